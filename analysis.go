@@ -0,0 +1,285 @@
+// Copyright © 2017 The vt-go authors. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vt
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"time"
+)
+
+// AnalysisStats contains the number of engines that returned each category
+// of verdict in an analysis.
+type AnalysisStats struct {
+	Harmless         int64 `json:"harmless"`
+	Malicious        int64 `json:"malicious"`
+	Suspicious       int64 `json:"suspicious"`
+	Undetected       int64 `json:"undetected"`
+	Timeout          int64 `json:"timeout"`
+	ConfirmedTimeout int64 `json:"confirmed-timeout"`
+	TypeUnsupported  int64 `json:"type-unsupported"`
+	Failure          int64 `json:"failure"`
+}
+
+// EngineResult contains the verdict of an antivirus engine in an analysis.
+type EngineResult struct {
+	// Category of the verdict, like "malicious", "undetected" or "harmless".
+	Category string `json:"category"`
+	// Result is the name of the detected threat, if any.
+	Result        string `json:"result"`
+	Method        string `json:"method"`
+	EngineName    string `json:"engine_name"`
+	EngineVersion string `json:"engine_version"`
+	// EngineUpdate is the date of the engine's signatures, with format
+	// YYYYMMDD. Use UpdateDate for obtaining it as a time.
+	EngineUpdate string `json:"engine_update"`
+}
+
+// UpdateDate returns the date of the engine's signatures.
+func (r *EngineResult) UpdateDate() (time.Time, error) {
+	return time.Parse("20060102", r.EngineUpdate)
+}
+
+func (obj *Object) getAnalysisResults(attr string) (map[string]EngineResult, error) {
+	results := make(map[string]EngineResult)
+	if err := obj.decodeAttribute(attr, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (obj *Object) getAnalysisStats(attr string) (*AnalysisStats, error) {
+	stats := &AnalysisStats{}
+	if err := obj.decodeAttribute(attr, stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// LastAnalysisStats returns the "last_analysis_stats" attribute of files,
+// URLs, domains and IP addresses.
+func (obj *Object) LastAnalysisStats() (*AnalysisStats, error) {
+	return obj.getAnalysisStats("last_analysis_stats")
+}
+
+// LastAnalysisResults returns the "last_analysis_results" attribute of
+// files, URLs, domains and IP addresses. Keys in the map are engine names.
+func (obj *Object) LastAnalysisResults() (map[string]EngineResult, error) {
+	return obj.getAnalysisResults("last_analysis_results")
+}
+
+// Analysis is an Object of type "analysis", it provides typed accessors for
+// the most relevant attributes of an analysis, while the generic methods
+// from Object are still available.
+type Analysis struct {
+	*Object
+}
+
+// NewAnalysis returns an Analysis from an Object of type "analysis".
+func NewAnalysis(obj *Object) *Analysis {
+	return &Analysis{Object: obj}
+}
+
+// Date returns the date in which the analysis was performed.
+func (a *Analysis) Date() (time.Time, error) {
+	return a.GetTime("date")
+}
+
+// Status returns the analysis' status, which can be "queued", "in-progress"
+// or "completed".
+func (a *Analysis) Status() (string, error) {
+	return a.GetString("status")
+}
+
+// Stats returns the number of engines that returned each verdict category.
+func (a *Analysis) Stats() (*AnalysisStats, error) {
+	return a.getAnalysisStats("stats")
+}
+
+// Results returns the verdict of each engine, keys in the map are engine
+// names.
+func (a *Analysis) Results() (map[string]EngineResult, error) {
+	return a.getAnalysisResults("results")
+}
+
+// WaitForCompletion polls the analysis until its status is "completed" and
+// returns the finished analysis, the receiver is not modified. The analysis is
+// retrieved from /analyses/{id} using the given client, the first polls are
+// separated by pollInterval, but the interval grows on each poll as described
+// in WaitFor, whose options can be used for tweaking the polling. Example:
+//
+//	analysis, err := scanner.Scan(f, "sample.exe", nil)
+//	if err != nil {
+//		...handle error
+//	}
+//	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+//	defer cancel()
+//	finished, err := vt.NewAnalysis(analysis).WaitForCompletion(ctx, client, 10*time.Second)
+func (a *Analysis) WaitForCompletion(ctx context.Context, cli *Client, pollInterval time.Duration, options ...WaitOption) (*Analysis, error) {
+	if status, _ := a.Status(); status == "completed" {
+		return a, nil
+	}
+	options = append([]WaitOption{WaitInterval(pollInterval)}, options...)
+	obj, err := cli.WaitFor(ctx, URL("analyses/%s", a.ID()),
+		func(o *Object) bool {
+			status, _ := o.GetString("status")
+			return status == "completed"
+		}, options...)
+	if err != nil {
+		return nil, err
+	}
+	return NewAnalysis(obj), nil
+}
+
+// AnalysisIterator is an iterator that returns Analysis objects.
+type AnalysisIterator struct {
+	*Iterator
+	// Analyses performed before this date end the iteration.
+	since time.Time
+	// True if an analysis performed before since was found.
+	sinceReached bool
+}
+
+// Next advances the iterator to the next analysis and returns true if there
+// are more analyses or false if the end of the collection has been reached.
+// Analyses are returned from newest to oldest, so when the iterator was
+// obtained with GetFileAnalyses the iteration ends at the first analysis
+// performed before the given date, without retrieving older ones.
+func (it *AnalysisIterator) Next() bool {
+	if it.sinceReached {
+		return false
+	}
+	for it.Iterator.Next() {
+		if it.since.IsZero() {
+			return true
+		}
+		date, err := it.Get().Date()
+		if err != nil {
+			continue
+		}
+		if date.Before(it.since) {
+			it.sinceReached = true
+			it.Close()
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+// Skip advances the iterator n analyses, as if Next was called n times, and
+// returns the number of analyses actually skipped. See Iterator.Skip.
+func (it *AnalysisIterator) Skip(n int) int {
+	if it.since.IsZero() {
+		return it.Iterator.Skip(n)
+	}
+	skipped := 0
+	for skipped < n && it.Next() {
+		skipped++
+	}
+	return skipped
+}
+
+// ForEach calls fn for every analysis returned by the iterator, using up to n
+// goroutines. See Iterator.ForEach.
+func (it *AnalysisIterator) ForEach(ctx context.Context, n int, fn func(*Analysis) error) error {
+	return it.forEach(ctx, n, it.Next, func(obj *Object) error {
+		return fn(NewAnalysis(obj))
+	})
+}
+
+// Reset moves the iterator to the position indicated by a cursor, see
+// Iterator.Reset.
+func (it *AnalysisIterator) Reset(cursor string) error {
+	it.sinceReached = false
+	return it.Iterator.Reset(cursor)
+}
+
+// Get returns the current analysis in the iterator.
+func (it *AnalysisIterator) Get() *Analysis {
+	if obj := it.Iterator.Get(); obj != nil {
+		return NewAnalysis(obj)
+	}
+	return nil
+}
+
+// AnalysisHistory returns an iterator over the past analyses of an object, for
+// objects that have the "analyses" relationship, like files and URLs. The URL
+// must point to the object itself, the "analyses" relationship is appended
+// to it. Example:
+//
+//	it, err := client.AnalysisHistory(vt.URL("files/%s", hash))
+//	if err != nil {
+//		...handle error
+//	}
+//	defer it.Close()
+//	for it.Next() {
+//		analysis := it.Get()
+//		date, _ := analysis.Date()
+//		stats, _ := analysis.Stats()
+//		fmt.Println(date, stats.Malicious)
+//	}
+func (cli *Client) AnalysisHistory(objectURL *url.URL, options ...IteratorOption) (*AnalysisIterator, error) {
+	u := *objectURL
+	u.Path = u.Path + "/analyses"
+	it, err := cli.Iterator(&u, options...)
+	if err != nil {
+		return nil, err
+	}
+	return &AnalysisIterator{Iterator: it}, nil
+}
+
+// GetFileAnalyses returns an iterator over the analyses of a file performed
+// on or after the given date, the file is identified by its hash (SHA-256,
+// SHA-1 or MD5). If since is the zero time all the analyses are returned.
+func (cli *Client) GetFileAnalyses(hash string, since time.Time, options ...IteratorOption) (*AnalysisIterator, error) {
+	it, err := cli.AnalysisHistory(URL("files/%s", hash), options...)
+	if err != nil {
+		return nil, err
+	}
+	it.since = since
+	return it, nil
+}
+
+// reanalyse asks VirusTotal for analysing again the object with the given URL
+// and returns the new analysis.
+func (cli *Client) reanalyse(objectURL *url.URL) (*Analysis, error) {
+	u := *objectURL
+	u.Path = u.Path + "/analyse"
+	resp, err := cli.Post(&u, nil)
+	if err != nil {
+		return nil, err
+	}
+	obj := &Object{}
+	if err := json.Unmarshal(resp.Data, obj); err != nil {
+		return nil, err
+	}
+	return NewAnalysis(obj), nil
+}
+
+// ReanalyseFile asks VirusTotal for analysing again a file already known, the
+// file is identified by its hash (SHA-256, SHA-1 or MD5). The returned
+// analysis is usually not completed yet, see Analysis.WaitForCompletion.
+func (cli *Client) ReanalyseFile(hash string) (*Analysis, error) {
+	return cli.reanalyse(URL("files/%s", hash))
+}
+
+// ReanalyseURL asks VirusTotal for analysing again a URL already known, the
+// URL is identified by its ID, which is either the SHA-256 of the URL or its
+// unpadded base64. Like in ReanalyseFile, the returned analysis is usually
+// not completed yet.
+func (cli *Client) ReanalyseURL(id string) (*Analysis, error) {
+	return cli.reanalyse(URL("urls/%s", id))
+}
@@ -0,0 +1,262 @@
+package vt
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnalysisHistory(t *testing.T) {
+	ts := NewTestServer(t).
+		SetExpectedMethod("GET").
+		SetResponse(map[string]interface{}{
+			"data": []map[string]interface{}{
+				{
+					"type": "analysis",
+					"id":   "analysis_2",
+					"attributes": map[string]interface{}{
+						"date":   1600000000,
+						"status": "completed",
+						"stats": map[string]interface{}{
+							"malicious":         10,
+							"undetected":        50,
+							"type-unsupported":  3,
+							"confirmed-timeout": 1,
+						},
+					},
+				},
+				{
+					"type": "analysis",
+					"id":   "analysis_1",
+					"attributes": map[string]interface{}{
+						"date":   1500000000,
+						"status": "completed",
+					},
+				},
+			}})
+
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+	it, err := c.AnalysisHistory(URL("files/abcd"))
+	assert.NoError(t, err)
+	defer it.Close()
+
+	assert.True(t, it.Next())
+	a := it.Get()
+	assert.Equal(t, "analysis_2", a.ID())
+	date, err := a.Date()
+	assert.NoError(t, err)
+	assert.Equal(t, time.Unix(1600000000, 0), date)
+	status, err := a.Status()
+	assert.NoError(t, err)
+	assert.Equal(t, "completed", status)
+	stats, err := a.Stats()
+	assert.NoError(t, err)
+	assert.Equal(t, AnalysisStats{
+		Malicious:        10,
+		Undetected:       50,
+		TypeUnsupported:  3,
+		ConfirmedTimeout: 1}, *stats)
+
+	assert.True(t, it.Next())
+	a = it.Get()
+	assert.Equal(t, "analysis_1", a.ID())
+	_, err = a.Stats()
+	assert.Error(t, err)
+
+	assert.False(t, it.Next())
+	assert.NoError(t, it.Error())
+}
+
+func TestGetFileAnalyses(t *testing.T) {
+	ts := NewTestServer(t).
+		SetExpectedMethod("GET").
+		SetResponse(map[string]interface{}{
+			"data": []map[string]interface{}{
+				{"type": "analysis", "id": "analysis_3", "attributes": map[string]interface{}{"date": 1700000000}},
+				{"type": "analysis", "id": "analysis_2", "attributes": map[string]interface{}{"date": 1600000000}},
+				{"type": "analysis", "id": "analysis_1", "attributes": map[string]interface{}{"date": 1500000000}},
+			}})
+
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+	it, err := c.GetFileAnalyses("abcd", time.Unix(1600000000, 0))
+	assert.NoError(t, err)
+	defer it.Close()
+
+	ids := []string{}
+	for it.Next() {
+		ids = append(ids, it.Get().ID())
+	}
+	assert.NoError(t, it.Error())
+	assert.Equal(t, []string{"analysis_3", "analysis_2"}, ids)
+}
+
+func TestGetFileAnalysesStopsAtSince(t *testing.T) {
+	var secondPage int32
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("cursor") != "" {
+			atomic.AddInt32(&secondPage, 1)
+			w.Write([]byte(`{"data": []}`))
+			return
+		}
+		fmt.Fprintf(w, `{"data": [
+			{"type": "analysis", "id": "analysis_4", "attributes": {"date": 1800000000}},
+			{"type": "analysis", "id": "analysis_3", "attributes": {"date": 1700000000}},
+			{"type": "analysis", "id": "analysis_2", "attributes": {"date": 1600000000}}],
+			"links": {"next": "%s/api/v3/files/abcd/analyses?cursor=x"}}`, ts.URL)
+	}))
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+	since := time.Unix(1650000000, 0)
+
+	it, err := c.GetFileAnalyses("abcd", since, IteratorPrefetch(1))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, it.Skip(5))
+	assert.False(t, it.Next())
+	it.Close()
+
+	it, err = c.GetFileAnalyses("abcd", since, IteratorPrefetch(1))
+	assert.NoError(t, err)
+	var count int32
+	err = it.ForEach(context.Background(), 2, func(a *Analysis) error {
+		date, err := a.Date()
+		assert.NoError(t, err)
+		assert.False(t, date.Before(since))
+		atomic.AddInt32(&count, 1)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&count))
+	it.Close()
+
+	// Older analyses are in the next page, which is never requested.
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&secondPage))
+}
+
+func TestLastAnalysis(t *testing.T) {
+	obj := &Object{}
+	err := obj.UnmarshalJSON([]byte(`{
+		"type": "file",
+		"id": "abcd",
+		"attributes": {
+			"last_analysis_stats": {"malicious": 2, "undetected": 1},
+			"last_analysis_results": {
+				"EngineA": {
+					"category": "malicious",
+					"result": "Trojan.Foo",
+					"method": "blacklist",
+					"engine_name": "EngineA",
+					"engine_version": "1.0",
+					"engine_update": "20200913"
+				},
+				"EngineB": {
+					"category": "undetected",
+					"result": null,
+					"engine_name": "EngineB"
+				}
+			}
+		}}`))
+	assert.NoError(t, err)
+
+	stats, err := obj.LastAnalysisStats()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), stats.Malicious)
+	assert.Equal(t, int64(1), stats.Undetected)
+
+	results, err := obj.LastAnalysisResults()
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	a := results["EngineA"]
+	assert.Equal(t, "malicious", a.Category)
+	assert.Equal(t, "Trojan.Foo", a.Result)
+	assert.Equal(t, "1.0", a.EngineVersion)
+	update, err := a.UpdateDate()
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2020, 9, 13, 0, 0, 0, 0, time.UTC), update)
+	assert.Equal(t, "", results["EngineB"].Result)
+
+	_, err = NewAnalysis(obj).Results()
+	assert.Error(t, err)
+}
+
+func TestAnalysisWaitForCompletion(t *testing.T) {
+	var polls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v3/analyses/a-1234", r.URL.Path)
+		status := "queued"
+		if atomic.AddInt32(&polls, 1) >= 2 {
+			status = "completed"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"data": {"type": "analysis", "id": "a-1234", "attributes": {
+			"status": "%s", "stats": {"malicious": 2}}}}`, status)
+	}))
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+
+	a := NewAnalysis(NewObjectWithID("analysis", "a-1234"))
+	finished, err := a.WaitForCompletion(context.Background(), c, time.Millisecond)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&polls))
+	status, err := finished.Status()
+	assert.NoError(t, err)
+	assert.Equal(t, "completed", status)
+	stats, err := finished.Stats()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), stats.Malicious)
+
+	// Analyses that are already completed are returned as is.
+	same, err := finished.WaitForCompletion(context.Background(), c, time.Millisecond)
+	assert.NoError(t, err)
+	assert.Equal(t, finished, same)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&polls))
+}
+
+func TestReanalyse(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v3/files/abcd/analyse":
+			w.Write([]byte(`{"data": {"type": "analysis", "id": "f-abcd"}}`))
+		case "/api/v3/urls/efgh/analyse":
+			w.Write([]byte(`{"data": {"type": "analysis", "id": "u-efgh"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": {"code": "NotFoundError"}}`))
+		}
+	}))
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+
+	a, err := c.ReanalyseFile("abcd")
+	assert.NoError(t, err)
+	assert.Equal(t, "f-abcd", a.ID())
+
+	a, err = c.ReanalyseURL("efgh")
+	assert.NoError(t, err)
+	assert.Equal(t, "u-efgh", a.ID())
+
+	_, err = c.ReanalyseFile("unknown")
+	assert.Error(t, err)
+}
@@ -0,0 +1,100 @@
+// Copyright © 2017 The vt-go authors. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vt
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+)
+
+// BehaviourArtifact identifies a file generated by a sandbox while analysing
+// a file, which can be downloaded from the file behaviour feed.
+type BehaviourArtifact string
+
+const (
+	// ArtifactEVTX is the Windows event log recorded during the analysis.
+	ArtifactEVTX BehaviourArtifact = "evtx"
+	// ArtifactPCAP is the network traffic captured during the analysis.
+	ArtifactPCAP BehaviourArtifact = "pcap"
+	// ArtifactMemdump is the memory dump of the analysed process.
+	ArtifactMemdump BehaviourArtifact = "memdump"
+	// ArtifactHTMLReport is the report produced by the sandbox in HTML
+	// format.
+	ArtifactHTMLReport BehaviourArtifact = "html_report"
+)
+
+// FileBehaviour is an Object of type "file_behaviour", as received from the
+// file behaviour feed. Besides the behaviour report itself, each object has
+// context attributes with links for downloading the artifacts generated by
+// the sandbox, those links include a token that authorizes the download.
+type FileBehaviour struct {
+	*Object
+}
+
+// NewFileBehaviour returns a FileBehaviour from an Object of type
+// "file_behaviour".
+func NewFileBehaviour(obj *Object) *FileBehaviour {
+	return &FileBehaviour{Object: obj}
+}
+
+// SandboxName returns the name of the sandbox that produced the report.
+func (b *FileBehaviour) SandboxName() (string, error) {
+	return b.GetString("sandbox_name")
+}
+
+// ArtifactURL returns the link for downloading an artifact, an error is
+// returned if the artifact is not available for this report.
+func (b *FileBehaviour) ArtifactURL(a BehaviourArtifact) (*url.URL, error) {
+	link, err := b.GetContextString(string(a))
+	if err != nil {
+		return nil, err
+	}
+	if link == "" {
+		return nil, fmt.Errorf("artifact \"%s\" not available", a)
+	}
+	return url.Parse(link)
+}
+
+// DownloadArtifact writes an artifact into the provided io.Writer, returning
+// the number of bytes written. If the token in the artifact's link has expired
+// the returned error is ErrDownloadExpired.
+func (b *FileBehaviour) DownloadArtifact(cli *Client, a BehaviourArtifact, w io.Writer) (int64, error) {
+	u, err := b.ArtifactURL(a)
+	if err != nil {
+		return 0, err
+	}
+	return cli.downloadLink(u, w)
+}
+
+// DownloadEVTX writes the Windows event log into the provided io.Writer.
+func (b *FileBehaviour) DownloadEVTX(cli *Client, w io.Writer) (int64, error) {
+	return b.DownloadArtifact(cli, ArtifactEVTX, w)
+}
+
+// DownloadPCAP writes the captured network traffic into the provided
+// io.Writer.
+func (b *FileBehaviour) DownloadPCAP(cli *Client, w io.Writer) (int64, error) {
+	return b.DownloadArtifact(cli, ArtifactPCAP, w)
+}
+
+// DownloadMemdump writes the memory dump into the provided io.Writer.
+func (b *FileBehaviour) DownloadMemdump(cli *Client, w io.Writer) (int64, error) {
+	return b.DownloadArtifact(cli, ArtifactMemdump, w)
+}
+
+// DownloadHTMLReport writes the HTML report into the provided io.Writer.
+func (b *FileBehaviour) DownloadHTMLReport(cli *Client, w io.Writer) (int64, error) {
+	return b.DownloadArtifact(cli, ArtifactHTMLReport, w)
+}
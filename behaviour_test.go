@@ -0,0 +1,55 @@
+package vt
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileBehaviourArtifacts(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v3/feeds/file-behaviours/token1/pcap", r.URL.Path)
+		w.Write([]byte("pcap data"))
+	}))
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+
+	obj, err := parseFeedLine(FileBehaviourFeed, []byte(`{
+		"type": "file_behaviour",
+		"id": "1234_Sandbox",
+		"attributes": {"sandbox_name": "Sandbox"},
+		"context_attributes": {
+			"pcap": "`+ts.URL+`/api/v3/feeds/file-behaviours/token1/pcap",
+			"evtx": ""
+		}}`))
+	assert.NoError(t, err)
+
+	b := NewFileBehaviour(obj)
+	name, err := b.SandboxName()
+	assert.NoError(t, err)
+	assert.Equal(t, "Sandbox", name)
+
+	var buf bytes.Buffer
+	n, err := b.DownloadPCAP(c, &buf)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(9), n)
+	assert.Equal(t, "pcap data", buf.String())
+
+	_, err = b.DownloadEVTX(c, &buf)
+	assert.Error(t, err)
+	_, err = b.DownloadMemdump(c, &buf)
+	assert.Error(t, err)
+}
+
+func TestFileBehaviourMissingArtifact(t *testing.T) {
+	obj := &Object{}
+	assert.NoError(t, json.Unmarshal([]byte(`{"type": "file_behaviour", "id": "x"}`), obj))
+	_, err := NewFileBehaviour(obj).ArtifactURL(ArtifactHTMLReport)
+	assert.Error(t, err)
+}
@@ -0,0 +1,227 @@
+// Copyright © 2017 The vt-go authors. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vt
+
+import (
+	"context"
+	"encoding/hex"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// LookupResult contains the result of looking up an ID with BulkLookup.
+type LookupResult struct {
+	// ID is the hash, domain or IP address as it was passed to Lookup.
+	ID string
+	// Object is the object retrieved from VirusTotal, it's nil if the
+	// lookup failed.
+	Object *Object
+	// Number of requests made for retrieving the object.
+	Attempts int
+	// Err is the error occurred in the last attempt, if the lookup failed.
+	Err error
+}
+
+// BulkLookup retrieves the objects corresponding to a stream of file hashes,
+// domains and IP addresses, sending multiple requests in parallel while
+// limiting the rate at which they are sent. Requests failing with a transient
+// error are retried.
+type BulkLookup struct {
+	cli        *Client
+	workers    int
+	interval   time.Duration
+	maxRetries int
+	retryDelay time.Duration
+	lookupURL  func(id string) *url.URL
+}
+
+// BulkLookupOption represents an option passed to NewBulkLookup.
+type BulkLookupOption func(*BulkLookup)
+
+// BulkLookupWorkers specifies the maximum number of requests that are sent in
+// parallel. The default is 4.
+func BulkLookupWorkers(n int) BulkLookupOption {
+	return func(b *BulkLookup) {
+		b.workers = n
+	}
+}
+
+// BulkLookupRequestsPerMinute limits the number of requests sent per minute,
+// including retries, which is useful for staying within the quota of an API
+// key. By default the number of requests is not limited.
+func BulkLookupRequestsPerMinute(n int) BulkLookupOption {
+	return func(b *BulkLookup) {
+		if n > 0 {
+			b.interval = time.Minute / time.Duration(n)
+		}
+	}
+}
+
+// BulkLookupRetries specifies the number of times that a lookup failing with
+// a transient error, like a network error or an error for which
+// IsRetryableError returns true, is retried, and the delay between retries.
+// The delay is multiplied by the number of the attempt, so retries get more
+// and more spaced. The default is 3 retries with a delay of 5 seconds.
+func BulkLookupRetries(n int, delay time.Duration) BulkLookupOption {
+	return func(b *BulkLookup) {
+		b.maxRetries = n
+		b.retryDelay = delay
+	}
+}
+
+// BulkLookupURL specifies a function that returns the URL of the object
+// corresponding to an ID, for looking up something else than files, domains
+// and IP addresses.
+func BulkLookupURL(f func(id string) *url.URL) BulkLookupOption {
+	return func(b *BulkLookup) {
+		b.lookupURL = f
+	}
+}
+
+// NewBulkLookup returns a new BulkLookup.
+func (cli *Client) NewBulkLookup(options ...BulkLookupOption) *BulkLookup {
+	b := &BulkLookup{
+		cli:        cli,
+		workers:    4,
+		maxRetries: 3,
+		retryDelay: 5 * time.Second,
+		lookupURL:  lookupURL,
+	}
+	for _, opt := range options {
+		opt(b)
+	}
+	if b.workers < 1 {
+		b.workers = 1
+	}
+	return b
+}
+
+// lookupURL returns the URL of the object corresponding to an ID, which can be
+// a MD5, SHA1 or SHA256 hash, an IP address or a domain name.
+func lookupURL(id string) *url.URL {
+	if net.ParseIP(id) != nil {
+		return URL("ip_addresses/%s", id)
+	}
+	if l := len(id); l == 32 || l == 40 || l == 64 {
+		if _, err := hex.DecodeString(id); err == nil {
+			return URL("files/%s", id)
+		}
+	}
+	return URL("domains/%s", id)
+}
+
+// Lookup retrieves the objects for the IDs received from the ids channel, and
+// sends the results through the returned channel as they are ready, which
+// means that results are not necessarily in the same order than IDs. The
+// returned channel is closed after the results for all the IDs have been
+// sent, once the ids channel is closed, or when the context is cancelled.
+//
+// Example:
+//
+//	ids := make(chan string)
+//	go func() {
+//		for _, hash := range hashes {
+//			ids <- hash
+//		}
+//		close(ids)
+//	}()
+//	for result := range client.NewBulkLookup().Lookup(ctx, ids) {
+//		if result.Err != nil {
+//			...
+//		}
+//	}
+func (b *BulkLookup) Lookup(ctx context.Context, ids <-chan string) <-chan LookupResult {
+	results := make(chan LookupResult)
+	var ticker *time.Ticker
+	if b.interval > 0 {
+		ticker = time.NewTicker(b.interval)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < b.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				var id string
+				var ok bool
+				select {
+				case id, ok = <-ids:
+				case <-ctx.Done():
+					return
+				}
+				if !ok {
+					return
+				}
+				select {
+				case results <- b.lookup(ctx, id, ticker):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		if ticker != nil {
+			ticker.Stop()
+		}
+		close(results)
+	}()
+	return results
+}
+
+// lookup retrieves the object for a single ID, retrying the request if
+// needed. If ticker is not nil, every request waits for a tick before being
+// sent.
+func (b *BulkLookup) lookup(ctx context.Context, id string, ticker *time.Ticker) LookupResult {
+	result := LookupResult{ID: id}
+	result.Attempts, result.Err = retryTransient(ctx, ticker, b.maxRetries, b.retryDelay, func() (err error) {
+		result.Object, err = b.cli.GetObject(b.lookupURL(id))
+		return err
+	})
+	return result
+}
+
+// retryTransient calls fn until it succeeds or fails with an error that is not
+// transient, retrying it up to n times. Retries are spaced as described in
+// BulkLookupRetries. If ticker is not nil, every call waits for a tick. It
+// returns the number of calls to fn and the error returned by the last one,
+// or the context's error if the context is done while waiting.
+func retryTransient(ctx context.Context, ticker *time.Ticker, n int, delay time.Duration, fn func() error) (int, error) {
+	var err error
+	calls := 0
+	for attempt := 0; attempt <= n; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(time.Duration(attempt) * delay):
+			case <-ctx.Done():
+				return calls, ctx.Err()
+			}
+		}
+		if ticker != nil {
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return calls, ctx.Err()
+			}
+		}
+		calls++
+		if err = fn(); err == nil || !isTransientError(err) {
+			break
+		}
+	}
+	return calls, err
+}
@@ -0,0 +1,98 @@
+package vt
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLookupURL(t *testing.T) {
+	assert.Equal(t, URL("files/44d88612fea8a8f36de82e1278abb02f"), lookupURL("44d88612fea8a8f36de82e1278abb02f"))
+	assert.Equal(t, URL("ip_addresses/8.8.8.8"), lookupURL("8.8.8.8"))
+	assert.Equal(t, URL("ip_addresses/2001:db8::1"), lookupURL("2001:db8::1"))
+	assert.Equal(t, URL("domains/example.com"), lookupURL("example.com"))
+}
+
+func TestBulkLookup(t *testing.T) {
+	var mu sync.Mutex
+	attempts := map[string]int{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		id := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		mu.Lock()
+		attempts[id]++
+		n := attempts[id]
+		mu.Unlock()
+		switch {
+		case id == "missing.com":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": {"code": "NotFoundError", "message": "not found"}}`))
+		case id == "flaky.com" && n == 1:
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"error": {"code": "QuotaExceededError", "message": "quota"}}`))
+		case id == "broken.com" && n == 1:
+			// A malformed response produces a network error.
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Write([]byte("garbage\r\n\r\n"))
+			conn.Close()
+		default:
+			w.Write([]byte(`{"data": {"type": "domain", "id": "` + id + `", "attributes": {}}}`))
+		}
+	}))
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+	b := c.NewBulkLookup(BulkLookupWorkers(3), BulkLookupRetries(2, time.Millisecond))
+
+	ids := make(chan string)
+	go func() {
+		for _, id := range []string{"a.com", "flaky.com", "broken.com", "missing.com", "b.com"} {
+			ids <- id
+		}
+		close(ids)
+	}()
+
+	results := map[string]LookupResult{}
+	for r := range b.Lookup(context.Background(), ids) {
+		results[r.ID] = r
+	}
+
+	assert.Len(t, results, 5)
+	assert.NoError(t, results["a.com"].Err)
+	assert.Equal(t, "a.com", results["a.com"].Object.ID())
+	assert.NoError(t, results["flaky.com"].Err)
+	assert.Equal(t, 2, results["flaky.com"].Attempts)
+	assert.NoError(t, results["broken.com"].Err)
+	assert.Equal(t, 2, results["broken.com"].Attempts)
+	assert.Error(t, results["missing.com"].Err)
+	assert.Nil(t, results["missing.com"].Object)
+	assert.Equal(t, 1, results["missing.com"].Attempts)
+}
+
+func TestBulkLookupCancel(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": {"type": "domain", "id": "x", "attributes": {}}}`))
+	}))
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+	b := c.NewBulkLookup(BulkLookupRequestsPerMinute(1))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ids := make(chan string, 2)
+	ids <- "a.com"
+	ids <- "b.com"
+	results := b.Lookup(ctx, ids)
+	cancel()
+	for range results {
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright © 2017 The vt-go authors. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vt
+
+import (
+	"sort"
+	"strings"
+)
+
+// Categories maps the names of categorization vendors to the category each
+// vendor assigned to a URL or domain, as returned in the "categories"
+// attribute.
+type Categories map[string]string
+
+// normalizeCategory returns the individual categories in a vendor's verdict.
+// Some vendors return multiple comma-separated categories, like "phishing,
+// malware". Categories are lowercased and trimmed.
+func normalizeCategory(category string) []string {
+	parts := strings.Split(category, ",")
+	for i, part := range parts {
+		parts[i] = strings.ToLower(strings.TrimSpace(part))
+	}
+	return parts
+}
+
+// HasCategory returns true if some vendor assigned the given category. The
+// comparison is case-insensitive.
+func (c Categories) HasCategory(category string) bool {
+	return len(c.Vendors(category)) > 0
+}
+
+// Vendors returns the names of the vendors that assigned the given category,
+// sorted alphabetically. The comparison is case-insensitive.
+func (c Categories) Vendors(category string) []string {
+	category = strings.ToLower(strings.TrimSpace(category))
+	vendors := []string{}
+	for vendor, value := range c {
+		for _, cat := range normalizeCategory(value) {
+			if cat == category {
+				vendors = append(vendors, vendor)
+				break
+			}
+		}
+	}
+	sort.Strings(vendors)
+	return vendors
+}
+
+// Unique returns the distinct categories assigned by all vendors, normalized
+// to lowercase and sorted alphabetically.
+func (c Categories) Unique() []string {
+	seen := make(map[string]bool)
+	result := []string{}
+	for _, value := range c {
+		for _, cat := range normalizeCategory(value) {
+			if cat != "" && !seen[cat] {
+				seen[cat] = true
+				result = append(result, cat)
+			}
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// GetCategories returns the "categories" attribute of URLs and domains.
+func (obj *Object) GetCategories() (Categories, error) {
+	m, err := obj.GetStringMap("categories")
+	if err != nil {
+		return nil, err
+	}
+	return Categories(m), nil
+}
@@ -0,0 +1,33 @@
+package vt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCategories(t *testing.T) {
+	obj := &Object{}
+	err := obj.UnmarshalJSON([]byte(`{
+		"type": "domain",
+		"id": "example.com",
+		"attributes": {
+			"categories": {
+				"Forcepoint ThreatSeeker": "Phishing and Other Frauds",
+				"Sophos": "phishing",
+				"BitDefender": "Phishing, Malware",
+				"alphaMountain.ai": "Malicious"
+			}
+		}}`))
+	assert.NoError(t, err)
+
+	c, err := obj.GetCategories()
+	assert.NoError(t, err)
+	assert.True(t, c.HasCategory("PHISHING"))
+	assert.True(t, c.HasCategory("phishing and other frauds"))
+	assert.False(t, c.HasCategory("spam"))
+	assert.Equal(t, []string{"BitDefender", "Sophos"}, c.Vendors("phishing"))
+	assert.Equal(t,
+		[]string{"malicious", "malware", "phishing", "phishing and other frauds"},
+		c.Unique())
+}
@@ -14,18 +14,22 @@
 package vt
 
 import (
+	"bufio"
 	"bytes"
-	"compress/gzip"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"path"
 	"strings"
+	"sync"
+	"time"
 )
 
 type requestOptions struct {
 	headers map[string]string
+	query   map[string]string
 }
 
 // RequestOption represents an option passed to some functions in this package.
@@ -65,6 +69,40 @@ type Client struct {
 	// methods (Get, Post, ...) via RequestOption have preference and will
 	// override these global ones.
 	headers map[string]string
+	// Endpoints that are sent to some other base URL instead of VirusTotal.
+	overrides []endpointOverride
+	// If true, the client doesn't ask the server for compressed responses.
+	noCompression bool
+	// Semaphore that limits the number of requests in flight, nil if there's
+	// no limit.
+	sem chan struct{}
+	// If true, attributes in objects are validated against the metadata
+	// before sending them to the server.
+	validateAttributes bool
+	// If true, query parameters are validated before sending requests, see
+	// WithStrictValidation.
+	strictValidation bool
+	// Metadata used for validating attributes, retrieved the first time it's
+	// needed.
+	metadata   *Metadata
+	metadataMu sync.Mutex
+	// URL used for uploading large files, which is reused by subsequent
+	// uploads until it expires, see WithUploadURLValidity.
+	uploadURL         *url.URL
+	uploadURLExpires  time.Time
+	uploadURLValidity time.Duration
+	uploadURLMu       sync.Mutex
+	// Maximum size of payloads posted to the API endpoints, and maximum size
+	// of files that can be uploaded, see WithMaxPayloadSize and
+	// WithMaxFileSize.
+	maxPayloadSize int64
+	maxFileSize    int64
+}
+
+// endpointOverride routes the endpoints matching pattern to baseURL.
+type endpointOverride struct {
+	pattern string
+	baseURL *url.URL
 }
 
 // WithHeader specifies a header to be included in the request, it will override
@@ -78,6 +116,42 @@ func WithHeader(header, value string) RequestOption {
 	}
 }
 
+// WithQueryParameter specifies a query parameter that is added to the
+// request's URL, replacing any parameter with the same name in it.
+func WithQueryParameter(name, value string) RequestOption {
+	return func(opts *requestOptions) {
+		if opts.query == nil {
+			opts.query = make(map[string]string)
+		}
+		opts.query[name] = value
+	}
+}
+
+// WithRelationshipCounters asks the server for including the number of
+// objects in each relationship of the requested object, which can be obtained
+// afterwards with Object.RelationshipCount. Example:
+//
+//	obj, err := client.GetObject(vt.URL("files/%s", hash), vt.WithRelationshipCounters())
+//	...
+//	n, err := obj.RelationshipCount("contacted_ips")
+func WithRelationshipCounters() RequestOption {
+	return WithQueryParameter("relationship_counters", "true")
+}
+
+// url returns the URL with the query parameters specified in the options.
+func (o *requestOptions) url(u *url.URL) *url.URL {
+	if len(o.query) == 0 {
+		return u
+	}
+	withQuery := *u
+	q := withQuery.Query()
+	for name, value := range o.query {
+		q.Set(name, value)
+	}
+	withQuery.RawQuery = q.Encode()
+	return &withQuery
+}
+
 func opts(opts ...RequestOption) *requestOptions {
 	o := &requestOptions{}
 	for _, opt := range opts {
@@ -107,10 +181,140 @@ func WithGlobalHeader(header, value string) ClientOption {
 	}
 }
 
+// WithCompression specifies whether the client asks the server for compressed
+// responses, which is the default. Some private deployments don't behave like
+// VirusTotal's servers regarding compression, for those cases compression can
+// be disabled, the client then asks for uncompressed responses and doesn't
+// include the "gzip" marker in the User-Agent header.
+func WithCompression(b bool) ClientOption {
+	return func(c *Client) {
+		c.noCompression = !b
+	}
+}
+
+// WithMaxConcurrentRequests limits the number of requests that the client
+// can have in flight at the same time, additional requests wait until some of
+// the ongoing ones finishes. A request is considered finished once its
+// response has been fully read, so this option is useful for capping the
+// parallelism of applications with many goroutines sharing the same client.
+// Values lower than 1 mean no limit, which is the default.
+func WithMaxConcurrentRequests(n int) ClientOption {
+	return func(c *Client) {
+		if n > 0 {
+			c.sem = make(chan struct{}, n)
+		} else {
+			c.sem = nil
+		}
+	}
+}
+
+// WithUploadURLValidity specifies for how long the URL obtained for uploading
+// a large file is reused for uploading other large files, which saves a
+// request to /files/upload_url for each file when many large files are
+// uploaded. If an upload with a reused URL fails, a new URL is obtained and
+// the upload is tried again. The default is 30 minutes, values lower or equal
+// to 0 disable the reuse.
+func WithUploadURLValidity(d time.Duration) ClientOption {
+	return func(c *Client) {
+		c.uploadURLValidity = d
+	}
+}
+
+// WithMaxPayloadSize specifies the maximum size of files that are uploaded
+// directly to the API endpoints, larger files are uploaded to a special URL
+// obtained from the API. The default is 30MB.
+func WithMaxPayloadSize(n int64) ClientOption {
+	return func(c *Client) {
+		c.maxPayloadSize = n
+	}
+}
+
+// WithMaxFileSize specifies the maximum size of files that can be uploaded
+// for scanning, larger files are rejected with ErrFileTooLarge without
+// uploading them. The default is 650MB, which is VirusTotal's limit for most
+// accounts, use this option if your account has a different limit.
+func WithMaxFileSize(n int64) ClientOption {
+	return func(c *Client) {
+		c.maxFileSize = n
+	}
+}
+
+// releasingBody is the body of a HTTP response that releases a slot in the
+// client's semaphore when closed.
+type releasingBody struct {
+	io.ReadCloser
+	once sync.Once
+	sem  chan struct{}
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() { <-b.sem })
+	return err
+}
+
+// WithEndpointOverride routes the requests for the endpoints matching pattern
+// to a different base URL, like an internal caching proxy, while the rest of
+// requests are still sent to VirusTotal. The pattern is matched against the
+// endpoint path relative to the API root (i.e: without the "/api/v3/" prefix)
+// using the syntax accepted by path.Match. For example, for routing the file
+// reports to a proxy while leaving other endpoints untouched:
+//
+//	client := vt.NewClient(apiKey,
+//		vt.WithEndpointOverride("files/*", "https://vtcache.example.com/api/v3/"))
+//
+// The endpoint path is appended to the base URL, and the query string is
+// preserved. When multiple patterns match the same endpoint the first one
+// takes precedence. This function panics if the pattern or the base URL are
+// not valid.
+func WithEndpointOverride(pattern, baseURL string) ClientOption {
+	if _, err := path.Match(pattern, ""); err != nil {
+		panic(fmt.Sprintf("invalid endpoint pattern \"%s\": %s", pattern, err))
+	}
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		panic(fmt.Sprintf("invalid base URL \"%s\": %s", baseURL, err))
+	}
+	if !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+	}
+	return func(c *Client) {
+		c.overrides = append(c.overrides, endpointOverride{pattern: pattern, baseURL: u})
+	}
+}
+
+// overrideURL returns the URL where a request for u must be sent, which is u
+// itself unless the endpoint matches some of the patterns specified with
+// WithEndpointOverride.
+func (cli *Client) overrideURL(u *url.URL) *url.URL {
+	if len(cli.overrides) == 0 || u.Host != baseURL.Host {
+		return u
+	}
+	endpoint := strings.TrimPrefix(u.Path, "/")
+	if !strings.HasPrefix(endpoint, baseURL.Path) {
+		return u
+	}
+	endpoint = strings.TrimPrefix(endpoint, baseURL.Path)
+	for _, o := range cli.overrides {
+		if matched, _ := path.Match(o.pattern, endpoint); matched {
+			return o.baseURL.ResolveReference(&url.URL{
+				Path:     endpoint,
+				RawQuery: u.RawQuery})
+		}
+	}
+	return u
+}
+
 // NewClient creates a new client for interacting with the VirusTotal API using
 // the provided API key.
 func NewClient(APIKey string, opts ...ClientOption) *Client {
-	c := &Client{APIKey: APIKey, httpClient: &http.Client{}}
+	c := &Client{
+		APIKey:            APIKey,
+		httpClient:        &http.Client{},
+		uploadURLValidity: 30 * time.Minute,
+		maxPayloadSize:    defaultMaxPayloadSize,
+		maxFileSize:       defaultMaxFileSize,
+	}
 	for _, o := range opts {
 		o(c)
 	}
@@ -119,7 +323,10 @@ func NewClient(APIKey string, opts ...ClientOption) *Client {
 
 // sendRequest sends a HTTP request to the VirusTotal REST API.
 func (cli *Client) sendRequest(method string, url *url.URL, body io.Reader, headers map[string]string) (*http.Response, error) {
-	req, err := http.NewRequest(method, url.String(), body)
+	if err := cli.validateURL(url); err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(method, cli.overrideURL(url).String(), body)
 	if err != nil {
 		return nil, err
 	}
@@ -131,8 +338,15 @@ func (cli *Client) sendRequest(method string, url *url.URL, body io.Reader, head
 	// based on Accept-Encoding and User-Agent. Non-standard UAs are not served
 	// with gzipped content unless it contains the string "gzip" somewhere.
 	// See: https://cloud.google.com/appengine/kb/#compression
-	req.Header.Set("User-Agent", fmt.Sprintf("%s; vtgo %s; gzip", agent, version))
-	req.Header.Set("Accept-Encoding", "gzip")
+	if cli.noCompression {
+		req.Header.Set("User-Agent", fmt.Sprintf("%s; vtgo %s", agent, version))
+		// Setting Accept-Encoding explicitly prevents the HTTP transport from
+		// asking for gzipped content by itself.
+		req.Header.Set("Accept-Encoding", "identity")
+	} else {
+		req.Header.Set("User-Agent", fmt.Sprintf("%s; vtgo %s; gzip", agent, version))
+		req.Header.Set("Accept-Encoding", acceptEncoding())
+	}
 	req.Header.Set("X-Apikey", cli.APIKey)
 
 	// Set global defined headers
@@ -145,7 +359,18 @@ func (cli *Client) sendRequest(method string, url *url.URL, body io.Reader, head
 		req.Header.Set(k, v)
 	}
 
-	return (cli.httpClient).Do(req)
+	if cli.sem == nil {
+		return (cli.httpClient).Do(req)
+	}
+
+	cli.sem <- struct{}{}
+	resp, err := (cli.httpClient).Do(req)
+	if err != nil {
+		<-cli.sem
+		return nil, err
+	}
+	resp.Body = &releasingBody{ReadCloser: resp.Body, sem: cli.sem}
+	return resp, nil
 }
 
 // parseResponse parses a HTTP response received from the VirusTotal REST API.
@@ -157,8 +382,21 @@ func (cli *Client) parseResponse(resp *http.Response) (*Response, error) {
 
 	apiresp := &Response{}
 
-	if resp.ContentLength == 0 {
-		return apiresp, nil
+	if resp.ContentLength == 0 || resp.StatusCode == http.StatusNoContent {
+		return cli.emptyResponse(resp, apiresp)
+	}
+
+	// The content length is unknown when the body is sent in chunks, in that
+	// case peek into the body for finding out if it's actually empty.
+	if resp.ContentLength < 0 {
+		body := bufio.NewReader(resp.Body)
+		if _, err := body.Peek(1); err == io.EOF {
+			return cli.emptyResponse(resp, apiresp)
+		}
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{body, resp.Body}
 	}
 
 	// If the response has some content its format should be JSON
@@ -167,18 +405,11 @@ func (cli *Client) parseResponse(resp *http.Response) (*Response, error) {
 			resp.Request.Method, resp.Request.URL.String())
 	}
 
-	var reader io.ReadCloser
-	if resp.Header.Get("Content-Encoding") == "gzip" {
-		// Prepare gzip reader for uncompressing gzipped JSON response
-		ungzipper, err := gzip.NewReader(resp.Body)
-		if err != nil {
-			return nil, err
-		}
-		defer ungzipper.Close()
-		reader = ungzipper
-	} else {
-		reader = resp.Body
+	reader, err := responseBody(resp)
+	if err != nil {
+		return nil, err
 	}
+	defer reader.Close()
 
 	if err := json.NewDecoder(reader).Decode(apiresp); err != nil {
 		return nil, err
@@ -192,12 +423,23 @@ func (cli *Client) parseResponse(resp *http.Response) (*Response, error) {
 	return apiresp, nil
 }
 
+// emptyResponse handles a response without body, which is a NoContent
+// response if the status code indicates success, and an error otherwise.
+func (cli *Client) emptyResponse(resp *http.Response, apiresp *Response) (*Response, error) {
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("Empty response with status \"%s\" from %s %s",
+			resp.Status, resp.Request.Method, resp.Request.URL.String())
+	}
+	apiresp.NoContent = true
+	return apiresp, nil
+}
+
 // Get sends a GET request to the specified API endpoint. This is a low level
 // primitive that returns a Response struct, where the response's data is in
 // raw form. See GetObject and GetData for higher level primitives.
 func (cli *Client) Get(url *url.URL, options ...RequestOption) (*Response, error) {
 	o := opts(options...)
-	httpResp, err := cli.sendRequest("GET", url, nil, o.headers)
+	httpResp, err := cli.sendRequest("GET", o.url(url), nil, o.headers)
 	if err != nil {
 		return nil, err
 	}
@@ -220,7 +462,7 @@ func (cli *Client) Post(url *url.URL, req *Request, options ...RequestOption) (*
 		[]RequestOption{WithHeader("Content-Type", "application/json")},
 		options...)
 	o := opts(defaultContentTypeOptions...)
-	httpResp, err := cli.sendRequest("POST", url, bytes.NewReader(b), o.headers)
+	httpResp, err := cli.sendRequest("POST", o.url(url), bytes.NewReader(b), o.headers)
 	if err != nil {
 		return nil, err
 	}
@@ -243,7 +485,7 @@ func (cli *Client) Patch(url *url.URL, req *Request, options ...RequestOption) (
 		[]RequestOption{WithHeader("Content-Type", "application/json")},
 		options...)
 	o := opts(defaultContentTypeOptions...)
-	httpResp, err := cli.sendRequest("PATCH", url, bytes.NewReader(b), o.headers)
+	httpResp, err := cli.sendRequest("PATCH", o.url(url), bytes.NewReader(b), o.headers)
 	if err != nil {
 		return nil, err
 	}
@@ -251,10 +493,12 @@ func (cli *Client) Patch(url *url.URL, req *Request, options ...RequestOption) (
 	return cli.parseResponse(httpResp)
 }
 
-// Delete sends a DELETE request to the specified API endpoint.
+// Delete sends a DELETE request to the specified API endpoint. Successful
+// responses without content, like those with status 204 (No Content), are
+// not an error, the returned Response has NoContent set to true in that case.
 func (cli *Client) Delete(url *url.URL, options ...RequestOption) (*Response, error) {
 	o := opts(options...)
-	httpResp, err := cli.sendRequest("DELETE", url, nil, o.headers)
+	httpResp, err := cli.sendRequest("DELETE", o.url(url), nil, o.headers)
 	if err != nil {
 		return nil, err
 	}
@@ -286,7 +530,9 @@ func (cli *Client) PostData(url *url.URL, data interface{}, options ...RequestOp
 }
 
 // DeleteData sends a DELETE request to the specified API endpoint. The data argument
-// is JSON-encoded and wrapped as {'data': <JSON-encoded data>}.
+// is JSON-encoded and wrapped as {'data': <JSON-encoded data>}. Like Delete, it
+// sets NoContent in the returned Response if the server responded successfully
+// without any content.
 func (cli *Client) DeleteData(url *url.URL, data interface{}, options ...RequestOption) (*Response, error) {
 	req := &Request{}
 	req.Data = data
@@ -301,7 +547,7 @@ func (cli *Client) DeleteData(url *url.URL, data interface{}, options ...Request
 		[]RequestOption{WithHeader("Content-Type", "application/json")},
 		options...)
 	o := opts(defaultContentTypeOptions...)
-	httpResp, err := cli.sendRequest("DELETE", url, bytes.NewReader(b), o.headers)
+	httpResp, err := cli.sendRequest("DELETE", o.url(url), bytes.NewReader(b), o.headers)
 	if err != nil {
 		return nil, err
 	}
@@ -327,6 +573,9 @@ func (cli *Client) DeleteData(url *url.URL, data interface{}, options ...Request
 //
 //	client.PostObject(vt.URL("intelligence/hunting_rulesets"), obj)
 func (cli *Client) PostObject(url *url.URL, obj *Object, options ...RequestOption) error {
+	if err := cli.validate(obj); err != nil {
+		return err
+	}
 	req := &Request{}
 	req.Data = modifiedObject(*obj)
 	resp, err := cli.Post(url, req, options...)
@@ -348,8 +597,68 @@ func (cli *Client) GetObject(url *url.URL, options ...RequestOption) (*Object, e
 	return obj, nil
 }
 
+// Maximum number of IDs sent in a single request by GetObjects.
+const getObjectsBatchSize = 100
+
+// GetObjects retrieves multiple objects from a collection that accepts a list
+// of IDs in the "ids" query parameter, like /files?ids=h1,h2,h3. The list of
+// IDs is split in batches that are sent in separate requests, and the objects
+// are returned in the same order than the IDs. If some of the objects can't be
+// retrieved the corresponding entries in the returned slice are nil, and the
+// returned error is a *MultiError that contains the error for each of them.
+//
+// Example:
+//
+//	files, err := client.GetObjects(vt.URL("files"), hash1, hash2, hash3)
+func (cli *Client) GetObjects(collection *url.URL, ids ...string) ([]*Object, error) {
+	objs := make([]*Object, len(ids))
+	errs := &MultiError{}
+	for start := 0; start < len(ids); start += getObjectsBatchSize {
+		end := start + getObjectsBatchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		u := *collection
+		q := u.Query()
+		q.Set("ids", strings.Join(ids[start:end], ","))
+		u.RawQuery = q.Encode()
+		var items []json.RawMessage
+		_, err := cli.GetData(&u, &items)
+		if err == nil && len(items) != end-start {
+			err = fmt.Errorf("expecting %d objects, got %d", end-start, len(items))
+		}
+		if err != nil {
+			for i := start; i < end; i++ {
+				errs.add(i, ids[i], err)
+			}
+			continue
+		}
+		for i, item := range items {
+			// Objects that couldn't be retrieved are replaced by an error
+			// like {"error": {"code": "NotFoundError", "message": "..."}}.
+			var itemErr struct {
+				Error *Error `json:"error"`
+			}
+			if err := json.Unmarshal(item, &itemErr); err == nil && itemErr.Error != nil {
+				errs.add(start+i, ids[start+i], *itemErr.Error)
+				continue
+			}
+			obj := &Object{}
+			if err := json.Unmarshal(item, obj); err != nil {
+				errs.add(start+i, ids[start+i], err)
+				continue
+			}
+			objs[start+i] = obj
+		}
+	}
+	return objs, errs.errorOrNil()
+}
+
 // PatchObject modifies an existing object.
 func (cli *Client) PatchObject(url *url.URL, obj *Object, options ...RequestOption) error {
+	if err := cli.validate(obj); err != nil {
+		return err
+	}
 	req := &Request{}
 	req.Data = modifiedObject(*obj)
 	resp, err := cli.Patch(url, req, options...)
@@ -359,18 +668,26 @@ func (cli *Client) PatchObject(url *url.URL, obj *Object, options ...RequestOpti
 	return json.Unmarshal(resp.Data, obj)
 }
 
-// DownloadFile downloads a file given its hash (SHA-256, SHA-1 or MD5). The
-// file is written into the provided io.Writer.
-func (cli *Client) DownloadFile(hash string, w io.Writer) (int64, error) {
-	u := URL("files/%s/download", hash)
-	resp, err := cli.sendRequest("GET", u, nil, nil)
+// GetRaw sends a GET request to the specified API endpoint and writes the
+// response's body into the provided io.Writer, returning the number of bytes
+// written. This is useful for endpoints that return something else than JSON,
+// like ZIP files, screenshots, PCAPs or HTML behaviour reports. If the server
+// responds with an error the function returns it without writing anything.
+func (cli *Client) GetRaw(url *url.URL, w io.Writer, options ...RequestOption) (int64, error) {
+	o := opts(options...)
+	resp, err := cli.sendRequest("GET", o.url(url), nil, o.headers)
 	if err != nil {
 		return 0, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode == http.StatusOK {
-		return io.Copy(w, resp.Body)
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		body, err := responseBody(resp)
+		if err != nil {
+			return 0, err
+		}
+		defer body.Close()
+		return io.Copy(w, body)
 	}
 
 	// See if there is an error in the response.
@@ -379,7 +696,13 @@ func (cli *Client) DownloadFile(hash string, w io.Writer) (int64, error) {
 	}
 
 	// Last resort return a generic error.
-	return 0, fmt.Errorf("Unknown error downloading %q, HTTP response code: %d", hash, resp.StatusCode)
+	return 0, fmt.Errorf("Unknown error requesting %s, HTTP response code: %d", url, resp.StatusCode)
+}
+
+// DownloadFile downloads a file given its hash (SHA-256, SHA-1 or MD5). The
+// file is written into the provided io.Writer.
+func (cli *Client) DownloadFile(hash string, w io.Writer) (int64, error) {
+	return cli.GetRaw(URL("files/%s/download", hash), w)
 }
 
 // Iterator returns an iterator for a collection. If the endpoint passed to the
@@ -428,6 +751,11 @@ type Metadata struct {
 	// relationship.
 	Relationships map[string][]RelationshipMeta `json:"relationships" yaml:"relationships"`
 	Privileges    []string                      `json:"privileges" yaml:"privileges"`
+	// Dictionary containing the attributes of each object type, keys are
+	// object types and values are a list of AttributeMeta structures with
+	// information about the attribute. This is empty if the server doesn't
+	// provide attribute descriptions.
+	Attributes map[string][]AttributeMeta `json:"attributes" yaml:"attributes"`
 }
 
 // RelationshipMeta is the structure returned by each relationship from the
@@ -439,6 +767,15 @@ type RelationshipMeta struct {
 	Description string `json:"description" yaml:"description"`
 }
 
+// AttributeMeta is the structure returned by each attribute from the
+// /api/v3/metadata endpoint.
+type AttributeMeta struct {
+	// Name of the attribute.
+	Name string `json:"name" yaml:"name"`
+	// A verbose description for the attribute.
+	Description string `json:"description" yaml:"description"`
+}
+
 // GetMetadata retrieves VirusTotal metadata by calling the /api/v3/metadata
 // endpoint.
 func (cli *Client) GetMetadata() (*Metadata, error) {
@@ -451,12 +788,37 @@ func (cli *Client) GetMetadata() (*Metadata, error) {
 
 // NewFileScanner returns a new FileScanner.
 func (cli *Client) NewFileScanner() *FileScanner {
-	return &FileScanner{cli: cli}
+	return cli.NewFileScannerWithOptions()
+}
+
+// NewFileScannerWithOptions returns a new FileScanner configured with the
+// given options.
+func (cli *Client) NewFileScannerWithOptions(options ...FileScannerOption) *FileScanner {
+	s := &FileScanner{cli: cli}
+	for _, opt := range options {
+		opt(s)
+	}
+	return s
 }
 
 // NewURLScanner returns a new URLScanner.
 func (cli *Client) NewURLScanner() *URLScanner {
-	return &URLScanner{cli: cli}
+	return cli.NewURLScannerWithOptions()
+}
+
+// NewURLScannerWithOptions returns a new URLScanner configured with the given
+// options.
+func (cli *Client) NewURLScannerWithOptions(options ...URLScannerOption) *URLScanner {
+	s := &URLScanner{
+		cli:        cli,
+		workers:    4,
+		maxRetries: 3,
+		retryDelay: 5 * time.Second,
+	}
+	for _, opt := range options {
+		opt(s)
+	}
+	return s
 }
 
 // NewMonitorUploader returns a new MonitorUploader.
@@ -1,10 +1,21 @@
 package vt
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
+// Client must keep implementing VTClient, adding methods to the interface or
+// changing their signatures breaks external implementations.
+var _ VTClient = (*Client)(nil)
+
 func TestNewClientWithHTTPClientOption(t *testing.T) {
 	httpClient := &http.Client{}
 
@@ -20,3 +31,249 @@ func TestNewClientWithClientHeaders(t *testing.T) {
 		t.Fatalf("failed to set global header")
 	}
 }
+
+func TestWithEndpointOverride(t *testing.T) {
+	SetHost("https://www.virustotal.com")
+	c := NewClient("api-key",
+		WithEndpointOverride("files/*", "http://cache.example.com/vt/api/v3"),
+		WithEndpointOverride("urls/*/*", "http://urls.example.com/"))
+
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{"files/abcd", "http://cache.example.com/vt/api/v3/files/abcd"},
+		{"files/abcd?relationships=bundled_files", "http://cache.example.com/vt/api/v3/files/abcd?relationships=bundled_files"},
+		{"files/abcd/comments", "https://www.virustotal.com/api/v3/files/abcd/comments"},
+		{"urls/abcd/analyses", "http://urls.example.com/urls/abcd/analyses"},
+		{"domains/example.com", "https://www.virustotal.com/api/v3/domains/example.com"},
+	}
+	for _, test := range tests {
+		u := URL(test.url)
+		if got := c.overrideURL(u).String(); got != test.expected {
+			t.Errorf("expecting %s, got %s", test.expected, got)
+		}
+	}
+}
+
+func TestWithCompression(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept-Encoding") != "identity" {
+			t.Errorf("unexpected Accept-Encoding: %s", r.Header.Get("Accept-Encoding"))
+		}
+		if strings.Contains(r.Header.Get("User-Agent"), "gzip") {
+			t.Errorf("unexpected User-Agent: %s", r.Header.Get("User-Agent"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": {"type": "object_type", "id": "object_id"}}`))
+	}))
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api-key", WithCompression(false))
+	o, err := c.GetObject(URL("collection/object_id"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.ID() != "object_id" {
+		t.Fatalf("unexpected object ID: %s", o.ID())
+	}
+}
+
+func TestDeleteNoContent(t *testing.T) {
+	handlers := map[string]http.HandlerFunc{
+		"no content": func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		},
+		"zero length": func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Length", "0")
+			w.WriteHeader(http.StatusOK)
+		},
+		"chunked": func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			w.(http.Flusher).Flush()
+		},
+	}
+	for name, handler := range handlers {
+		ts := httptest.NewServer(handler)
+		SetHost(ts.URL)
+		c := NewClient("api-key")
+		resp, err := c.Delete(URL("collection/object_id"))
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", name, err)
+		} else if !resp.NoContent {
+			t.Errorf("%s: expecting NoContent", name)
+		}
+		resp, err = c.DeleteData(URL("collection/object_id/relationships/foo"), []string{"bar"})
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", name, err)
+		} else if !resp.NoContent {
+			t.Errorf("%s: expecting NoContent", name)
+		}
+		ts.Close()
+	}
+}
+
+func TestDeleteEmptyError(t *testing.T) {
+	handlers := map[string]http.HandlerFunc{
+		"chunked 5xx": func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.(http.Flusher).Flush()
+		},
+		"zero length 4xx": func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Length", "0")
+			w.WriteHeader(http.StatusNotFound)
+		},
+	}
+	for name, handler := range handlers {
+		ts := httptest.NewServer(handler)
+		SetHost(ts.URL)
+		c := NewClient("api-key")
+		if _, err := c.Delete(URL("collection/object_id")); err == nil {
+			t.Errorf("%s: expecting error", name)
+		}
+		if err := c.Object("collection", "object_id").Delete(); err == nil {
+			t.Errorf("%s: expecting error", name)
+		}
+		ts.Close()
+	}
+}
+
+func TestDeleteChunkedContent(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		w.Write([]byte(`{"data": "foo"}`))
+	}))
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api-key")
+	resp, err := c.Delete(URL("collection/object_id"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.NoContent {
+		t.Error("unexpected NoContent")
+	}
+	if string(resp.Data) != `"foo"` {
+		t.Errorf("unexpected data: %s", resp.Data)
+	}
+}
+
+func TestWithMaxConcurrentRequests(t *testing.T) {
+	var inFlight, maxInFlight int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": {"type": "object_type", "id": "object_id"}}`))
+	}))
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api-key", WithMaxConcurrentRequests(2))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.GetObject(URL("collection/object_id")); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if max := atomic.LoadInt32(&maxInFlight); max > 2 {
+		t.Errorf("expecting at most 2 concurrent requests, got %d", max)
+	}
+}
+
+func TestGetObjects(t *testing.T) {
+	var requests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		items := []string{}
+		for _, id := range strings.Split(r.URL.Query().Get("ids"), ",") {
+			if id == "missing" {
+				items = append(items, `{"error": {"code": "NotFoundError", "message": "not found"}}`)
+			} else {
+				items = append(items, `{"type": "file", "id": "`+id+`", "attributes": {}}`)
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": [` + strings.Join(items, ",") + `]}`))
+	}))
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api-key")
+
+	ids := make([]string, 150)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("h%d", i)
+	}
+	ids[120] = "missing"
+
+	objs, err := c.GetObjects(URL("files"), ids...)
+	if n := atomic.LoadInt32(&requests); n != 2 {
+		t.Errorf("expecting 2 requests, got %d", n)
+	}
+	if len(objs) != len(ids) {
+		t.Fatalf("expecting %d objects, got %d", len(ids), len(objs))
+	}
+	for i, obj := range objs {
+		if i == 120 {
+			if obj != nil {
+				t.Errorf("expecting nil object for missing ID")
+			}
+		} else if obj == nil || obj.ID() != ids[i] {
+			t.Errorf("unexpected object at index %d", i)
+		}
+	}
+	var multiErr *MultiError
+	if !errors.As(err, &multiErr) {
+		t.Fatalf("expecting MultiError, got %v", err)
+	}
+	if len(multiErr.Errors) != 1 || multiErr.Errors[0].Index != 120 || multiErr.Errors[0].ID != "missing" {
+		t.Errorf("unexpected errors: %v", multiErr)
+	}
+	var apiErr Error
+	if !errors.As(err, &apiErr) || apiErr.Code != "NotFoundError" {
+		t.Errorf("expecting NotFoundError, got %v", err)
+	}
+}
+
+func TestWithQueryParameter(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("relationship_counters"); got != "true" {
+			t.Errorf("unexpected relationship_counters: %s", got)
+		}
+		if got := r.URL.Query().Get("relationships"); got != "contacted_ips" {
+			t.Errorf("unexpected relationships: %s", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": {"type": "file", "id": "1234"}}`))
+	}))
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api-key")
+	u := URL("files/1234?relationships=contacted_ips")
+	if _, err := c.GetObject(u, WithRelationshipCounters()); err != nil {
+		t.Fatal(err)
+	}
+	if u.RawQuery != "relationships=contacted_ips" {
+		t.Errorf("URL was modified: %s", u)
+	}
+}
@@ -0,0 +1,5 @@
+module github.com/VirusTotal/vt-go/cmd/vtgo
+
+go 1.14
+
+require github.com/VirusTotal/vt-go v1.1.0
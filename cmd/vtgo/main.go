@@ -0,0 +1,251 @@
+// Copyright © 2017 The vt-go authors. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// vtgo is a small command-line tool built on top of vt-go. It serves both as
+// an example of how the high-level APIs in vt-go are used, and as a smoke test
+// that checks that they compose well. Usage:
+//
+//	vtgo [--apikey <key>] get [--format yaml|text|markdown] <type> <id>
+//	vtgo [--apikey <key>] search [--limit N] <query>
+//	vtgo [--apikey <key>] export [--format csv|json] [--attributes a,b] [--output <file>] [--timeout 30m] <query>
+//	vtgo [--apikey <key>] feed [--type files] [--checkpoint <file>]
+//	vtgo [--apikey <key>] scan [--timeout 10m] <file>
+//
+// The API key can be passed with --apikey or with the VT_APIKEY environment
+// variable.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/VirusTotal/vt-go"
+)
+
+var apikey = flag.String("apikey", os.Getenv("VT_APIKEY"), "VirusTotal API key")
+
+// Maximum number of objects returned by the API in a single page.
+const maxPageSize = 40
+
+var renderFormats = map[string]vt.RenderFormat{
+	"yaml":     vt.RenderYAML,
+	"text":     vt.RenderText,
+	"markdown": vt.RenderMarkdown,
+}
+
+// get prints the report for an object.
+func get(client *vt.Client, args []string) error {
+	fs := flag.NewFlagSet("get", flag.ExitOnError)
+	format := fs.String("format", "yaml", "output format: yaml, text or markdown")
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		return fmt.Errorf("usage: vtgo get [--format yaml|text|markdown] <type> <id>")
+	}
+	f, ok := renderFormats[*format]
+	if !ok {
+		return fmt.Errorf("unknown format \"%s\"", *format)
+	}
+	h := client.Object(fs.Arg(0), fs.Arg(1))
+	if err := h.Refresh(); err != nil {
+		return err
+	}
+	out, err := h.Render(f)
+	if err != nil {
+		return err
+	}
+	fmt.Print(out)
+	return nil
+}
+
+// search prints the objects matching a VirusTotal Intelligence query, one JSON
+// object per line.
+func search(client *vt.Client, args []string) error {
+	fs := flag.NewFlagSet("search", flag.ExitOnError)
+	limit := fs.Int("limit", 10, "maximum number of objects")
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		return fmt.Errorf("usage: vtgo search [--limit N] <query>")
+	}
+	batchSize := *limit
+	if batchSize > maxPageSize {
+		batchSize = maxPageSize
+	}
+	it, err := client.Search(strings.Join(fs.Args(), " "),
+		vt.IteratorLimit(*limit), vt.IteratorBatchSize(batchSize))
+	if err != nil {
+		return err
+	}
+	defer it.Close()
+	enc := json.NewEncoder(os.Stdout)
+	for it.Next() {
+		if err := enc.Encode(it.Get()); err != nil {
+			return err
+		}
+	}
+	return it.Error()
+}
+
+var exportFormats = map[string]vt.SearchExportFormat{
+	"csv":  vt.SearchExportCSV,
+	"json": vt.SearchExportJSON,
+}
+
+// export exports the objects matching a VirusTotal Intelligence query to a
+// file, or to the standard output if no file is given, waiting until the
+// export is ready for downloading it.
+func export(client *vt.Client, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	format := fs.String("format", "json", "export format: csv or json")
+	attributes := fs.String("attributes", "", "comma-separated list of attributes to export")
+	output := fs.String("output", "", "file where the export is written")
+	timeout := fs.Duration("timeout", 30*time.Minute, "maximum time to wait for the export")
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		return fmt.Errorf("usage: vtgo export [--format csv|json] [--attributes a,b] [--output <file>] [--timeout 30m] <query>")
+	}
+	f, ok := exportFormats[*format]
+	if !ok {
+		return fmt.Errorf("unknown format \"%s\"", *format)
+	}
+	var attrs []string
+	if *attributes != "" {
+		attrs = strings.Split(*attributes, ",")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	e, err := client.ExportSearch(strings.Join(fs.Args(), " "), f, attrs...)
+	if err != nil {
+		return err
+	}
+	if e, err = e.WaitForCompletion(ctx, client, 30*time.Second); err != nil {
+		return err
+	}
+
+	w := os.Stdout
+	if *output != "" {
+		if w, err = os.Create(*output); err != nil {
+			return err
+		}
+		defer w.Close()
+	}
+	if _, err := e.Download(client, w); err != nil {
+		return err
+	}
+	if w != os.Stdout {
+		return w.Close()
+	}
+	return nil
+}
+
+// feed prints the IDs of the objects received from a feed until interrupted.
+// If a checkpoint file is given, the feed resumes from the cursor stored in
+// the file, and the cursor is stored back as the feed advances.
+func feed(client *vt.Client, args []string) error {
+	fs := flag.NewFlagSet("feed", flag.ExitOnError)
+	feedType := fs.String("type", string(vt.FileFeed), "feed type")
+	checkpoint := fs.String("checkpoint", "", "file where the cursor is stored")
+	fs.Parse(args)
+
+	var options []vt.FeedOption
+	if *checkpoint != "" {
+		options = append(options, vt.FeedPersistCursor(vt.NewFileCursorStore(*checkpoint)))
+	}
+
+	f, err := client.NewFeed(vt.FeedType(*feedType), options...)
+	if err != nil {
+		return err
+	}
+
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		f.Stop()
+	}()
+
+	for obj := range f.C {
+		fmt.Println(obj.ID())
+	}
+
+	return f.Error()
+}
+
+// scan uploads a file, waits for the analysis to complete and prints the
+// file's analysis stats.
+func scan(client *vt.Client, args []string) error {
+	fs := flag.NewFlagSet("scan", flag.ExitOnError)
+	timeout := fs.Duration("timeout", 10*time.Minute, "maximum time to wait for the analysis")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: vtgo scan [--timeout 10m] <file>")
+	}
+	file, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	obj, err := client.NewFileScanner().ScanFileAndWait(ctx, file, vt.WaitInterval(15*time.Second))
+	if err != nil {
+		return err
+	}
+	stats, err := obj.LastAnalysisStats()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("malicious: %d\nsuspicious: %d\nundetected: %d\nharmless: %d\n",
+		stats.Malicious, stats.Suspicious, stats.Undetected, stats.Harmless)
+	return nil
+}
+
+var commands = map[string]func(*vt.Client, []string) error{
+	"get":    get,
+	"search": search,
+	"export": export,
+	"feed":   feed,
+	"scan":   scan,
+}
+
+func main() {
+	flag.Parse()
+
+	if *apikey == "" {
+		fmt.Fprintln(os.Stderr, "An API key is required, use --apikey or VT_APIKEY")
+		os.Exit(1)
+	}
+
+	command, ok := commands[flag.Arg(0)]
+	if !ok {
+		fmt.Fprintln(os.Stderr, "Usage: vtgo [--apikey <key>] get|search|export|feed|scan ...")
+		os.Exit(1)
+	}
+
+	client := vt.NewClient(*apikey, vt.WithMaxConcurrentRequests(4))
+	client.Agent = "vtgo"
+
+	if err := command(client, flag.Args()[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright © 2017 The vt-go authors. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vt
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FileCursorStore is a CursorStore that keeps the cursor in a file.
+type FileCursorStore struct {
+	path string
+}
+
+// NewFileCursorStore returns a FileCursorStore that keeps the cursor in the
+// file with the given path. The file is created when the first cursor is
+// saved.
+func NewFileCursorStore(path string) *FileCursorStore {
+	return &FileCursorStore{path: path}
+}
+
+// Load returns the cursor stored in the file, or an empty string if the file
+// doesn't exist.
+func (s *FileCursorStore) Load() (string, error) {
+	b, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+// Save writes the cursor to a temporary file that is renamed afterwards, so
+// that the stored cursor is not corrupted if the program is interrupted while
+// writing it.
+func (s *FileCursorStore) Save(cursor string) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(s.path), ".cursor")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.WriteString(cursor + "\n"); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
@@ -0,0 +1,21 @@
+package vt
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileCursorStore(t *testing.T) {
+	s := NewFileCursorStore(filepath.Join(t.TempDir(), "cursor"))
+	cursor, err := s.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, "", cursor)
+
+	assert.NoError(t, s.Save("abcd"))
+	assert.NoError(t, s.Save("efgh"))
+	cursor, err = s.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, "efgh", cursor)
+}
@@ -0,0 +1,221 @@
+// Copyright © 2017 The vt-go authors. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vt
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// DirectoryScanResult contains the result of scanning a file with
+// DirectoryScanner.
+type DirectoryScanResult struct {
+	// Path is the local path of the file.
+	Path string
+	// Object is the analysis returned after uploading the file, or the file
+	// object if it was already known and DirectoryScannerLookupFirst was
+	// used. It's nil if the scan failed.
+	Object *Object
+	// Uploaded is true if the file was uploaded.
+	Uploaded bool
+	// Number of attempts made for scanning the file.
+	Attempts int
+	// Err is the error occurred in the last attempt, if the scan failed. It
+	// can also be an error occurred while walking the directory.
+	Err error
+}
+
+// DirectoryScanner uploads the files in a directory and its subdirectories to
+// VirusTotal, sending multiple files in parallel. Uploads failing with a
+// transient error are retried.
+type DirectoryScanner struct {
+	cli         *Client
+	workers     int
+	maxRetries  int
+	retryDelay  time.Duration
+	lookupFirst bool
+	filter      func(path string, info os.FileInfo) bool
+	onProgress  func(path string, pct float32)
+	options     []FileScannerOption
+}
+
+// DirectoryScannerOption represents an option passed to NewDirectoryScanner.
+type DirectoryScannerOption func(*DirectoryScanner)
+
+// DirectoryScannerWorkers specifies the maximum number of files that are
+// uploaded in parallel. The default is 4.
+func DirectoryScannerWorkers(n int) DirectoryScannerOption {
+	return func(d *DirectoryScanner) {
+		d.workers = n
+	}
+}
+
+// DirectoryScannerRetries specifies the number of times that an upload
+// failing with a transient error is retried, and the delay between retries,
+// see BulkLookupRetries for how retries are spaced. The default is 3 retries
+// with a delay of 5 seconds.
+func DirectoryScannerRetries(n int, delay time.Duration) DirectoryScannerOption {
+	return func(d *DirectoryScanner) {
+		d.maxRetries = n
+		d.retryDelay = delay
+	}
+}
+
+// DirectoryScannerLookupFirst receives a boolean that indicates whether files
+// already known by VirusTotal must be looked up instead of uploaded, see
+// FileScanner.ScanOrLookup.
+func DirectoryScannerLookupFirst(b bool) DirectoryScannerOption {
+	return func(d *DirectoryScanner) {
+		d.lookupFirst = b
+	}
+}
+
+// DirectoryScannerFilter specifies a function that decides which files are
+// scanned, it receives the path and information of each regular file found,
+// and returns true if the file must be scanned. By default all regular files
+// are scanned.
+func DirectoryScannerFilter(f func(path string, info os.FileInfo) bool) DirectoryScannerOption {
+	return func(d *DirectoryScanner) {
+		d.filter = f
+	}
+}
+
+// DirectoryScannerProgress specifies a function that is called with the
+// percentage uploaded of each file. The function is called from multiple
+// goroutines, so it must be safe for concurrent use.
+func DirectoryScannerProgress(f func(path string, pct float32)) DirectoryScannerOption {
+	return func(d *DirectoryScanner) {
+		d.onProgress = f
+	}
+}
+
+// DirectoryScannerFileScannerOptions specifies options for the FileScanner
+// used for uploading each file, like FileScannerSubmissionName.
+func DirectoryScannerFileScannerOptions(options ...FileScannerOption) DirectoryScannerOption {
+	return func(d *DirectoryScanner) {
+		d.options = options
+	}
+}
+
+// NewDirectoryScanner returns a new DirectoryScanner.
+func (cli *Client) NewDirectoryScanner(options ...DirectoryScannerOption) *DirectoryScanner {
+	d := &DirectoryScanner{
+		cli:        cli,
+		workers:    4,
+		maxRetries: 3,
+		retryDelay: 5 * time.Second,
+	}
+	for _, opt := range options {
+		opt(d)
+	}
+	if d.workers < 1 {
+		d.workers = 1
+	}
+	return d
+}
+
+// Scan walks the directory tree rooted at root, scanning every regular file,
+// and sends the results through the returned channel as they are ready, which
+// means that results are not necessarily in the order in which files are
+// found. The returned channel is closed after the results for all the files
+// have been sent, or when the context is cancelled.
+//
+// Example:
+//
+//	for result := range client.NewDirectoryScanner().Scan(ctx, "/samples") {
+//		if result.Err != nil {
+//			...
+//		}
+//	}
+func (d *DirectoryScanner) Scan(ctx context.Context, root string) <-chan DirectoryScanResult {
+	paths := make(chan string)
+	results := make(chan DirectoryScanResult)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(paths)
+		filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				select {
+				case results <- DirectoryScanResult{Path: path, Err: err}:
+					return nil
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+			if !info.Mode().IsRegular() || (d.filter != nil && !d.filter(path, info)) {
+				return nil
+			}
+			select {
+			case paths <- path:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+
+	for i := 0; i < d.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range paths {
+				select {
+				case results <- d.scan(ctx, path):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+	return results
+}
+
+// scan uploads a single file, retrying the upload if needed.
+func (d *DirectoryScanner) scan(ctx context.Context, path string) DirectoryScanResult {
+	result := DirectoryScanResult{Path: path}
+	options := d.options
+	if d.onProgress != nil {
+		options = append(options[:len(options):len(options)],
+			FileScannerOnProgress(func(pct float32) { d.onProgress(path, pct) }))
+	}
+	s := d.cli.NewFileScannerWithOptions(options...)
+	result.Attempts, result.Err = retryTransient(ctx, nil, d.maxRetries, d.retryDelay, func() (err error) {
+		result.Object, result.Uploaded, err = d.scanFile(s, path)
+		return err
+	})
+	return result
+}
+
+func (d *DirectoryScanner) scanFile(s *FileScanner, path string) (*Object, bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, false, err
+	}
+	defer f.Close()
+	if d.lookupFirst {
+		return s.ScanFileOrLookup(f, nil)
+	}
+	obj, err := s.ScanFile(f, nil)
+	return obj, err == nil, err
+}
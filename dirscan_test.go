@@ -0,0 +1,86 @@
+package vt
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDirectoryScanner(t *testing.T) {
+	var requests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, header, err := r.FormFile("file")
+		assert.NoError(t, err)
+		w.Header().Set("Content-Type", "application/json")
+		// The first upload of b.txt fails with a transient error.
+		if header.Filename == "b.txt" && atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"error": {"code": "TransientError"}}`))
+			return
+		}
+		w.Write([]byte(`{"data": {"type": "analysis", "id": "` + header.Filename + `"}}`))
+	}))
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "vt")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0755))
+	for _, name := range []string{"a.txt", "sub/b.txt", "sub/c.skip"} {
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(name), 0644))
+	}
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+
+	var mu sync.Mutex
+	progress := make(map[string]float32)
+	s := c.NewDirectoryScanner(
+		DirectoryScannerWorkers(2),
+		DirectoryScannerRetries(1, time.Millisecond),
+		DirectoryScannerFilter(func(path string, info os.FileInfo) bool {
+			return !strings.HasSuffix(path, ".skip")
+		}),
+		DirectoryScannerProgress(func(path string, pct float32) {
+			mu.Lock()
+			progress[filepath.Base(path)] = pct
+			mu.Unlock()
+		}))
+
+	var ids []string
+	attempts := make(map[string]int)
+	for result := range s.Scan(context.Background(), dir) {
+		assert.NoError(t, result.Err)
+		assert.True(t, result.Uploaded)
+		ids = append(ids, result.Object.ID())
+		attempts[result.Object.ID()] = result.Attempts
+	}
+	sort.Strings(ids)
+	assert.Equal(t, []string{"a.txt", "b.txt"}, ids)
+	assert.Equal(t, map[string]int{"a.txt": 1, "b.txt": 2}, attempts)
+	assert.Equal(t, map[string]float32{"a.txt": 100, "b.txt": 100}, progress)
+}
+
+func TestDirectoryScannerWalkError(t *testing.T) {
+	c := NewClient("api_key")
+	var results []DirectoryScanResult
+	for result := range c.NewDirectoryScanner().Scan(context.Background(), "/nonexistent/vt") {
+		results = append(results, result)
+	}
+	if assert.Len(t, results, 1) {
+		assert.True(t, os.IsNotExist(results[0].Err))
+		assert.Equal(t, "/nonexistent/vt", results[0].Path)
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright © 2017 The vt-go authors. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vt
+
+import "time"
+
+// DNSRecord is a DNS record as returned in the "last_dns_records" attribute
+// of domains. Not all fields are relevant for every record type, for example
+// Priority is used only by MX records, while Flag and Tag are specific to CAA
+// records.
+type DNSRecord struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+	TTL   int64  `json:"ttl"`
+	// Priority of MX records.
+	Priority int64 `json:"priority,omitempty"`
+	// Flag and Tag of CAA records.
+	Flag int64  `json:"flag,omitempty"`
+	Tag  string `json:"tag,omitempty"`
+	// Fields specific to SOA records.
+	RName   string `json:"rname,omitempty"`
+	Serial  int64  `json:"serial,omitempty"`
+	Refresh int64  `json:"refresh,omitempty"`
+	Retry   int64  `json:"retry,omitempty"`
+	Expire  int64  `json:"expire,omitempty"`
+	Minimum int64  `json:"minimum,omitempty"`
+}
+
+// PopularityRank is the position of a domain in a popularity ranking.
+type PopularityRank struct {
+	Rank int64 `json:"rank"`
+	// Timestamp is the date in which the rank was computed, as a UNIX
+	// timestamp. Use Date for obtaining it as a time.
+	Timestamp int64 `json:"timestamp"`
+}
+
+// Date returns the date in which the rank was computed.
+func (r *PopularityRank) Date() time.Time {
+	return time.Unix(r.Timestamp, 0)
+}
+
+// Domain is an Object of type "domain", it provides typed accessors for the
+// most relevant attributes of a domain, while the generic methods from Object
+// are still available.
+type Domain struct {
+	*Object
+}
+
+// NewDomain returns a Domain from an Object of type "domain".
+func NewDomain(obj *Object) *Domain {
+	return &Domain{Object: obj}
+}
+
+// Categories returns the categories assigned to the domain by each vendor.
+func (d *Domain) Categories() (Categories, error) {
+	return d.GetCategories()
+}
+
+// Registrar returns the name of the company through which the domain was
+// registered.
+func (d *Domain) Registrar() (string, error) {
+	return d.GetString("registrar")
+}
+
+// CreationDate returns the date in which the domain was registered.
+func (d *Domain) CreationDate() (time.Time, error) {
+	return d.GetTime("creation_date")
+}
+
+// Whois returns the raw whois information for the domain.
+func (d *Domain) Whois() (string, error) {
+	return d.GetString("whois")
+}
+
+// LastDNSRecords returns the DNS records obtained the last time the domain was
+// resolved.
+func (d *Domain) LastDNSRecords() ([]DNSRecord, error) {
+	var records []DNSRecord
+	if err := d.decodeAttribute("last_dns_records", &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// DNSRecords returns the records of the given type (i.e: "A", "NS", "MX",
+// "CAA") from the last time the domain was resolved.
+func (d *Domain) DNSRecords(recordType string) ([]DNSRecord, error) {
+	records, err := d.LastDNSRecords()
+	if err != nil {
+		return nil, err
+	}
+	filtered := make([]DNSRecord, 0)
+	for _, r := range records {
+		if r.Type == recordType {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered, nil
+}
+
+// PopularityRanks returns the position of the domain in several popularity
+// rankings, keys in the map are ranking names, like "Alexa" or "Majestic".
+func (d *Domain) PopularityRanks() (map[string]PopularityRank, error) {
+	ranks := make(map[string]PopularityRank)
+	if err := d.decodeAttribute("popularity_ranks", &ranks); err != nil {
+		return nil, err
+	}
+	return ranks, nil
+}
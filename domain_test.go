@@ -0,0 +1,61 @@
+package vt
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDomain(t *testing.T) {
+	obj := &Object{}
+	err := json.Unmarshal([]byte(`{
+		"type": "domain",
+		"id": "example.com",
+		"attributes": {
+			"registrar": "RESERVED-Internet Assigned Numbers Authority",
+			"creation_date": 808372800,
+			"whois": "Domain Name: EXAMPLE.COM",
+			"categories": {"Forcepoint ThreatSeeker": "information technology"},
+			"last_dns_records": [
+				{"type": "A", "value": "93.184.216.34", "ttl": 3600},
+				{"type": "MX", "value": "mail.example.com", "ttl": 3600, "priority": 10},
+				{"type": "CAA", "value": "letsencrypt.org", "ttl": 300, "flag": 0, "tag": "issue"},
+				{"type": "A", "value": "93.184.216.35", "ttl": 3600}
+			],
+			"popularity_ranks": {"Majestic": {"rank": 50, "timestamp": 1600000000}}
+		}}`), obj)
+	assert.NoError(t, err)
+
+	d := NewDomain(obj)
+
+	registrar, err := d.Registrar()
+	assert.NoError(t, err)
+	assert.Equal(t, "RESERVED-Internet Assigned Numbers Authority", registrar)
+
+	date, err := d.CreationDate()
+	assert.NoError(t, err)
+	assert.Equal(t, time.Unix(808372800, 0), date)
+
+	categories, err := d.Categories()
+	assert.NoError(t, err)
+	assert.True(t, categories.HasCategory("information technology"))
+
+	records, err := d.LastDNSRecords()
+	assert.NoError(t, err)
+	assert.Len(t, records, 4)
+	assert.Equal(t, int64(10), records[1].Priority)
+	assert.Equal(t, "issue", records[2].Tag)
+
+	records, err = d.DNSRecords("A")
+	assert.NoError(t, err)
+	assert.Len(t, records, 2)
+
+	ranks, err := d.PopularityRanks()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(50), ranks["Majestic"].Rank)
+
+	_, err = NewDomain(&Object{}).Registrar()
+	assert.Error(t, err)
+}
@@ -0,0 +1,146 @@
+// Copyright © 2017 The vt-go authors. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vt
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ContentDecoder is a function that receives a reader with content compressed
+// with some Content-Encoding and returns a reader that produces the
+// uncompressed content.
+type ContentDecoder func(r io.Reader) (io.ReadCloser, error)
+
+var (
+	contentDecodersMu sync.RWMutex
+	// contentDecoders contains the Content-Encodings supported by the client,
+	// besides "identity" which is always supported. Decoders for "br" and
+	// "zstd" are not included by default as they are not part of the standard
+	// library, but they can be added with RegisterContentDecoder.
+	contentDecoders = map[string]ContentDecoder{
+		"gzip":    newGzipReader,
+		"x-gzip":  newGzipReader,
+		"deflate": newDeflateReader,
+	}
+)
+
+func newGzipReader(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
+
+// newDeflateReader returns a reader for "deflate" content. According to the
+// HTTP spec "deflate" means the zlib format, but some servers send raw
+// deflate streams, both variants are accepted.
+func newDeflateReader(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	header, err := br.Peek(2)
+	if err == nil && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(br)
+	}
+	return flate.NewReader(br), nil
+}
+
+// RegisterContentDecoder registers a decoder for the given Content-Encoding.
+// Registered encodings are advertised in the Accept-Encoding header of every
+// request. This allows using encodings like "br" or "zstd", which are not
+// supported by the standard library, without adding dependencies to this
+// package. For example, using github.com/klauspost/compress/zstd:
+//
+//	vt.RegisterContentDecoder("zstd", func(r io.Reader) (io.ReadCloser, error) {
+//		d, err := zstd.NewReader(r)
+//		if err != nil {
+//			return nil, err
+//		}
+//		return d.IOReadCloser(), nil
+//	})
+func RegisterContentDecoder(encoding string, decoder ContentDecoder) {
+	contentDecodersMu.Lock()
+	defer contentDecodersMu.Unlock()
+	contentDecoders[strings.ToLower(encoding)] = decoder
+}
+
+// acceptEncoding returns the value for the Accept-Encoding header, which
+// includes all the encodings with a registered decoder, gzip goes first.
+func acceptEncoding() string {
+	contentDecodersMu.RLock()
+	defer contentDecodersMu.RUnlock()
+	encodings := make([]string, 0, len(contentDecoders))
+	for encoding := range contentDecoders {
+		if encoding != "gzip" && encoding != "x-gzip" {
+			encodings = append(encodings, encoding)
+		}
+	}
+	sort.Strings(encodings)
+	return strings.Join(append([]string{"gzip"}, encodings...), ", ")
+}
+
+// multiReadCloser is a io.ReadCloser that reads from the outermost reader in
+// a chain of decoders and closes all of them.
+type multiReadCloser struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (m *multiReadCloser) Close() error {
+	var err error
+	for i := len(m.closers) - 1; i >= 0; i-- {
+		if e := m.closers[i].Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// responseBody returns a reader for the body of a HTTP response, taking care
+// of uncompressing the body according to the Content-Encoding header. When
+// multiple encodings are listed they are undone in reverse order, as they
+// were applied in the order they appear.
+func responseBody(resp *http.Response) (io.ReadCloser, error) {
+	header := resp.Header.Get("Content-Encoding")
+	if header == "" {
+		return resp.Body, nil
+	}
+	encodings := strings.Split(header, ",")
+	body := &multiReadCloser{Reader: resp.Body}
+	contentDecodersMu.RLock()
+	defer contentDecodersMu.RUnlock()
+	for i := len(encodings) - 1; i >= 0; i-- {
+		encoding := strings.ToLower(strings.TrimSpace(encodings[i]))
+		if encoding == "identity" || encoding == "" {
+			continue
+		}
+		decoder, ok := contentDecoders[encoding]
+		if !ok {
+			body.Close()
+			return nil, fmt.Errorf("unsupported Content-Encoding \"%s\"", encoding)
+		}
+		r, err := decoder(body.Reader)
+		if err != nil {
+			body.Close()
+			return nil, err
+		}
+		body.Reader = r
+		body.closers = append(body.closers, r)
+	}
+	return body, nil
+}
@@ -0,0 +1,118 @@
+package vt
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/zlib"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const encodingTestResponse = `{"data": {"type": "object_type", "id": "object_id"}}`
+
+func newEncodingTestServer(t *testing.T, encoding string, body []byte) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Contains(t, r.Header.Get("Accept-Encoding"), "deflate")
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", encoding)
+		w.Write(body)
+	}))
+}
+
+func TestContentEncodings(t *testing.T) {
+	var zlibBody, flateBody bytes.Buffer
+	zw := zlib.NewWriter(&zlibBody)
+	zw.Write([]byte(encodingTestResponse))
+	zw.Close()
+	fw, _ := flate.NewWriter(&flateBody, flate.DefaultCompression)
+	fw.Write([]byte(encodingTestResponse))
+	fw.Close()
+
+	RegisterContentDecoder("x-reverse", func(r io.Reader) (io.ReadCloser, error) {
+		b, err := ioutil.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+			b[i], b[j] = b[j], b[i]
+		}
+		return ioutil.NopCloser(bytes.NewReader(b)), nil
+	})
+	defer func() {
+		contentDecodersMu.Lock()
+		delete(contentDecoders, "x-reverse")
+		contentDecodersMu.Unlock()
+	}()
+
+	reversed := []byte(encodingTestResponse)
+	for i, j := 0, len(reversed)-1; i < j; i, j = i+1, j-1 {
+		reversed[i], reversed[j] = reversed[j], reversed[i]
+	}
+
+	tests := []struct {
+		encoding string
+		body     []byte
+	}{
+		{"identity", []byte(encodingTestResponse)},
+		{"deflate", zlibBody.Bytes()},
+		{"deflate", flateBody.Bytes()},
+		{"X-Reverse", reversed},
+	}
+
+	c := NewClient("api_key")
+	for _, test := range tests {
+		ts := newEncodingTestServer(t, test.encoding, test.body)
+		SetHost(ts.URL)
+		o, err := c.GetObject(URL("collection/object_id"))
+		assert.NoError(t, err, test.encoding)
+		if err == nil {
+			assert.Equal(t, "object_id", o.ID())
+		}
+		ts.Close()
+	}
+
+	ts := newEncodingTestServer(t, "br", []byte("garbage"))
+	defer ts.Close()
+	SetHost(ts.URL)
+	_, err := c.GetObject(URL("collection/object_id"))
+	assert.Error(t, err)
+	assert.True(t, strings.Contains(err.Error(), "unsupported Content-Encoding"))
+}
+
+func TestRegisteredBrotliDecoder(t *testing.T) {
+	// A real Brotli decoder is not in the standard library, base64 stands in
+	// for it, what matters is that the decoder registered for "br" is used.
+	var calls int
+	RegisterContentDecoder("br", func(r io.Reader) (io.ReadCloser, error) {
+		calls++
+		return ioutil.NopCloser(base64.NewDecoder(base64.StdEncoding, r)), nil
+	})
+	defer func() {
+		contentDecodersMu.Lock()
+		delete(contentDecoders, "br")
+		contentDecodersMu.Unlock()
+	}()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encodings := strings.Split(r.Header.Get("Accept-Encoding"), ", ")
+		assert.Contains(t, encodings, "br")
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "br")
+		w.Write([]byte(base64.StdEncoding.EncodeToString([]byte(encodingTestResponse))))
+	}))
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+	o, err := c.GetObject(URL("collection/object_id"))
+	assert.NoError(t, err)
+	assert.Equal(t, "object_id", o.ID())
+	assert.Equal(t, 1, calls)
+}
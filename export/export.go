@@ -0,0 +1,123 @@
+// Copyright © 2017 The vt-go authors. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package export provides functions for writing the objects returned by a
+// vt.Iterator in formats that can be consumed by other tools, like NDJSON
+// for data lakes or CSV for spreadsheets. Objects are written as they are
+// returned by the iterator, without keeping them in memory. It also provides
+// WriteFeed, which archives the objects received from a vt.Feed to files.
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+
+	vt "github.com/VirusTotal/vt-go"
+)
+
+const (
+	// IDColumn can be passed to WriteCSV for a column with the object's ID.
+	IDColumn = "_id"
+	// TypeColumn can be passed to WriteCSV for a column with the object's
+	// type.
+	TypeColumn = "_type"
+)
+
+// WriteNDJSON writes the objects returned by the iterator to w as newline
+// delimited JSON, one object per line, using the same format in which the
+// objects are returned by the API. It returns the number of objects written,
+// and the first error occurred while iterating or writing, if any. The
+// iterator is not closed.
+func WriteNDJSON(it *vt.Iterator, w io.Writer) (int, error) {
+	enc := json.NewEncoder(w)
+	n := 0
+	for it.Next() {
+		if err := enc.Encode(it.Get()); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, it.Error()
+}
+
+// WriteCSV writes the objects returned by the iterator to w in CSV format,
+// with a header row followed by one row per object. Each column contains the
+// value of an attribute, columns are specified by attribute names that can
+// include dots for referring to nested attributes, like in vt.Object.Get.
+// IDColumn and TypeColumn can be used for including the object's ID and type.
+// Missing attributes result in empty cells, while arrays and maps are written
+// as JSON. It returns the number of objects written, and the first error
+// occurred while iterating or writing, if any. The iterator is not closed.
+func WriteCSV(it *vt.Iterator, w io.Writer, columns ...string) (int, error) {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(columns); err != nil {
+		return 0, err
+	}
+	n := 0
+	row := make([]string, len(columns))
+	for it.Next() {
+		obj := it.Get()
+		for i, column := range columns {
+			cell, err := cellValue(obj, column)
+			if err != nil {
+				return n, err
+			}
+			row[i] = cell
+		}
+		if err := cw.Write(row); err != nil {
+			return n, err
+		}
+		n++
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return n, err
+	}
+	return n, it.Error()
+}
+
+// cellValue returns the textual representation of an object's attribute for
+// a CSV cell.
+func cellValue(obj *vt.Object, column string) (string, error) {
+	switch column {
+	case IDColumn:
+		return obj.ID(), nil
+	case TypeColumn:
+		return obj.Type(), nil
+	}
+	// Get returns an error when the attribute doesn't exist, which is not
+	// an error here.
+	v, err := obj.Get(column)
+	if err != nil {
+		return "", nil
+	}
+	switch value := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return value, nil
+	case json.Number:
+		return value.String(), nil
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(value), nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
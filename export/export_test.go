@@ -0,0 +1,58 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	vt "github.com/VirusTotal/vt-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestIterator(t *testing.T) *vt.Iterator {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": [
+			{"type": "file", "id": "h1", "attributes": {
+				"size": 10, "type_tag": "peexe", "names": ["a.exe", "b,c.exe"],
+				"last_analysis_stats": {"malicious": 3}}},
+			{"type": "file", "id": "h2", "attributes": {"size": 2.5}}
+		]}`))
+	}))
+	t.Cleanup(ts.Close)
+	vt.SetHost(ts.URL)
+	it, err := vt.NewClient("api_key").Iterator(vt.URL("files"))
+	assert.NoError(t, err)
+	t.Cleanup(it.Close)
+	return it
+}
+
+func TestWriteNDJSON(t *testing.T) {
+	var b bytes.Buffer
+	n, err := WriteNDJSON(newTestIterator(t), &b)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	assert.Len(t, lines, 2)
+	obj := &vt.Object{}
+	assert.NoError(t, json.Unmarshal([]byte(lines[1]), obj))
+	assert.Equal(t, "h2", obj.ID())
+	assert.Equal(t, "file", obj.Type())
+}
+
+func TestWriteCSV(t *testing.T) {
+	var b bytes.Buffer
+	n, err := WriteCSV(newTestIterator(t), &b,
+		IDColumn, "size", "type_tag", "names", "last_analysis_stats.malicious")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t,
+		"_id,size,type_tag,names,last_analysis_stats.malicious\n"+
+			"h1,10,peexe,\"[\"\"a.exe\"\",\"\"b,c.exe\"\"]\",3\n"+
+			"h2,2.5,,,\n",
+		b.String())
+}
@@ -0,0 +1,183 @@
+// Copyright © 2017 The vt-go authors. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	vt "github.com/VirusTotal/vt-go"
+)
+
+// FileOption is the type for the options accepted by WriteFeed.
+type FileOption func(*feedFiles)
+
+// MaxFileSize specifies the maximum size in bytes of the files written by
+// WriteFeed, a new file is started when the next object would exceed the
+// limit. The size is measured before compression. The default is 100MB.
+func MaxFileSize(size int64) FileOption {
+	return func(ff *feedFiles) {
+		ff.maxSize = size
+	}
+}
+
+// GzipFiles specifies whether the files written by WriteFeed are compressed
+// with gzip, in which case their names end with ".ndjson.gz".
+func GzipFiles(b bool) FileOption {
+	return func(ff *feedFiles) {
+		ff.gzip = b
+	}
+}
+
+// feedFiles writes objects to rotating NDJSON files in a directory.
+type feedFiles struct {
+	dir     string
+	maxSize int64
+	gzip    bool
+	// Current file, nil if there's none.
+	f    *os.File
+	w    io.Writer
+	gz   *gzip.Writer
+	size int64
+	// Cursors of the first object in the current file and of the object
+	// following the last one written to it.
+	from, to string
+}
+
+// WriteFeed writes the objects received from a feed to NDJSON files in the
+// given directory, one object per line, until the feed is stopped. The feed
+// must be created with vt.FeedPackages, as objects are received in whole
+// packages for knowing their cursors. Files are named FROM_TO.ndjson, where
+// FROM is the cursor of the first object in the file and TO the cursor that
+// follows the last one, which means that a feed can be resumed from the TO
+// cursor of the most recent file. Files are written with a ".tmp" suffix that
+// is removed once they are complete, so that the directory contains partial
+// files only if the process is interrupted. It returns the number of objects
+// written, and the error that stopped the feed or the first error occurred
+// while writing, if any. Example:
+//
+//	feed, err := client.NewFeed(vt.FileFeed, vt.FeedPackages(true))
+//	...
+//	n, err := export.WriteFeed(feed, "/data/feed", export.GzipFiles(true))
+func WriteFeed(feed *vt.Feed, dir string, options ...FileOption) (int, error) {
+	if feed.Packages == nil {
+		return 0, errors.New("feed must be created with vt.FeedPackages")
+	}
+	ff := &feedFiles{dir: dir, maxSize: 100 * 1024 * 1024}
+	for _, opt := range options {
+		opt(ff)
+	}
+	n := 0
+	for p := range feed.Packages {
+		for i, obj := range p.Objects {
+			b, err := json.Marshal(obj)
+			if err != nil {
+				ff.abort()
+				return n, err
+			}
+			b = append(b, '\n')
+			index := p.Offset + int64(i)
+			if err := ff.write(b, fmt.Sprintf("%s-%d", p.Name, index),
+				fmt.Sprintf("%s-%d", p.Name, index+1)); err != nil {
+				ff.abort()
+				return n, err
+			}
+			n++
+		}
+	}
+	if err := ff.close(); err != nil {
+		return n, err
+	}
+	return n, feed.Error()
+}
+
+// write writes a line for the object at the given cursor, next is the cursor
+// that follows it. A new file is started if needed.
+func (ff *feedFiles) write(line []byte, cursor, next string) error {
+	if ff.f != nil && ff.size+int64(len(line)) > ff.maxSize {
+		if err := ff.close(); err != nil {
+			return err
+		}
+	}
+	if ff.f == nil {
+		if err := ff.open(cursor); err != nil {
+			return err
+		}
+	}
+	if _, err := ff.w.Write(line); err != nil {
+		return err
+	}
+	ff.size += int64(len(line))
+	ff.to = next
+	return nil
+}
+
+// name returns the name of the current file once it's complete.
+func (ff *feedFiles) name() string {
+	name := ff.from + "_" + ff.to + ".ndjson"
+	if ff.gzip {
+		name += ".gz"
+	}
+	return filepath.Join(ff.dir, name)
+}
+
+func (ff *feedFiles) open(cursor string) error {
+	f, err := os.Create(filepath.Join(ff.dir, cursor+".tmp"))
+	if err != nil {
+		return err
+	}
+	ff.f, ff.w, ff.size, ff.from, ff.to = f, f, 0, cursor, cursor
+	if ff.gzip {
+		ff.gz = gzip.NewWriter(f)
+		ff.w = ff.gz
+	}
+	return nil
+}
+
+// close completes the current file, if any, and renames it to its final name.
+func (ff *feedFiles) close() error {
+	if ff.f == nil {
+		return nil
+	}
+	f := ff.f
+	ff.f = nil
+	if ff.gz != nil {
+		if err := ff.gz.Close(); err != nil {
+			f.Close()
+			return err
+		}
+		ff.gz = nil
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), ff.name())
+}
+
+// abort closes the current file, if any, leaving it with the ".tmp" suffix.
+func (ff *feedFiles) abort() {
+	if ff.f != nil {
+		ff.f.Close()
+		ff.f = nil
+	}
+}
@@ -0,0 +1,79 @@
+package export
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	vt "github.com/VirusTotal/vt-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestFeed(t *testing.T, options ...vt.FeedOption) *vt.Feed {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/202001011200") || strings.HasSuffix(r.URL.Path, "/202001011201") {
+			b, err := ioutil.ReadFile("../testdata/feed_package.bz2")
+			assert.NoError(t, err)
+			w.Write(b)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error": {"code": "NotFoundError"}}`))
+	}))
+	t.Cleanup(ts.Close)
+	vt.SetHost(ts.URL)
+	feed, err := vt.NewClient("api_key").NewFeed(vt.FileFeed, options...)
+	assert.NoError(t, err)
+	return feed
+}
+
+func TestWriteFeed(t *testing.T) {
+	dir := t.TempDir()
+	feed := newTestFeed(t, vt.FeedCursor("202001011200-1"), vt.FeedPackages(true))
+	n, err := WriteFeed(feed, dir, MaxFileSize(1))
+	// The feed stops after two missing packages.
+	assert.Error(t, err)
+	assert.Equal(t, 3, n)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*"))
+	assert.NoError(t, err)
+	names := []string{}
+	for _, f := range files {
+		names = append(names, filepath.Base(f))
+	}
+	assert.Equal(t, []string{
+		"202001011200-1_202001011200-2.ndjson",
+		"202001011201-0_202001011201-1.ndjson",
+		"202001011201-1_202001011201-2.ndjson",
+	}, names)
+
+	_, err = WriteFeed(newTestFeed(t), dir)
+	assert.Error(t, err)
+}
+
+func TestWriteFeedGzip(t *testing.T) {
+	dir := t.TempDir()
+	feed := newTestFeed(t, vt.FeedCursor("202001011200"), vt.FeedPackages(true))
+	n, err := WriteFeed(feed, dir, GzipFiles(true))
+	assert.Error(t, err)
+	assert.Equal(t, 4, n)
+
+	f, err := os.Open(filepath.Join(dir, "202001011200-0_202001011201-2.ndjson.gz"))
+	assert.NoError(t, err)
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	assert.NoError(t, err)
+	sc := bufio.NewScanner(gz)
+	lines := 0
+	for sc.Scan() {
+		lines++
+	}
+	assert.Equal(t, 4, lines)
+}
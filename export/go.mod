@@ -0,0 +1,8 @@
+module github.com/VirusTotal/vt-go/export
+
+go 1.14
+
+require (
+	github.com/VirusTotal/vt-go v1.1.0
+	github.com/stretchr/testify v1.7.0
+)
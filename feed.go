@@ -15,25 +15,93 @@ package vt
 
 import (
 	"bufio"
-	"compress/bzip2"
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
-// FeedType ...
+// FeedType is the type of the objects received from a feed, it's passed to
+// NewFeed and identifies the feed's endpoint.
 type FeedType string
 
 const (
 	// FileFeed is the feed type passed to NewFeed() for getting a feed with
 	// all the files being scanned by VirusTotal.
 	FileFeed FeedType = "files"
+	// URLFeed is the feed type passed to NewFeed() for getting a feed with
+	// all the URLs being scanned by VirusTotal.
+	URLFeed FeedType = "urls"
+	// DomainFeed is the feed type passed to NewFeed() for getting a feed with
+	// the domains being analysed by VirusTotal.
+	DomainFeed FeedType = "domains"
+	// IPAddressFeed is the feed type passed to NewFeed() for getting a feed
+	// with the IP addresses being analysed by VirusTotal.
+	IPAddressFeed FeedType = "ip_addresses"
+	// FileBehaviourFeed is the feed type passed to NewFeed() for getting a
+	// feed with the behaviour reports produced by VirusTotal's sandboxes.
+	// Objects received from this feed can be wrapped with NewFileBehaviour
+	// for downloading the artifacts generated by the sandbox.
+	FileBehaviourFeed FeedType = "file-behaviours"
+	// HuntingNotificationFeed is the feed type passed to NewFeed() for getting
+	// a feed with the notifications generated by your Livehunt rules. Objects
+	// received from this feed can be wrapped with NewHuntingNotification.
+	HuntingNotificationFeed FeedType = "hunting-notifications"
 )
 
+// Type of the objects received from each feed.
+var feedObjectTypes = map[FeedType]string{
+	FileFeed:                "file",
+	URLFeed:                 "url",
+	DomainFeed:              "domain",
+	IPAddressFeed:           "ip_address",
+	FileBehaviourFeed:       "file_behaviour",
+	HuntingNotificationFeed: "hunting_notification",
+}
+
+// parseFeedLine returns the object contained in a line from a package of the
+// given feed type. Lines usually contain a complete object with its type, ID
+// and attributes, as returned by the API, but lines in some feeds contain only
+// the object's attributes plus its ID. In the latter case the object's type is
+// inferred from the feed type.
+func parseFeedLine(feedType FeedType, line []byte) (*Object, error) {
+	var envelope struct {
+		Type string          `json:"type"`
+		ID   json.RawMessage `json:"id"`
+	}
+	if err := json.Unmarshal(line, &envelope); err != nil {
+		return nil, err
+	}
+	obj := &Object{}
+	if envelope.Type != "" && envelope.ID != nil {
+		if err := json.Unmarshal(line, obj); err != nil {
+			return nil, err
+		}
+		return obj, nil
+	}
+	objType, ok := feedObjectTypes[feedType]
+	if !ok {
+		return nil, fmt.Errorf("object without type in feed \"%s\"", feedType)
+	}
+	d := json.NewDecoder(bytes.NewReader(line))
+	d.UseNumber()
+	attributes := make(map[string]interface{})
+	if err := d.Decode(&attributes); err != nil {
+		return nil, err
+	}
+	id, _ := attributes["id"].(string)
+	delete(attributes, "id")
+	obj.data = objectData{ID: id, Type: objType, Attributes: attributes}
+	return obj, nil
+}
+
 // A Feed represents a stream of objects received from VirusTotal via the
 // feed API v3. This API allows you to get information about objects as they are
 // processed by VirusTotal in real-time. Objects are sent on channel C.
@@ -43,12 +111,197 @@ type Feed struct {
 	feedType FeedType
 	// t is the time of the current package and n is index of the current item
 	// within the package, the feed cursor is determined by the t and n.
-	t                        time.Time
-	n                        int64
-	stop                     chan bool
-	stopped                  bool
-	err                      error
-	missingPackagesTolerance int
+	t             time.Time
+	n             int64
+	stop          chan bool
+	stopped       bool
+	err           error
+	missingPolicy FeedMissingPackagePolicy
+	interner      *stringInterner
+	// Errors receives the errors occurred while retrieving the feed, see
+	// FeedError.
+	Errors chan *FeedError
+	// Time to wait before requesting again a package that is not available
+	// yet, it doubles on each consecutive attempt.
+	pollInterval time.Duration
+	// Packages are not requested until this time has passed since the end of
+	// the minute they cover.
+	latency time.Duration
+	// True if the cursor was specified with FeedCursor.
+	hasCursor bool
+	// Store where the cursor is saved after each package.
+	cursorStore FeedCursorStore
+	// Packages receives whole packages instead of C when the feed is created
+	// with FeedPackages, it's nil otherwise.
+	Packages chan *FeedPackage
+	// Items receives FeedItem instead of C when the feed is created with
+	// FeedItems, it's nil otherwise.
+	Items chan *FeedItem
+	// statsMu protects stats, statsTime and statsN, which are updated by the
+	// feed's goroutine and read by Stats.
+	statsMu sync.Mutex
+	stats   FeedStats
+	// Time of the package being retrieved, used for computing the lag, and
+	// index of the next item in the package.
+	statsTime time.Time
+	statsN    int64
+	started   time.Time
+	// Source of the current time and timers, see FeedClock.
+	clock Clock
+	// Tracks the items acknowledged by the consumer, see FeedAck.
+	ack  bool
+	acks *feedAcks
+	// Maximum number of packages retrieved concurrently, see FeedConcurrency.
+	concurrency int
+	// Packages being retrieved in advance, indexed by package time.
+	prefetched map[time.Time]*feedFetch
+	// Policy applied when C is full, and directory where objects are
+	// written with FeedOverflowSpill.
+	overflow FeedOverflowPolicy
+	spillDir string
+	spill    *feedSpill
+	// Predicate that objects must satisfy for being sent, see FeedFilter.
+	filter func(*Object) bool
+	// done is closed when the goroutine retrieving the feed finishes.
+	done chan struct{}
+}
+
+// feedFetch is a package retrieved in background, done is closed when objects
+// and err are ready.
+type feedFetch struct {
+	objects []*Object
+	raw     [][]byte
+	err     error
+	done    chan struct{}
+}
+
+// FeedStats contains metrics about the consumption of a feed, which can be
+// used for monitoring its health. See Feed.Stats.
+type FeedStats struct {
+	// Lag is how far behind real time the feed is, measured from the end of
+	// the minute covered by the package being retrieved.
+	Lag time.Duration
+	// Items is the number of objects delivered since the feed was created.
+	Items int64
+	// ItemsPerSecond is the average number of objects delivered per second
+	// since the feed was created.
+	ItemsPerSecond float64
+	// PackagesMissed is the number of packages skipped because they didn't
+	// exist.
+	PackagesMissed int64
+	// Retries is the number of times that a package was requested again
+	// because it wasn't available yet.
+	Retries int64
+	// Dropped is the number of objects discarded because of the
+	// FeedOverflowDropOldest policy.
+	Dropped int64
+	// Spilled is the number of objects written to disk because of the
+	// FeedOverflowSpill policy.
+	Spilled int64
+	// Filtered is the number of objects discarded by the predicate specified
+	// with FeedFilter. They are not included in Items.
+	Filtered int64
+}
+
+// FeedErrorKind classifies the errors reported by a feed.
+type FeedErrorKind int
+
+const (
+	// FeedErrorRequest is an error occurred while requesting a package, like
+	// a network error or an unexpected response from the server.
+	FeedErrorRequest FeedErrorKind = iota
+	// FeedErrorAuth indicates that the API key is not valid or doesn't have
+	// access to the feed.
+	FeedErrorAuth
+	// FeedErrorMissingPackage indicates that a package doesn't exist.
+	FeedErrorMissingPackage
+	// FeedErrorDecode indicates that a package contains an item that can't be
+	// decoded.
+	FeedErrorDecode
+	// FeedErrorCursorStore indicates that the cursor couldn't be saved in the
+	// store specified with FeedPersistCursor.
+	FeedErrorCursorStore
+	// FeedErrorSpill indicates that an object couldn't be written to disk, or
+	// read back, with the FeedOverflowSpill policy.
+	FeedErrorSpill
+)
+
+// String returns a textual representation of the error kind.
+func (k FeedErrorKind) String() string {
+	switch k {
+	case FeedErrorRequest:
+		return "request error"
+	case FeedErrorAuth:
+		return "authentication error"
+	case FeedErrorMissingPackage:
+		return "missing package"
+	case FeedErrorDecode:
+		return "decode error"
+	case FeedErrorCursorStore:
+		return "cursor store error"
+	case FeedErrorSpill:
+		return "spill error"
+	}
+	return fmt.Sprintf("FeedErrorKind(%d)", int(k))
+}
+
+// FeedError is an error occurred while retrieving a feed package. Errors are
+// sent to the feed's Errors channel as they occur, even if the feed can
+// continue, like when a package is missing but the number of missing packages
+// is tolerated. When an error stops the feed it's also returned by Error.
+type FeedError struct {
+	Kind FeedErrorKind
+	// Package is the name of the package where the error occurred, with
+	// format YYYYMMDDhhmm. It's empty for errors not related to a package.
+	Package string
+	// Line is the number of the line that couldn't be decoded, starting at 1,
+	// for FeedErrorDecode errors.
+	Line int
+	// Fatal is true if the error stopped the feed.
+	Fatal bool
+	Err   error
+}
+
+// Error implements the error interface.
+func (e *FeedError) Error() string {
+	if e.Package == "" {
+		return e.Err.Error()
+	}
+	if e.Kind == FeedErrorDecode {
+		return fmt.Sprintf("feed package %s, line %d: %s", e.Package, e.Line, e.Err)
+	}
+	return fmt.Sprintf("feed package %s: %s", e.Package, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *FeedError) Unwrap() error {
+	return e.Err
+}
+
+// Error codes returned by the API when the API key is not valid, or doesn't
+// grant access to the requested resource.
+var authErrorCodes = map[string]bool{
+	"AuthenticationRequiredError": true,
+	"ForbiddenError":              true,
+	"UserNotActiveError":          true,
+	"WrongCredentialsError":       true,
+}
+
+// newFeedError returns a FeedError for an error occurred while retrieving the
+// given package, classifying it according to its kind.
+func newFeedError(packageTime string, err error) *FeedError {
+	var feedErr *FeedError
+	if errors.As(err, &feedErr) {
+		return feedErr
+	}
+	feedErr = &FeedError{Kind: FeedErrorRequest, Package: packageTime, Err: err}
+	var apiErr Error
+	if err == errNotFound {
+		feedErr.Kind = FeedErrorMissingPackage
+	} else if errors.As(err, &apiErr) && authErrorCodes[apiErr.Code] {
+		feedErr.Kind = FeedErrorAuth
+	}
+	return feedErr
 }
 
 // FeedOption represents an option passed to a NewFeed.
@@ -68,25 +321,538 @@ func FeedBufferSize(size int) FeedOption {
 // If a empty string is passed as cursor the current time will be used.
 func FeedCursor(cursor string) FeedOption {
 	return func(f *Feed) error {
-		var err error
 		// An empty cursor is acceptable, it's equivalent to passing no cursor
 		// at all.
 		if cursor == "" {
 			return nil
 		}
-		// Cursor can be either YYYYMMDDhhmm or YYYYMMDDhhmm-N where N
-		// indicates a line number within package YYYYMMDDhhmm.
-		s := strings.Split(cursor, "-")
-		if len(s) > 1 {
-			f.n, err = strconv.ParseInt(s[1], 10, 32)
-		}
+		t, n, err := parseFeedCursor(cursor)
 		if err == nil {
-			f.t, err = time.Parse("200601021504", s[0])
+			f.t, f.n = t, n
+			f.hasCursor = true
 		}
 		return err
 	}
 }
 
+// FeedCursorStore is the interface implemented by types that persist the
+// cursor of a feed, see FeedPersistCursor. Load returns the last cursor saved,
+// or an empty string if no cursor has been saved yet. This interface has the
+// same methods than CursorStore, so a FileCursorStore can be used as well.
+type FeedCursorStore interface {
+	Load() (string, error)
+	Save(cursor string) error
+}
+
+// FeedPersistCursor specifies a FeedCursorStore where the feed saves its
+// cursor after each package and when it stops. If the feed is created without
+// FeedCursor it starts at the cursor loaded from the store, so consumers can
+// be restarted without keeping track of the cursor by themselves. Errors
+// occurred while saving the cursor don't stop the feed, they are sent to the
+// Errors channel.
+func FeedPersistCursor(store FeedCursorStore) FeedOption {
+	return func(f *Feed) error {
+		f.cursorStore = store
+		return nil
+	}
+}
+
+// feedCursor returns the cursor for the item with index n in the package for
+// time t.
+func feedCursor(t time.Time, n int64) string {
+	return fmt.Sprintf("%s-%d", t.Format("200601021504"), n)
+}
+
+// parseFeedCursor returns the package time and the item index within the
+// package indicated by a feed cursor. Cursor can be either YYYYMMDDhhmm or
+// YYYYMMDDhhmm-N where N indicates a line number within package YYYYMMDDhhmm.
+func parseFeedCursor(cursor string) (t time.Time, n int64, err error) {
+	s := strings.Split(cursor, "-")
+	if len(s) > 1 {
+		n, err = strconv.ParseInt(s[1], 10, 32)
+	}
+	if err == nil {
+		t, err = time.Parse("200601021504", s[0])
+	}
+	return t, n, err
+}
+
+// ErrDownloadExpired is returned when the token included in the download link
+// of a feed item is no longer valid. Tokens expire some time after the item is
+// published in the feed, so items must be downloaded soon after received.
+var ErrDownloadExpired = errors.New("download link expired")
+
+// Error codes returned by the API when a download token is not valid.
+var expiredDownloadCodes = map[string]bool{
+	"ForbiddenError": true,
+	"NotFoundError":  true,
+}
+
+// DownloadURL returns the link for downloading the file of an item received
+// from the file feed, which is in the "download_url" context attribute and
+// includes a token that authorizes the download.
+func (obj *Object) DownloadURL() (*url.URL, error) {
+	link, err := obj.GetContextString("download_url")
+	if err != nil {
+		return nil, err
+	}
+	if link == "" {
+		return nil, errors.New("object doesn't have a download link")
+	}
+	return url.Parse(link)
+}
+
+// DownloadFeedItem writes the file of an item received from the file feed into
+// the provided io.Writer, returning the number of bytes written. The download
+// uses the item's link, see Object.DownloadURL, instead of the file's hash,
+// so it works even for files that can't be downloaded with DownloadFile. If
+// the link's token has expired the returned error is ErrDownloadExpired, in
+// that case the file can still be downloaded with DownloadFile. Artifacts
+// from the file behaviour feed are downloaded with FileBehaviour's methods.
+func (cli *Client) DownloadFeedItem(obj *Object, w io.Writer) (int64, error) {
+	u, err := obj.DownloadURL()
+	if err != nil {
+		return 0, err
+	}
+	return cli.downloadLink(u, w)
+}
+
+// downloadLink writes the content pointed by a link that includes a download
+// token into w, returning ErrDownloadExpired if the token is not valid.
+func (cli *Client) downloadLink(u *url.URL, w io.Writer) (int64, error) {
+	n, err := cli.GetRaw(u, w)
+	var apiErr Error
+	if errors.As(err, &apiErr) && expiredDownloadCodes[apiErr.Code] {
+		return n, ErrDownloadExpired
+	}
+	return n, err
+}
+
+// FeedPackageStatus indicates whether a feed package is available or not.
+type FeedPackageStatus int
+
+const (
+	// FeedPackageAvailable indicates that the package can be downloaded.
+	FeedPackageAvailable FeedPackageStatus = iota
+	// FeedPackageMissing indicates that the package doesn't exist and won't
+	// exist in the future either.
+	FeedPackageMissing
+	// FeedPackageNotAvailableYet indicates that the package has not been
+	// published yet, it will be available later.
+	FeedPackageNotAvailableYet
+)
+
+// String returns a textual representation of the package status.
+func (s FeedPackageStatus) String() string {
+	switch s {
+	case FeedPackageAvailable:
+		return "available"
+	case FeedPackageMissing:
+		return "missing"
+	case FeedPackageNotAvailableYet:
+		return "not available yet"
+	}
+	return fmt.Sprintf("FeedPackageStatus(%d)", int(s))
+}
+
+// FeedPackage describes a per-minute feed package.
+type FeedPackage struct {
+	// Name of the package, with format YYYYMMDDhhmm.
+	Name   string
+	Time   time.Time
+	Status FeedPackageStatus
+	// Objects in the package, they are included only by functions that
+	// download the package, like GetFeedPackage, or by feeds created with
+	// FeedPackages.
+	Objects []*Object
+	// Offset is the index within the package of the first object in Objects.
+	// It's greater than zero only for the first package sent by a feed that
+	// started at a cursor pointing to the middle of a package.
+	Offset int64
+}
+
+// GetHourlyFeedPackage downloads the hourly package of the given feed type
+// that contains the objects processed during the hour of the given time, and
+// returns it with all its objects decoded. The package's name has format
+// YYYYMMDDhh. Hourly packages are useful for backfilling large periods of time
+// without minute granularity, but notice that they can contain a large number
+// of objects, all of them kept in memory.
+func (cli *Client) GetHourlyFeedPackage(feedType FeedType, t time.Time) (*FeedPackage, error) {
+	t = t.UTC().Truncate(time.Hour)
+	p := &FeedPackage{Name: t.Format("2006010215"), Time: t}
+	httpResp, err := cli.getFeedPackage(feedType, p.Name)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+	if p.Objects, _, err = decodeFeedPackage(feedType, p.Name, httpResp.Body, nil, false); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// OpenFeedPackage returns a reader for the per-minute package of the given
+// feed type that contains the objects processed at the given time, which is
+// truncated to minute precision. The package is returned as is, usually
+// compressed with bzip2 and with one JSON object per line, which is useful for
+// archiving raw packages. The reader must be closed by the caller.
+func (cli *Client) OpenFeedPackage(feedType FeedType, t time.Time) (io.ReadCloser, error) {
+	httpResp, err := cli.getFeedPackage(feedType, t.UTC().Format("200601021504"))
+	if err != nil {
+		return nil, err
+	}
+	return httpResp.Body, nil
+}
+
+// GetFeedPackage downloads the per-minute package of the given feed type that
+// contains the objects processed at the given time, which is truncated to
+// minute precision, and returns it with all its objects decoded.
+func (cli *Client) GetFeedPackage(feedType FeedType, t time.Time) (*FeedPackage, error) {
+	t = t.UTC().Truncate(time.Minute)
+	p := &FeedPackage{Name: t.Format("200601021504"), Time: t}
+	r, err := cli.OpenFeedPackage(feedType, t)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	if p.Objects, _, err = decodeFeedPackage(feedType, p.Name, r, nil, false); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// ListFeedPackages returns the per-minute packages for the given feed type
+// within a time range, indicating which ones are available and which ones are
+// missing. The range starts at "from" and ends right before "to", both are
+// truncated to minute precision. Packages are probed one by one, so this
+// function sends one request per minute in the range, but package contents
+// are not downloaded.
+func (cli *Client) ListFeedPackages(feedType FeedType, from, to time.Time) ([]FeedPackage, error) {
+	packages := []FeedPackage{}
+	for t := from.UTC().Truncate(time.Minute); t.Before(to); t = t.Add(time.Minute) {
+		p := FeedPackage{Name: t.Format("200601021504"), Time: t}
+		httpResp, err := cli.getFeedPackage(feedType, p.Name)
+		switch err {
+		case nil:
+			httpResp.Body.Close()
+			p.Status = FeedPackageAvailable
+		case errNotFound:
+			p.Status = FeedPackageMissing
+		case errNoAvailableYet:
+			p.Status = FeedPackageNotAvailableYet
+		default:
+			return packages, err
+		}
+		packages = append(packages, p)
+	}
+	return packages, nil
+}
+
+// FeedDelta describes the gap between two feed cursors.
+type FeedDelta struct {
+	// Time of the package where the gap starts, and index of the first
+	// item within that package.
+	From      time.Time
+	FromIndex int64
+	// Time of the package where the gap ends, and index of the first item
+	// within that package that is not part of the gap.
+	To      time.Time
+	ToIndex int64
+}
+
+// FeedCursorDelta returns the gap between two cursors, as returned by
+// Feed.Cursor. The "to" cursor can't point to a position before "from".
+func FeedCursorDelta(from, to string) (*FeedDelta, error) {
+	d := &FeedDelta{}
+	var err error
+	if d.From, d.FromIndex, err = parseFeedCursor(from); err != nil {
+		return nil, err
+	}
+	if d.To, d.ToIndex, err = parseFeedCursor(to); err != nil {
+		return nil, err
+	}
+	if d.To.Before(d.From) || (d.To.Equal(d.From) && d.ToIndex < d.FromIndex) {
+		return nil, fmt.Errorf("cursor %s is before %s", to, from)
+	}
+	return d, nil
+}
+
+// Duration returns the time window covered by the gap.
+func (d *FeedDelta) Duration() time.Duration {
+	return d.To.Sub(d.From)
+}
+
+// PackageCount returns the number of per-minute packages that contain items
+// in the gap, including partially covered packages at both ends. This is an
+// estimation, as some packages may be missing in the feed.
+func (d *FeedDelta) PackageCount() int {
+	n := int(d.To.Sub(d.From) / time.Minute)
+	if d.ToIndex > 0 {
+		n++
+	}
+	// If both cursors point to the same package, there's a gap only if the
+	// indexes are different.
+	if n == 1 && d.To.Equal(d.From) && d.ToIndex == d.FromIndex {
+		n = 0
+	}
+	return n
+}
+
+// Packages returns the names of the per-minute packages, with format
+// YYYYMMDDhhmm, that contain items in the gap. These names can be passed to
+// FeedCursor for backfilling the gap.
+func (d *FeedDelta) Packages() []string {
+	n := d.PackageCount()
+	packages := make([]string, n)
+	for i := 0; i < n; i++ {
+		packages[i] = d.From.Add(time.Duration(i) * time.Minute).Format("200601021504")
+	}
+	return packages
+}
+
+// FeedPollInterval specifies the time that the feed waits before requesting
+// again a package that was not available yet, which happens when the feed
+// reaches the most recent package. The waiting time doubles on each attempt
+// until the package is available. The default is 20 seconds.
+func FeedPollInterval(d time.Duration) FeedOption {
+	return func(f *Feed) error {
+		if d <= 0 {
+			return fmt.Errorf("invalid poll interval %s", d)
+		}
+		f.pollInterval = d
+		return nil
+	}
+}
+
+// FeedLatency specifies how far behind the current time the feed stays. A
+// package is not requested until the given time has passed since the end of
+// the minute covered by the package, which avoids requesting packages that
+// are not published yet. By default packages are requested as soon as the
+// feed reaches them, and those not available yet are polled as specified by
+// FeedPollInterval.
+func FeedLatency(d time.Duration) FeedOption {
+	return func(f *Feed) error {
+		if d < 0 {
+			return fmt.Errorf("invalid latency %s", d)
+		}
+		f.latency = d
+		return nil
+	}
+}
+
+// FeedMissingPackagePolicy controls how a feed handles packages that don't
+// exist. Some packages are legitimately missing, so by default the feed skips
+// a missing package without retrying it, reports the skip to the Errors
+// channel, and stops with an error if the next package is missing too.
+type FeedMissingPackagePolicy struct {
+	// Retries is the number of times that a missing package is requested
+	// again before skipping it.
+	Retries int
+	// RetryDelay is the time waited before each retry.
+	RetryDelay time.Duration
+	// Tolerance is the maximum number of consecutive packages that are
+	// skipped, the feed stops with an error when the next one is missing.
+	Tolerance int
+	// Silent indicates that skipped packages are not reported to the Errors
+	// channel. They are still counted in FeedStats.PackagesMissed.
+	Silent bool
+}
+
+// FeedMissingPackages specifies the policy for packages that don't exist, see
+// FeedMissingPackagePolicy. Example:
+//
+//	feed, err := client.NewFeed(vt.FileFeed, vt.FeedMissingPackages(
+//		vt.FeedMissingPackagePolicy{
+//			Retries:    2,
+//			RetryDelay: 30 * time.Second,
+//			Tolerance:  5,
+//		}))
+func FeedMissingPackages(policy FeedMissingPackagePolicy) FeedOption {
+	return func(f *Feed) error {
+		if policy.Retries < 0 || policy.RetryDelay < 0 || policy.Tolerance < 0 {
+			return errors.New("invalid missing package policy")
+		}
+		f.missingPolicy = policy
+		return nil
+	}
+}
+
+// FeedItem is an object received from a feed created with FeedItems, along
+// with the JSON line it was decoded from and its position in the feed.
+type FeedItem struct {
+	Object *Object
+	// Raw is the object's JSON line as it appears in the feed package, which
+	// can be archived without encoding the object again.
+	Raw json.RawMessage
+	// PackageTime is the time of the package containing the object.
+	PackageTime time.Time
+	// Index is the object's position within the package.
+	Index int64
+	// Tracker notified by Ack, nil if the feed was not created with FeedAck.
+	acks  *feedAcks
+	acked bool
+}
+
+// Cursor returns the feed cursor pointing to the item, a feed created with
+// this cursor starts with this item.
+func (i *FeedItem) Cursor() string {
+	return feedCursor(i.PackageTime, i.Index)
+}
+
+// FeedItems receives a boolean that indicates whether the feed must send
+// FeedItem to the Items channel, instead of objects to C, which is closed
+// without receiving any object. Items carry the raw JSON of each object,
+// which increases the feed's memory usage.
+func FeedItems(b bool) FeedOption {
+	return func(f *Feed) error {
+		if b {
+			f.Items = make(chan *FeedItem, 1000)
+		} else {
+			f.Items = nil
+		}
+		return nil
+	}
+}
+
+// FeedOverflowPolicy determines what a feed does when it has objects to send
+// but its channel C is full because the consumer is slower than the feed.
+type FeedOverflowPolicy int
+
+const (
+	// FeedOverflowBlock makes the feed wait until there's room in C, which
+	// stops the retrieval of packages. This is the default policy.
+	FeedOverflowBlock FeedOverflowPolicy = iota
+	// FeedOverflowDropOldest discards the oldest object in C for making room
+	// for the new one. Discarded objects are counted in FeedStats.Dropped.
+	FeedOverflowDropOldest
+	// FeedOverflowSpill writes the objects that don't fit in C to a file,
+	// from where they are sent to C in order as the consumer catches up. The
+	// directory is specified with FeedSpillDir. Objects written to disk are
+	// counted in FeedStats.Spilled. Notice that objects in the file are sent
+	// to C even after Stop is called, so C must be read until it's closed.
+	FeedOverflowSpill
+)
+
+// FeedOverflow specifies the policy applied when the feed's channel C is full,
+// see FeedOverflowPolicy. It doesn't apply to feeds created with FeedPackages
+// or FeedItems.
+func FeedOverflow(policy FeedOverflowPolicy) FeedOption {
+	return func(f *Feed) error {
+		if policy < FeedOverflowBlock || policy > FeedOverflowSpill {
+			return fmt.Errorf("invalid overflow policy %d", policy)
+		}
+		f.overflow = policy
+		return nil
+	}
+}
+
+// FeedSpillDir specifies the directory where the feed writes the objects that
+// don't fit in C with the FeedOverflowSpill policy. By default the directory
+// is the one returned by os.TempDir.
+func FeedSpillDir(dir string) FeedOption {
+	return func(f *Feed) error {
+		f.spillDir = dir
+		return nil
+	}
+}
+
+// FeedFilter specifies a predicate that objects must satisfy for being sent
+// by the feed, objects for which the predicate returns false are discarded
+// before reaching the channel, which saves the cost of sending and processing
+// objects the consumer is not interested in. Discarded objects still advance
+// the cursor, and are counted in FeedStats.Filtered. The filter doesn't apply
+// to feeds created with FeedPackages, as packages are sent whole. Example:
+//
+//	feed, err := client.NewFeed(vt.FileFeed, vt.FeedFilter(func(obj *vt.Object) bool {
+//		typeTag, _ := obj.GetString("type_tag")
+//		return typeTag == "peexe"
+//	}))
+func FeedFilter(predicate func(obj *Object) bool) FeedOption {
+	return func(f *Feed) error {
+		f.filter = predicate
+		return nil
+	}
+}
+
+// Clock provides the current time and timers, it allows simulating the
+// passage of time in tests.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// After returns a channel that receives the current time once the given
+	// duration has elapsed, like time.After.
+	After(d time.Duration) <-chan time.Time
+}
+
+// realClock is the Clock used by default, backed by the time package.
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// FeedClock specifies the clock used by the feed for obtaining the current
+// time and for waiting, which determine the default cursor, the latency
+// window, the polling for packages not available yet, and the feed's stats.
+// This is intended for tests that simulate the passage of time, instead of
+// relying on real sleeps.
+func FeedClock(clock Clock) FeedOption {
+	return func(f *Feed) error {
+		f.clock = clock
+		return nil
+	}
+}
+
+// FeedConcurrency specifies the maximum number of packages that the feed
+// downloads and decodes concurrently. By default packages are retrieved one at
+// a time, which is enough for keeping up with real time, but can be slow for
+// catching up when the feed starts at an old cursor. With n > 1 the feed
+// retrieves in advance up to n-1 packages after the current one, as long as
+// they are old enough for being available. Objects are still delivered in
+// order.
+func FeedConcurrency(n int) FeedOption {
+	return func(f *Feed) error {
+		if n < 1 {
+			return fmt.Errorf("invalid concurrency %d", n)
+		}
+		f.concurrency = n
+		return nil
+	}
+}
+
+// FeedPackages receives a boolean that indicates whether the feed must send
+// whole per-minute packages to the Packages channel, instead of individual
+// objects to C, which is closed without receiving any object. This reduces
+// the per-object overhead for consumers that process objects in bulk, like
+// those inserting them into a database. If the feed starts at a cursor that
+// points to the middle of a package, the first package contains only the
+// objects after the cursor.
+func FeedPackages(b bool) FeedOption {
+	return func(f *Feed) error {
+		if b {
+			f.Packages = make(chan *FeedPackage, 10)
+		} else {
+			f.Packages = nil
+		}
+		return nil
+	}
+}
+
+// FeedInternStrings receives a boolean that indicates whether string values
+// in the objects' attributes must be interned. When interning is enabled,
+// repeated values like engine names, type tags or categories share the same
+// underlying memory, which reduces the heap footprint of consumers that retain
+// many objects at the cost of some additional CPU time while decoding.
+func FeedInternStrings(b bool) FeedOption {
+	return func(f *Feed) error {
+		if b {
+			f.interner = newStringInterner()
+		} else {
+			f.interner = nil
+		}
+		return nil
+	}
+}
+
 // NewFeed creates a Feed that receives objects from the specified type. Objects
 // are send on channel C. The feed can be stopped at any moment by calling Stop.
 // This example illustrates how a Feed is typically used:
@@ -104,11 +870,11 @@ func FeedCursor(cursor string) FeedOption {
 //
 func (cli *Client) NewFeed(t FeedType, options ...FeedOption) (*Feed, error) {
 	feed := &Feed{
-		client:                   cli,
-		feedType:                 t,
-		t:                        time.Now().UTC().Add(-1 * time.Hour),
-		stop:                     make(chan bool, 1),
-		missingPackagesTolerance: 1,
+		client:        cli,
+		feedType:      t,
+		stop:          make(chan bool, 1),
+		missingPolicy: FeedMissingPackagePolicy{Tolerance: 1},
+		pollInterval:  20 * time.Second,
 	}
 
 	for _, opt := range options {
@@ -117,12 +883,45 @@ func (cli *Client) NewFeed(t FeedType, options ...FeedOption) (*Feed, error) {
 		}
 	}
 
+	if feed.clock == nil {
+		feed.clock = realClock{}
+	}
+	if !feed.hasCursor {
+		feed.t = feed.clock.Now().UTC().Add(-1 * time.Hour)
+	}
+
+	if feed.Packages != nil && feed.Items != nil {
+		return nil, errors.New("FeedPackages and FeedItems can't be used together")
+	}
+	if feed.ack && feed.Items == nil {
+		return nil, errors.New("FeedAck requires FeedItems")
+	}
+
+	if feed.cursorStore != nil && !feed.hasCursor {
+		cursor, err := feed.cursorStore.Load()
+		if err != nil {
+			return nil, err
+		}
+		if err := FeedCursor(cursor)(feed); err != nil {
+			return nil, err
+		}
+	}
+
 	// If the channel hasn't been created yet with a custom buffer size by
 	// WithBufferSize, let's create it with a default size.
 	if feed.C == nil {
 		feed.C = make(chan *Object, 1000)
 	}
 
+	feed.Errors = make(chan *FeedError, 100)
+	feed.started = feed.clock.Now()
+	feed.statsTime, feed.statsN = feed.t, feed.n
+	feed.done = make(chan struct{})
+	feed.startSpill()
+	if feed.ack {
+		feed.acks = &feedAcks{feed: feed}
+	}
+
 	go feed.retrieve()
 
 	return feed, nil
@@ -131,10 +930,39 @@ func (cli *Client) NewFeed(t FeedType, options ...FeedOption) (*Feed, error) {
 // Cursor returns a string that can be passed to FeedCursor for creating a
 // feed that resumes where a previous one left.
 func (f *Feed) Cursor() string {
-	return fmt.Sprintf("%s-%d", f.t.Format("200601021504"), f.n)
+	return feedCursor(f.t, f.n)
 }
 
-// Error returns any error occurred so far.
+// Stats returns the feed's current metrics. It's safe to call Stats while the
+// feed is running.
+func (f *Feed) Stats() FeedStats {
+	f.statsMu.Lock()
+	defer f.statsMu.Unlock()
+	stats := f.stats
+	now := f.clock.Now()
+	if lag := now.Sub(f.statsTime.Add(time.Minute)); lag > 0 {
+		stats.Lag = lag
+	}
+	if elapsed := now.Sub(f.started).Seconds(); elapsed > 0 {
+		stats.ItemsPerSecond = float64(stats.Items) / elapsed
+	}
+	return stats
+}
+
+// updateStats calls fn, if not nil, with the feed's stats locked, and updates
+// the feed's position used for computing the lag.
+func (f *Feed) updateStats(fn func(stats *FeedStats)) {
+	f.statsMu.Lock()
+	if fn != nil {
+		fn(&f.stats)
+	}
+	f.statsTime = f.t
+	f.statsN = f.n
+	f.statsMu.Unlock()
+}
+
+// Error returns the error that stopped the feed, if any, which is a
+// *FeedError.
 func (f *Feed) Error() error {
 	return f.err
 }
@@ -149,8 +977,165 @@ func (f *Feed) Stop() error {
 	return nil
 }
 
-// Send the object to the feed's channel, except if it was stopped.
+// Done returns a channel that is closed when the feed's goroutine finishes,
+// either because Stop was called or because of an error. At that point C has
+// been closed, although it may still contain buffered objects. After a call
+// to Restart, Done returns a new channel.
+func (f *Feed) Done() <-chan struct{} {
+	return f.done
+}
+
+// discard returns true if the object doesn't satisfy the feed's filter, in
+// which case the object is skipped by advancing the cursor.
+func (f *Feed) discard(object *Object) bool {
+	if f.filter == nil || f.filter(object) {
+		return false
+	}
+	f.n++
+	f.updateStats(func(stats *FeedStats) { stats.Filtered++ })
+	return true
+}
+
+// isRunning returns true if the feed's goroutine hasn't finished.
+func (f *Feed) isRunning() bool {
+	select {
+	case <-f.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// Seek moves a finished feed to the given cursor, which has the same format
+// than in FeedCursor, so that it continues from there when restarted with
+// Restart. An error is returned if the feed is still running, see Done.
+// Example:
+//
+//	feed.Stop()
+//	<-feed.Done()
+//	if err := feed.Seek(cursor); err != nil {
+//		...handle error
+//	}
+//	feed.Restart()
+//	for obj := range feed.C {
+//		...
+//	}
+func (f *Feed) Seek(cursor string) error {
+	if f.isRunning() {
+		return errors.New("feed is running")
+	}
+	t, n, err := parseFeedCursor(cursor)
+	if err != nil {
+		return err
+	}
+	f.t, f.n = t, n
+	f.prefetched = nil
+	f.updateStats(nil)
+	f.saveCursor()
+	return nil
+}
+
+// SeekTime is like Seek, but moves the feed to the package that contains the
+// objects processed at the given time.
+func (f *Feed) SeekTime(t time.Time) error {
+	return f.Seek(t.UTC().Format("200601021504"))
+}
+
+// Restart resumes a finished feed, either stopped by Stop or by an error, from
+// its current cursor. Channels C, Errors, Packages and Items are replaced with
+// new ones, so consumers must not keep references to the old ones. Objects
+// that were buffered in the old C are not sent again, but can still be
+// received from it. An error is returned if the feed is still running, see
+// Done.
+func (f *Feed) Restart() error {
+	if f.isRunning() {
+		return errors.New("feed is running")
+	}
+	f.C = make(chan *Object, cap(f.C))
+	if f.Packages != nil {
+		f.Packages = make(chan *FeedPackage, cap(f.Packages))
+	}
+	if f.Items != nil {
+		f.Items = make(chan *FeedItem, cap(f.Items))
+	}
+	f.Errors = make(chan *FeedError, cap(f.Errors))
+	f.stop = make(chan bool, 1)
+	f.done = make(chan struct{})
+	f.err = nil
+	f.stopped = false
+	f.startSpill()
+	if f.ack {
+		f.acks = &feedAcks{feed: f}
+	}
+	go f.retrieve()
+	return nil
+}
+
+// startSpill creates the queue where objects are written with the
+// FeedOverflowSpill policy.
+func (f *Feed) startSpill() {
+	if f.overflow != FeedOverflowSpill {
+		return
+	}
+	errs := f.Errors
+	f.spill = newFeedSpill(f.spillDir, f.C, func(err error) {
+		// The Errors channel is not closed until the spill finishes.
+		select {
+		case errs <- &FeedError{Kind: FeedErrorSpill, Err: err}:
+		default:
+		}
+	})
+}
+
+// Send the object to the feed's channel, except if it was stopped. If the
+// channel is full the overflow policy is applied.
 func (f *Feed) sendToChannel(object *Object) int {
+	switch f.overflow {
+	case FeedOverflowDropOldest:
+		for {
+			select {
+			case <-f.stop:
+				return stop
+			case f.C <- object:
+				return ok
+			default:
+			}
+			// The channel is full, discard the oldest object.
+			select {
+			case <-f.C:
+				f.updateStats(func(stats *FeedStats) { stats.Dropped++ })
+			default:
+			}
+		}
+	case FeedOverflowSpill:
+		select {
+		case <-f.stop:
+			return stop
+		default:
+		}
+		// Objects are sent directly only if there are no objects waiting
+		// on disk, as they must be sent first.
+		if f.spill.empty() {
+			select {
+			case f.C <- object:
+				return ok
+			default:
+			}
+		}
+		if err := f.spill.push(object); err != nil {
+			feedErr := &FeedError{
+				Kind:    FeedErrorSpill,
+				Package: f.t.Format("200601021504"),
+				Fatal:   true,
+				Err:     err,
+			}
+			f.err = feedErr
+			f.reportError(feedErr)
+			return stop
+		}
+		f.updateStats(func(stats *FeedStats) { stats.Spilled++ })
+		return ok
+	}
 	select {
 	case <-f.stop:
 		return stop
@@ -165,7 +1150,7 @@ func (f *Feed) wait(d time.Duration) int {
 	select {
 	case <-f.stop:
 		return stop
-	case <-time.After(d):
+	case <-f.clock.After(d):
 		return ok
 	}
 }
@@ -173,32 +1158,115 @@ func (f *Feed) wait(d time.Duration) int {
 var errNoAvailableYet = errors.New("not available yet")
 var errNotFound = errors.New("not found")
 
-func (f *Feed) getObjects(packageTime string) ([]*Object, error) {
+// getFeedPackage sends the request for a feed package and returns the HTTP
+// response, which must be closed by the caller. If the package is not
+// available yet errNoAvailableYet is returned, if it doesn't exist the error
+// is errNotFound.
+func (cli *Client) getFeedPackage(feedType FeedType, packageTime string) (*http.Response, error) {
 
-	u := URL("feeds/%s/%s", f.feedType, packageTime)
+	u := URL("feeds/%s/%s", feedType, packageTime)
 
-	httpResp, err := f.client.sendRequest("GET", u, nil, nil)
+	httpResp, err := cli.sendRequest("GET", u, nil, nil)
 	if err != nil {
 		return nil, err
 	}
-	defer httpResp.Body.Close()
 
 	switch httpResp.StatusCode {
+	case http.StatusOK:
+		return httpResp, nil
 	case http.StatusBadRequest:
-		if resp, err := f.client.parseResponse(httpResp); err != nil {
-			if resp.Error.Code == "NotAvailableYet" {
+		if resp, err := cli.parseResponse(httpResp); err != nil {
+			if resp != nil && resp.Error.Code == "NotAvailableYet" {
+				httpResp.Body.Close()
 				return nil, errNoAvailableYet
 			}
 		}
 	case http.StatusNotFound:
+		httpResp.Body.Close()
 		return nil, errNotFound
+	default:
+		// Return the API error included in the response, if any.
+		if _, err := cli.parseResponse(httpResp); err != nil {
+			var apiErr Error
+			if errors.As(err, &apiErr) {
+				httpResp.Body.Close()
+				return nil, apiErr
+			}
+		}
 	}
 
-	if httpResp.StatusCode != http.StatusOK {
-		return nil, errors.New(httpResp.Status)
+	httpResp.Body.Close()
+	return nil, errors.New(httpResp.Status)
+}
+
+// getObjects returns the objects in a package, and also their raw JSON lines
+// if the feed was created with FeedItems.
+func (f *Feed) getObjects(packageTime string, interner *stringInterner) ([]*Object, [][]byte, error) {
+
+	httpResp, err := f.client.getFeedPackage(f.feedType, packageTime)
+	if err != nil {
+		return nil, nil, err
 	}
+	defer httpResp.Body.Close()
+
+	return decodeFeedPackage(f.feedType, packageTime, httpResp.Body, interner, f.Items != nil)
+}
 
-	sc := bufio.NewScanner(bzip2.NewReader(httpResp.Body))
+// fetch returns the objects in the package for time t, see getObjects. If the
+// feed's concurrency is greater than one, it also starts retrieving in
+// background the packages that follow, so that they are ready when requested.
+func (f *Feed) fetch(t time.Time) ([]*Object, [][]byte, error) {
+	if f.concurrency < 2 {
+		return f.getObjects(t.Format("200601021504"), f.interner)
+	}
+	if f.prefetched == nil {
+		f.prefetched = make(map[time.Time]*feedFetch)
+	}
+	for i := 0; i < f.concurrency; i++ {
+		pt := t.Add(time.Duration(i) * time.Minute)
+		// Packages that may not be available yet are not retrieved in advance,
+		// with the exception of the current one.
+		if i > 0 && pt.Add(time.Minute+f.latency).After(f.clock.Now()) {
+			break
+		}
+		if _, ok := f.prefetched[pt]; ok {
+			continue
+		}
+		ff := &feedFetch{done: make(chan struct{})}
+		f.prefetched[pt] = ff
+		go func() {
+			// Strings are interned below, as the interner is not safe for
+			// concurrent use.
+			ff.objects, ff.raw, ff.err = f.getObjects(pt.Format("200601021504"), nil)
+			close(ff.done)
+		}()
+	}
+	ff := f.prefetched[t]
+	delete(f.prefetched, t)
+	<-ff.done
+	if f.interner != nil {
+		for _, obj := range ff.objects {
+			f.interner.internObject(obj)
+		}
+	}
+	return ff.objects, ff.raw, ff.err
+}
+
+// decodeFeedPackage decodes the objects in a feed package, which is usually
+// compressed with bzip2, see decompressFeedPackage. The objects' strings are
+// interned if interner is not nil. If keepRaw is true it also returns the JSON
+// line for each object.
+func decodeFeedPackage(feedType FeedType, packageTime string, r io.Reader, interner *stringInterner, keepRaw bool) ([]*Object, [][]byte, error) {
+
+	content, err := decompressFeedPackage(r)
+	if err != nil {
+		return nil, nil, &FeedError{
+			Kind:    FeedErrorDecode,
+			Package: packageTime,
+			Err:     err,
+		}
+	}
+	sc := bufio.NewScanner(content)
 	// By default bufio.Scanner uses a buffer that is limited to a maximum size
 	// defined by bufio.MaxScanBufferSize (64KB). This is too small for
 	// accommodating the large JSONs stored in the feed files. So we create an
@@ -207,61 +1275,196 @@ func (f *Feed) getObjects(packageTime string) ([]*Object, error) {
 	sc.Buffer(buffer, 10*1024*1024)
 
 	objects := make([]*Object, 0)
+	var raw [][]byte
 	for sc.Scan() {
-		obj := &Object{}
-		if err := json.Unmarshal(sc.Bytes(), obj); err != nil {
-			return objects, err
+		obj, err := parseFeedLine(feedType, sc.Bytes())
+		if err != nil {
+			return objects, raw, &FeedError{
+				Kind:    FeedErrorDecode,
+				Package: packageTime,
+				Line:    len(objects) + 1,
+				Err:     err,
+			}
+		}
+		if interner != nil {
+			interner.internObject(obj)
 		}
 		objects = append(objects, obj)
+		if keepRaw {
+			// The scanner reuses its buffer, so the line must be copied.
+			raw = append(raw, append([]byte(nil), sc.Bytes()...))
+		}
 	}
 
-	return objects, sc.Err()
+	return objects, raw, sc.Err()
 }
 
 func (f *Feed) retrieve() {
-	waitDuration := 20 * time.Second
+	waitDuration := f.pollInterval
 	missingPackages := 0
+	missingRetries := 0
 loop:
 	for {
+		// Wait until the package is older than the latency window.
+		if d := f.t.Add(time.Minute + f.latency).Sub(f.clock.Now()); f.latency > 0 && d > 0 {
+			if f.wait(d) == stop {
+				break loop
+			}
+		}
 		packageTime := f.t.Format("200601021504") // YYYYMMDDhhmm
-		objects, err := f.getObjects(packageTime)
-		objects = objects[f.n:]
+		objects, raw, err := f.fetch(f.t)
 		switch err {
 		case nil:
-			for _, object := range objects {
-				if f.sendToChannel(object) == stop {
+			if f.n > int64(len(objects)) {
+				f.n = int64(len(objects))
+			}
+			if f.Packages != nil {
+				p := &FeedPackage{
+					Name:    packageTime,
+					Time:    f.t,
+					Status:  FeedPackageAvailable,
+					Objects: objects[f.n:],
+					Offset:  f.n,
+				}
+				select {
+				case f.Packages <- p:
+				case <-f.stop:
 					break loop
 				}
-				f.n++
+				f.updateStats(func(stats *FeedStats) {
+					stats.Items += int64(len(p.Objects))
+				})
+			} else if f.Items != nil {
+				for _, object := range objects[f.n:] {
+					if f.discard(object) {
+						continue
+					}
+					item := &FeedItem{
+						Object:      object,
+						Raw:         raw[f.n],
+						PackageTime: f.t,
+						Index:       f.n,
+					}
+					if f.acks != nil {
+						f.acks.push(item)
+					}
+					select {
+					case f.Items <- item:
+					case <-f.stop:
+						break loop
+					}
+					f.n++
+					f.updateStats(func(stats *FeedStats) { stats.Items++ })
+				}
+			} else {
+				for _, object := range objects[f.n:] {
+					if f.discard(object) {
+						continue
+					}
+					if f.sendToChannel(object) == stop {
+						break loop
+					}
+					f.n++
+					f.updateStats(func(stats *FeedStats) { stats.Items++ })
+				}
 			}
 			f.t = f.t.Add(60 * time.Second)
 			f.n = 0
-			waitDuration = 20 * time.Second
+			waitDuration = f.pollInterval
 			missingPackages = 0
+			missingRetries = 0
+			f.updateStats(nil)
+			f.saveCursor()
 		case errNoAvailableYet:
-			// Feed package is not available yet, let's wait for 1 minute and
-			// try again. If Close() is called during the waiting period it
+			// Feed package is not available yet, let's wait for the poll
+			// interval and try again. If Close() is called during the waiting period it
 			// exits early and breaks the loop.
 			if f.wait(waitDuration) == stop {
 				break loop
 			}
 			waitDuration *= 2
+			f.updateStats(func(stats *FeedStats) { stats.Retries++ })
 		case errNotFound:
-			// The feed tolerates some missing packages, if the number of missing
-			// packages is greater than missingPackagesTolerance an error is
-			// returned, if not, it tries to get the next package.
+			// The package is requested again as many times as indicated by
+			// the missing package policy before giving up on it.
+			if missingRetries < f.missingPolicy.Retries {
+				missingRetries++
+				if f.wait(f.missingPolicy.RetryDelay) == stop {
+					break loop
+				}
+				f.updateStats(func(stats *FeedStats) { stats.Retries++ })
+				continue
+			}
+			missingRetries = 0
+			// The feed tolerates some missing packages, if the number of
+			// consecutive missing packages is greater than the policy's
+			// tolerance an error is returned, if not, it tries to get the next
+			// package.
 			missingPackages++
-			if missingPackages > f.missingPackagesTolerance {
-				f.err = err
+			if missingPackages > f.missingPolicy.Tolerance {
+				f.fail(packageTime, err)
 				break loop
 			}
+			if !f.missingPolicy.Silent {
+				f.reportError(newFeedError(packageTime, err))
+			}
 			f.t = f.t.Add(60 * time.Second)
+			f.updateStats(func(stats *FeedStats) { stats.PackagesMissed++ })
 		default:
-			f.err = err
+			f.fail(packageTime, err)
 			break loop
 		}
 	}
+	f.saveCursor()
 	f.stopped = true
+	if f.spill != nil {
+		f.spill.finish()
+	}
 	close(f.C)
+	if f.Packages != nil {
+		close(f.Packages)
+	}
+	if f.Items != nil {
+		close(f.Items)
+	}
+	close(f.Errors)
 	close(f.stop)
+	close(f.done)
+}
+
+// reportError sends an error to the Errors channel, the error is discarded if
+// the channel's buffer is full, so that consumers not interested in errors
+// don't block the feed.
+func (f *Feed) reportError(err *FeedError) {
+	select {
+	case f.Errors <- err:
+	default:
+	}
+}
+
+// saveCursor saves the feed's cursor in the store specified with
+// FeedPersistCursor, if any.
+func (f *Feed) saveCursor() {
+	if f.cursorStore == nil {
+		return
+	}
+	if f.acks != nil {
+		f.acks.save()
+		return
+	}
+	if err := f.cursorStore.Save(f.Cursor()); err != nil {
+		f.reportError(&FeedError{
+			Kind:    FeedErrorCursorStore,
+			Package: f.t.Format("200601021504"),
+			Err:     err,
+		})
+	}
+}
+
+// fail records an error that stops the feed.
+func (f *Feed) fail(packageTime string, err error) {
+	feedErr := newFeedError(packageTime, err)
+	feedErr.Fatal = true
+	f.err = feedErr
+	f.reportError(feedErr)
 }
@@ -0,0 +1,678 @@
+package vt
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeedCursorDelta(t *testing.T) {
+	d, err := FeedCursorDelta("202001011200-5", "202001011203")
+	assert.NoError(t, err)
+	assert.Equal(t, 3*time.Minute, d.Duration())
+	assert.Equal(t, int64(5), d.FromIndex)
+	assert.Equal(t, 3, d.PackageCount())
+	assert.Equal(t, []string{"202001011200", "202001011201", "202001011202"}, d.Packages())
+
+	d, err = FeedCursorDelta("202001011200-5", "202001011201-10")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"202001011200", "202001011201"}, d.Packages())
+
+	d, err = FeedCursorDelta("202001011200-5", "202001011200-5")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, d.PackageCount())
+
+	_, err = FeedCursorDelta("202001011203", "202001011200")
+	assert.Error(t, err)
+	_, err = FeedCursorDelta("foo", "202001011200")
+	assert.Error(t, err)
+}
+
+func TestListFeedPackages(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/202001011200"):
+			w.Write([]byte("BZh"))
+		case strings.HasSuffix(r.URL.Path, "/202001011201"):
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": {"code": "NotFoundError"}}`))
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error": {"code": "NotAvailableYet"}}`))
+		}
+	}))
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+
+	from := time.Date(2020, 1, 1, 12, 0, 30, 0, time.UTC)
+	packages, err := c.ListFeedPackages(FileFeed, from, from.Add(2*time.Minute))
+	assert.NoError(t, err)
+	assert.Len(t, packages, 3)
+	assert.Equal(t, "202001011200", packages[0].Name)
+	assert.Equal(t, FeedPackageAvailable, packages[0].Status)
+	assert.Equal(t, FeedPackageMissing, packages[1].Status)
+	assert.Equal(t, FeedPackageNotAvailableYet, packages[2].Status)
+}
+
+func TestParseFeedLine(t *testing.T) {
+	obj, err := parseFeedLine(URLFeed, []byte(
+		`{"type": "url", "id": "1234", "attributes": {"url": "http://example.com"}}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "url", obj.Type())
+	assert.Equal(t, "1234", obj.ID())
+	assert.Equal(t, "http://example.com", obj.MustGetString("url"))
+
+	obj, err = parseFeedLine(DomainFeed, []byte(
+		`{"id": "example.com", "registrar": "Namecheap", "reputation": 3}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "domain", obj.Type())
+	assert.Equal(t, "example.com", obj.ID())
+	assert.Equal(t, "Namecheap", obj.MustGetString("registrar"))
+	assert.Equal(t, int64(3), obj.MustGetInt64("reputation"))
+	assert.ElementsMatch(t, []string{"registrar", "reputation"}, obj.Attributes())
+
+	obj, err = parseFeedLine(IPAddressFeed, []byte(`{"id": "8.8.8.8", "country": "US"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "ip_address", obj.Type())
+
+	_, err = parseFeedLine(FeedType("unknown"), []byte(`{"id": "1"}`))
+	assert.Error(t, err)
+	_, err = parseFeedLine(FileFeed, []byte(`not json`))
+	assert.Error(t, err)
+}
+
+// newFeedTestServer returns a test server that serves the packages in the
+// map, the keys are package names and the values are paths to bzip2-compressed
+// files. Missing packages are not found, except the one named notAvailableYet.
+func newFeedTestServer(t *testing.T, packages map[string]string, notAvailableYet string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		if path, ok := packages[name]; ok {
+			b, err := ioutil.ReadFile(path)
+			assert.NoError(t, err)
+			w.Write(b)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if name == notAvailableYet {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error": {"code": "NotAvailableYet"}}`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error": {"code": "NotFoundError"}}`))
+	}))
+}
+
+func TestFeedErrors(t *testing.T) {
+	ts := newFeedTestServer(t, map[string]string{
+		"202001011200": "testdata/feed_package.bz2",
+		"202001011202": "testdata/feed_package_bad_line.bz2",
+	}, "")
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+	feed, err := c.NewFeed(FileFeed, FeedCursor("202001011200"))
+	assert.NoError(t, err)
+
+	ids := []string{}
+	for obj := range feed.C {
+		ids = append(ids, obj.ID())
+	}
+	assert.Equal(t, []string{"1", "2"}, ids)
+
+	errs := []*FeedError{}
+	for err := range feed.Errors {
+		errs = append(errs, err)
+	}
+	assert.Len(t, errs, 2)
+	assert.Equal(t, FeedErrorMissingPackage, errs[0].Kind)
+	assert.Equal(t, "202001011201", errs[0].Package)
+	assert.False(t, errs[0].Fatal)
+	assert.Equal(t, FeedErrorDecode, errs[1].Kind)
+	assert.Equal(t, "202001011202", errs[1].Package)
+	assert.Equal(t, 2, errs[1].Line)
+	assert.True(t, errs[1].Fatal)
+
+	var feedErr *FeedError
+	assert.True(t, errors.As(feed.Error(), &feedErr))
+	assert.Equal(t, errs[1], feedErr)
+}
+
+func TestFeedAuthError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error": {"code": "WrongCredentialsError", "message": "wrong key"}}`))
+	}))
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+	feed, err := c.NewFeed(FileFeed, FeedCursor("202001011200"))
+	assert.NoError(t, err)
+	for range feed.C {
+	}
+	var feedErr *FeedError
+	assert.True(t, errors.As(feed.Error(), &feedErr))
+	assert.Equal(t, FeedErrorAuth, feedErr.Kind)
+	assert.True(t, feedErr.Fatal)
+	assert.EqualError(t, feedErr, "feed package 202001011200: wrong key")
+}
+
+func TestFeedPollInterval(t *testing.T) {
+	var requests int32
+	packages := newFeedTestServer(t, map[string]string{
+		"202001011200": "testdata/feed_package.bz2",
+	}, "")
+	defer packages.Close()
+	// The package is not available in the first request.
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error": {"code": "NotAvailableYet"}}`))
+			return
+		}
+		packages.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+	feed, err := c.NewFeed(FileFeed,
+		FeedCursor("202001011200"), FeedPollInterval(time.Millisecond))
+	assert.NoError(t, err)
+	defer feed.Stop()
+
+	select {
+	case obj := <-feed.C:
+		assert.Equal(t, "1", obj.ID())
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for the feed")
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+
+	_, err = c.NewFeed(FileFeed, FeedPollInterval(0))
+	assert.Error(t, err)
+}
+
+func TestFeedLatency(t *testing.T) {
+	var requests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+	}))
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+	now := time.Now().UTC().Format("200601021504")
+	feed, err := c.NewFeed(FileFeed, FeedCursor(now), FeedLatency(time.Hour))
+	assert.NoError(t, err)
+	time.Sleep(20 * time.Millisecond)
+	feed.Stop()
+	for range feed.C {
+	}
+	assert.Equal(t, int32(0), atomic.LoadInt32(&requests))
+	assert.NoError(t, feed.Error())
+}
+
+func TestFeedPersistCursor(t *testing.T) {
+	ts := newFeedTestServer(t, map[string]string{
+		"202001011200": "testdata/feed_package.bz2",
+	}, "")
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+	store := NewFileCursorStore(filepath.Join(t.TempDir(), "cursor"))
+	assert.NoError(t, store.Save("202001011200"))
+
+	feed, err := c.NewFeed(FileFeed, FeedPersistCursor(store))
+	assert.NoError(t, err)
+	n := 0
+	for range feed.C {
+		n++
+	}
+	assert.Equal(t, 2, n)
+	assert.Error(t, feed.Error())
+
+	// The feed stopped after two missing packages.
+	cursor, err := store.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, "202001011202-0", cursor)
+
+	// An explicit cursor has precedence over the stored one.
+	feed, err = c.NewFeed(FileFeed, FeedPersistCursor(store), FeedCursor("202001011200-1"))
+	assert.NoError(t, err)
+	ids := []string{}
+	for obj := range feed.C {
+		ids = append(ids, obj.ID())
+	}
+	assert.Equal(t, []string{"2"}, ids)
+}
+
+func TestFeedPackages(t *testing.T) {
+	ts := newFeedTestServer(t, map[string]string{
+		"202001011200": "testdata/feed_package.bz2",
+		"202001011201": "testdata/feed_package.bz2",
+	}, "")
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+	feed, err := c.NewFeed(FileFeed, FeedCursor("202001011200-1"), FeedPackages(true))
+	assert.NoError(t, err)
+	packages := []*FeedPackage{}
+	for p := range feed.Packages {
+		packages = append(packages, p)
+	}
+	_, ok := <-feed.C
+	assert.False(t, ok)
+	assert.Len(t, packages, 2)
+	assert.Equal(t, "202001011200", packages[0].Name)
+	assert.Len(t, packages[0].Objects, 1)
+	assert.Equal(t, int64(1), packages[0].Offset)
+	assert.Equal(t, "2", packages[0].Objects[0].ID())
+	assert.Equal(t, "202001011201", packages[1].Name)
+	assert.Len(t, packages[1].Objects, 2)
+}
+
+func TestGetFeedPackage(t *testing.T) {
+	ts := newFeedTestServer(t, map[string]string{
+		"202001011200": "testdata/feed_package.bz2",
+	}, "")
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+	p, err := c.GetFeedPackage(FileFeed, time.Date(2020, 1, 1, 12, 0, 30, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, "202001011200", p.Name)
+	assert.Len(t, p.Objects, 2)
+
+	r, err := c.OpenFeedPackage(FileFeed, time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	b, err := ioutil.ReadAll(r)
+	r.Close()
+	assert.NoError(t, err)
+	expected, _ := ioutil.ReadFile("testdata/feed_package.bz2")
+	assert.Equal(t, expected, b)
+
+	_, err = c.GetFeedPackage(FileFeed, time.Date(2020, 1, 1, 12, 1, 0, 0, time.UTC))
+	assert.Error(t, err)
+}
+
+func TestGetHourlyFeedPackage(t *testing.T) {
+	ts := newFeedTestServer(t, map[string]string{
+		"2020010112": "testdata/feed_package.bz2",
+	}, "")
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+	p, err := c.GetHourlyFeedPackage(URLFeed, time.Date(2020, 1, 1, 12, 30, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, "2020010112", p.Name)
+	assert.Equal(t, time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC), p.Time)
+	assert.Len(t, p.Objects, 2)
+}
+
+func TestFeedStats(t *testing.T) {
+	ts := newFeedTestServer(t, map[string]string{
+		"202001011200": "testdata/feed_package.bz2",
+	}, "202001011202")
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+	feed, err := c.NewFeed(FileFeed,
+		FeedCursor("202001011200"), FeedPollInterval(time.Millisecond))
+	assert.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		<-feed.C
+	}
+	// Package 202001011201 is missing and 202001011202 is never available.
+	assert.Eventually(t, func() bool {
+		return feed.Stats().Retries > 0
+	}, time.Second, time.Millisecond)
+	feed.Stop()
+
+	stats := feed.Stats()
+	assert.Equal(t, int64(2), stats.Items)
+	assert.Equal(t, int64(1), stats.PackagesMissed)
+	assert.True(t, stats.ItemsPerSecond > 0)
+	assert.True(t, stats.Lag > 24*time.Hour)
+}
+
+func TestFeedConcurrency(t *testing.T) {
+	var inFlight, maxInFlight int32
+	packages := newFeedTestServer(t, map[string]string{
+		"202001011200": "testdata/feed_package.bz2",
+		"202001011201": "testdata/feed_package.bz2",
+		"202001011202": "testdata/feed_package.bz2",
+		"202001011203": "testdata/feed_package.bz2",
+	}, "")
+	defer packages.Close()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			m := atomic.LoadInt32(&maxInFlight)
+			if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		packages.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+	feed, err := c.NewFeed(FileFeed, FeedCursor("202001011200-1"), FeedConcurrency(4))
+	assert.NoError(t, err)
+	ids := []string{}
+	for obj := range feed.C {
+		ids = append(ids, obj.ID())
+	}
+	assert.Equal(t, []string{"2", "1", "2", "1", "2", "1", "2"}, ids)
+	assert.True(t, atomic.LoadInt32(&maxInFlight) > 1)
+
+	_, err = c.NewFeed(FileFeed, FeedConcurrency(0))
+	assert.Error(t, err)
+}
+
+func TestFeedMissingPackages(t *testing.T) {
+	var requests int32
+	packages := newFeedTestServer(t, map[string]string{
+		"202001011200": "testdata/feed_package.bz2",
+		"202001011202": "testdata/feed_package.bz2",
+	}, "")
+	defer packages.Close()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/202001011201") {
+			atomic.AddInt32(&requests, 1)
+		}
+		packages.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+	feed, err := c.NewFeed(FileFeed, FeedCursor("202001011200"),
+		FeedMissingPackages(FeedMissingPackagePolicy{
+			Retries:    2,
+			RetryDelay: time.Millisecond,
+			Tolerance:  0,
+			Silent:     true,
+		}))
+	assert.NoError(t, err)
+	n := 0
+	for range feed.C {
+		n++
+	}
+	assert.Equal(t, 2, n)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+	// The only error reported is the one that stopped the feed.
+	errs := []*FeedError{}
+	for err := range feed.Errors {
+		errs = append(errs, err)
+	}
+	assert.Len(t, errs, 1)
+	assert.True(t, errs[0].Fatal)
+	assert.Equal(t, int64(2), feed.Stats().Retries)
+
+	_, err = c.NewFeed(FileFeed,
+		FeedMissingPackages(FeedMissingPackagePolicy{Tolerance: -1}))
+	assert.Error(t, err)
+}
+
+func TestDownloadFeedItem(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v3/feeds/files/token1/download" {
+			w.Write([]byte("file data"))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"error": {"code": "ForbiddenError"}}`))
+	}))
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+	item := func(token string) *Object {
+		obj, err := parseFeedLine(FileFeed, []byte(`{
+			"type": "file", "id": "1", "attributes": {},
+			"context_attributes": {
+				"download_url": "`+ts.URL+`/api/v3/feeds/files/`+token+`/download"
+			}}`))
+		assert.NoError(t, err)
+		return obj
+	}
+
+	var buf bytes.Buffer
+	n, err := c.DownloadFeedItem(item("token1"), &buf)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(9), n)
+	assert.Equal(t, "file data", buf.String())
+
+	_, err = c.DownloadFeedItem(item("expired"), &buf)
+	assert.Equal(t, ErrDownloadExpired, err)
+
+	_, err = c.DownloadFeedItem(NewObject("file"), &buf)
+	assert.Error(t, err)
+}
+
+func TestFeedSeekRestart(t *testing.T) {
+	ts := newFeedTestServer(t, map[string]string{
+		"202001011200": "testdata/feed_package.bz2",
+	}, "")
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+	feed, err := c.NewFeed(FileFeed, FeedCursor("202001011200"))
+	assert.NoError(t, err)
+	assert.Error(t, feed.Restart())
+	n := 0
+	for range feed.C {
+		n++
+	}
+	<-feed.Done()
+	assert.Equal(t, 2, n)
+	assert.Error(t, feed.Error())
+
+	// Rewind to the second object and restart.
+	assert.NoError(t, feed.Seek("202001011200-1"))
+	assert.Equal(t, "202001011200-1", feed.Cursor())
+	assert.NoError(t, feed.Restart())
+	ids := []string{}
+	for obj := range feed.C {
+		ids = append(ids, obj.ID())
+	}
+	assert.Equal(t, []string{"2"}, ids)
+	<-feed.Done()
+
+	assert.NoError(t, feed.SeekTime(time.Date(2020, 1, 1, 12, 0, 30, 0, time.UTC)))
+	assert.Equal(t, "202001011200-0", feed.Cursor())
+	assert.Error(t, feed.Seek("bad"))
+}
+
+func TestFeedItems(t *testing.T) {
+	ts := newFeedTestServer(t, map[string]string{
+		"202001011200": "testdata/feed_package.bz2",
+	}, "")
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+	feed, err := c.NewFeed(FileFeed, FeedCursor("202001011200"), FeedItems(true))
+	assert.NoError(t, err)
+	items := []*FeedItem{}
+	for item := range feed.Items {
+		items = append(items, item)
+	}
+	_, ok := <-feed.C
+	assert.False(t, ok)
+	assert.Len(t, items, 2)
+	assert.Equal(t, "2", items[1].Object.ID())
+	assert.Equal(t, "202001011200-1", items[1].Cursor())
+	// The raw line decodes to the same object.
+	obj := &Object{}
+	assert.NoError(t, json.Unmarshal(items[1].Raw, obj))
+	assert.Equal(t, "2", obj.ID())
+
+	_, err = c.NewFeed(FileFeed, FeedItems(true), FeedPackages(true))
+	assert.Error(t, err)
+}
+
+func TestFeedOverflowDropOldest(t *testing.T) {
+	ts := newFeedTestServer(t, map[string]string{
+		"202001011200": "testdata/feed_package.bz2",
+		"202001011201": "testdata/feed_package.bz2",
+	}, "")
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+	feed, err := c.NewFeed(FileFeed, FeedCursor("202001011200"),
+		FeedBufferSize(1), FeedOverflow(FeedOverflowDropOldest))
+	assert.NoError(t, err)
+	// Nothing is read from C until the feed finishes, so only the last object
+	// remains.
+	<-feed.Done()
+	ids := []string{}
+	for obj := range feed.C {
+		ids = append(ids, obj.ID())
+	}
+	assert.Equal(t, []string{"2"}, ids)
+	assert.Equal(t, int64(3), feed.Stats().Dropped)
+
+	_, err = c.NewFeed(FileFeed, FeedOverflow(FeedOverflowPolicy(10)))
+	assert.Error(t, err)
+}
+
+func TestFeedOverflowSpill(t *testing.T) {
+	ts := newFeedTestServer(t, map[string]string{
+		"202001011200": "testdata/feed_package.bz2",
+		"202001011201": "testdata/feed_package.bz2",
+	}, "")
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+	dir := t.TempDir()
+	feed, err := c.NewFeed(FileFeed, FeedCursor("202001011200"),
+		FeedBufferSize(1), FeedOverflow(FeedOverflowSpill), FeedSpillDir(dir))
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return feed.Stats().Spilled == 3
+	}, time.Second, time.Millisecond)
+	ids := []string{}
+	for obj := range feed.C {
+		ids = append(ids, obj.ID())
+	}
+	assert.Equal(t, []string{"1", "2", "1", "2"}, ids)
+	<-feed.Done()
+
+	// The spill file is removed when the feed finishes.
+	files, err := ioutil.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestFeedFilter(t *testing.T) {
+	ts := newFeedTestServer(t, map[string]string{
+		"202001011200": "testdata/feed_package.bz2",
+	}, "")
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+	feed, err := c.NewFeed(FileFeed, FeedCursor("202001011200"),
+		FeedFilter(func(obj *Object) bool { return obj.ID() == "2" }))
+	assert.NoError(t, err)
+	ids := []string{}
+	for obj := range feed.C {
+		ids = append(ids, obj.ID())
+	}
+	assert.Equal(t, []string{"2"}, ids)
+	stats := feed.Stats()
+	assert.Equal(t, int64(1), stats.Items)
+	assert.Equal(t, int64(1), stats.Filtered)
+}
+
+// testClock is a Clock whose timers fire only when the test says so.
+type testClock struct {
+	now    time.Time
+	timers chan time.Duration
+	fire   chan time.Time
+}
+
+func newTestClock(now time.Time) *testClock {
+	return &testClock{now: now, timers: make(chan time.Duration, 10), fire: make(chan time.Time)}
+}
+
+func (c *testClock) Now() time.Time { return c.now }
+
+func (c *testClock) After(d time.Duration) <-chan time.Time {
+	c.timers <- d
+	return c.fire
+}
+
+func TestFeedClock(t *testing.T) {
+	var requests int32
+	packages := newFeedTestServer(t, map[string]string{
+		"202001011200": "testdata/feed_package.bz2",
+	}, "")
+	defer packages.Close()
+	// The package is not available in the first two requests.
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) <= 2 {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error": {"code": "NotAvailableYet"}}`))
+			return
+		}
+		packages.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+	clock := newTestClock(time.Date(2020, 1, 1, 13, 0, 0, 0, time.UTC))
+	feed, err := c.NewFeed(FileFeed, FeedClock(clock))
+	assert.NoError(t, err)
+
+	// The poll interval doubles after each attempt.
+	assert.Equal(t, 20*time.Second, <-clock.timers)
+	clock.fire <- clock.now
+	assert.Equal(t, 40*time.Second, <-clock.timers)
+	clock.fire <- clock.now
+
+	// The feed started one hour before the clock's time, and stops after
+	// two missing packages.
+	ids := []string{}
+	for obj := range feed.C {
+		ids = append(ids, obj.ID())
+	}
+	assert.Equal(t, []string{"1", "2"}, ids)
+	assert.Equal(t, "202001011202-0", feed.Cursor())
+	assert.Equal(t, 57*time.Minute, feed.Stats().Lag)
+}
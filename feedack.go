@@ -0,0 +1,145 @@
+// Copyright © 2017 The vt-go authors. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vt
+
+import (
+	"sync"
+	"time"
+)
+
+// FeedAck receives a boolean that indicates whether the feed's cursor must
+// advance only as items are acknowledged by the consumer with FeedItem.Ack.
+// This requires FeedItems. With this option the cursor saved in the store
+// specified with FeedPersistCursor, and the one returned by AckedCursor, point
+// to the oldest item not acknowledged yet, so that a feed resumed from that
+// cursor receives again all the items that were not completely processed,
+// even if the process crashed. This provides at-least-once delivery for
+// consumers that write items into a database, for example. Items can be
+// acknowledged in any order, for instance by several workers. Example:
+//
+//	feed, err := client.NewFeed(vt.FileFeed,
+//		vt.FeedItems(true),
+//		vt.FeedAck(true),
+//		vt.FeedPersistCursor(vt.NewFileCursorStore("cursor")))
+//	...
+//	for item := range feed.Items {
+//		if err := db.Insert(item.Object); err == nil {
+//			item.Ack()
+//		}
+//	}
+func FeedAck(b bool) FeedOption {
+	return func(f *Feed) error {
+		f.ack = b
+		return nil
+	}
+}
+
+// feedAcks tracks the items sent by a feed that have not been acknowledged.
+type feedAcks struct {
+	mu   sync.Mutex
+	feed *Feed
+	// Items sent by the feed, in order, the first one is always pending.
+	pending []*FeedItem
+	// Package of the last cursor saved by Ack.
+	saved time.Time
+}
+
+// push adds an item that is about to be sent.
+func (a *feedAcks) push(item *FeedItem) {
+	a.mu.Lock()
+	item.acks = a
+	a.pending = append(a.pending, item)
+	a.mu.Unlock()
+}
+
+// cursor returns the cursor of the oldest item not acknowledged, or the feed's
+// position if all items were acknowledged. It must be called with the lock
+// held.
+func (a *feedAcks) cursor() (time.Time, int64) {
+	if len(a.pending) > 0 {
+		return a.pending[0].PackageTime, a.pending[0].Index
+	}
+	a.feed.statsMu.Lock()
+	defer a.feed.statsMu.Unlock()
+	return a.feed.statsTime, a.feed.statsN
+}
+
+// ack marks an item as acknowledged, and saves the cursor if it moved to a
+// different package.
+func (a *feedAcks) ack(item *FeedItem) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if item.acked {
+		return
+	}
+	item.acked = true
+	i := 0
+	for i < len(a.pending) && a.pending[i].acked {
+		i++
+	}
+	// Release the references to the acknowledged items.
+	for j := 0; j < i; j++ {
+		a.pending[j] = nil
+	}
+	a.pending = a.pending[i:]
+	if t, _ := a.cursor(); !t.Equal(a.saved) {
+		a.saveLocked()
+	}
+}
+
+// save saves the cursor in the feed's store.
+func (a *feedAcks) save() {
+	a.mu.Lock()
+	a.saveLocked()
+	a.mu.Unlock()
+}
+
+func (a *feedAcks) saveLocked() {
+	store := a.feed.cursorStore
+	if store == nil {
+		return
+	}
+	t, n := a.cursor()
+	a.saved = t
+	if err := store.Save(feedCursor(t, n)); err != nil {
+		a.feed.reportError(&FeedError{
+			Kind:    FeedErrorCursorStore,
+			Package: t.Format("200601021504"),
+			Err:     err,
+		})
+	}
+}
+
+// Ack acknowledges that the item has been processed, which allows the cursor
+// of a feed created with FeedAck to advance past it. It's safe to call Ack
+// from multiple goroutines, and calling it more than once has no effect. For
+// feeds created without FeedAck it does nothing.
+func (i *FeedItem) Ack() {
+	if i.acks != nil {
+		i.acks.ack(i)
+	}
+}
+
+// AckedCursor returns the cursor of the oldest item not acknowledged yet, see
+// FeedAck. A feed resumed from this cursor receives again all the items not
+// acknowledged. For feeds created without FeedAck it's the same as Cursor.
+// Unlike Cursor, it's safe to call AckedCursor while the feed is running.
+func (f *Feed) AckedCursor() string {
+	if f.acks == nil {
+		return f.Cursor()
+	}
+	f.acks.mu.Lock()
+	defer f.acks.mu.Unlock()
+	return feedCursor(f.acks.cursor())
+}
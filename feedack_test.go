@@ -0,0 +1,52 @@
+package vt
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeedAck(t *testing.T) {
+	ts := newFeedTestServer(t, map[string]string{
+		"202001011200": "testdata/feed_package.bz2",
+		"202001011201": "testdata/feed_package.bz2",
+	}, "")
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+	store := NewFileCursorStore(filepath.Join(t.TempDir(), "cursor"))
+	feed, err := c.NewFeed(FileFeed, FeedCursor("202001011200"),
+		FeedItems(true), FeedAck(true), FeedPersistCursor(store))
+	assert.NoError(t, err)
+	items := []*FeedItem{}
+	for item := range feed.Items {
+		items = append(items, item)
+	}
+	assert.Len(t, items, 4)
+
+	// Nothing was acknowledged, the cursor stays at the first item.
+	cursor, err := store.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, "202001011200-0", cursor)
+	assert.Equal(t, "202001011200-0", feed.AckedCursor())
+
+	// Items acknowledged out of order.
+	items[1].Ack()
+	assert.Equal(t, "202001011200-0", feed.AckedCursor())
+	items[0].Ack()
+	items[0].Ack()
+	assert.Equal(t, "202001011201-0", feed.AckedCursor())
+	cursor, err = store.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, "202001011201-0", cursor)
+
+	items[2].Ack()
+	items[3].Ack()
+	// The feed stopped after two missing packages.
+	assert.Equal(t, "202001011203-0", feed.AckedCursor())
+
+	_, err = c.NewFeed(FileFeed, FeedAck(true))
+	assert.Error(t, err)
+}
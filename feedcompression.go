@@ -0,0 +1,113 @@
+// Copyright © 2017 The vt-go authors. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vt
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// FeedDecompressor returns a reader that decompresses the data read from r.
+type FeedDecompressor func(r io.Reader) (io.Reader, error)
+
+type feedDecompressor struct {
+	magic      []byte
+	decompress FeedDecompressor
+}
+
+// Decompressors for feed packages, the compression used by a package is
+// detected by the magic bytes at its start.
+var feedDecompressors = struct {
+	sync.RWMutex
+	list []feedDecompressor
+}{list: []feedDecompressor{
+	{[]byte("BZh"), func(r io.Reader) (io.Reader, error) {
+		return bzip2.NewReader(r), nil
+	}},
+	{[]byte{0x1f, 0x8b}, func(r io.Reader) (io.Reader, error) {
+		return gzip.NewReader(r)
+	}},
+}}
+
+// Magic bytes at the start of zstd-compressed data. zstd is recognized only
+// for reporting it as unsupported, there's no zstd decompressor in the
+// standard library, see RegisterFeedDecompressor.
+var zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+
+// ErrUnsupportedFeedCompression is returned when a feed package is compressed
+// with a format that has no registered decompressor, like zstd. The error
+// returned by the feed wraps ErrUnsupportedFeedCompression, use errors.Is for
+// checking it.
+var ErrUnsupportedFeedCompression = errors.New("unsupported feed package compression")
+
+// RegisterFeedDecompressor registers a decompressor for feed packages that
+// start with the given magic bytes. Only packages compressed with bzip2 or
+// gzip, and uncompressed packages, are supported out of the box. Packages
+// compressed with other formats, including zstd, fail with
+// ErrUnsupportedFeedCompression unless a decompressor is registered, which
+// avoids adding dependencies to this package. Example using
+// github.com/klauspost/compress/zstd:
+//
+//	vt.RegisterFeedDecompressor([]byte{0x28, 0xb5, 0x2f, 0xfd},
+//		func(r io.Reader) (io.Reader, error) {
+//			return zstd.NewReader(r)
+//		})
+//
+// Registering the same magic bytes again replaces the previous decompressor.
+func RegisterFeedDecompressor(magic []byte, decompress FeedDecompressor) {
+	feedDecompressors.Lock()
+	defer feedDecompressors.Unlock()
+	for i, d := range feedDecompressors.list {
+		if bytes.Equal(d.magic, magic) {
+			feedDecompressors.list[i].decompress = decompress
+			return
+		}
+	}
+	feedDecompressors.list = append(feedDecompressors.list,
+		feedDecompressor{append([]byte(nil), magic...), decompress})
+}
+
+// decompressFeedPackage returns a reader for the content of a feed package,
+// detecting its compression format.
+func decompressFeedPackage(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	head, err := br.Peek(8)
+	if len(head) == 0 {
+		if err == io.EOF {
+			return br, nil
+		}
+		return nil, err
+	}
+	feedDecompressors.RLock()
+	defer feedDecompressors.RUnlock()
+	for _, d := range feedDecompressors.list {
+		if bytes.HasPrefix(head, d.magic) {
+			return d.decompress(br)
+		}
+	}
+	// Uncompressed packages start with a JSON object.
+	if trimmed := bytes.TrimLeft(head, " \t\r\n"); len(trimmed) == 0 || trimmed[0] == '{' {
+		return br, nil
+	}
+	if bytes.HasPrefix(head, zstdMagic) {
+		return nil, fmt.Errorf("%w: zstd, see RegisterFeedDecompressor", ErrUnsupportedFeedCompression)
+	}
+	return nil, fmt.Errorf("%w: unknown format", ErrUnsupportedFeedCompression)
+}
@@ -0,0 +1,70 @@
+package vt
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testFeedLines = `{"type": "file", "id": "1", "attributes": {}}
+{"type": "file", "id": "2", "attributes": {}}
+`
+
+func decodeTestPackage(t *testing.T, data []byte) ([]*Object, error) {
+	objects, _, err := decodeFeedPackage(FileFeed, "202001011200", bytes.NewReader(data), nil, false)
+	return objects, err
+}
+
+func TestFeedPackageCompression(t *testing.T) {
+	bz2, err := ioutil.ReadFile("testdata/feed_package.bz2")
+	assert.NoError(t, err)
+	objects, err := decodeTestPackage(t, bz2)
+	assert.NoError(t, err)
+	assert.Len(t, objects, 2)
+
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	w.Write([]byte(testFeedLines))
+	w.Close()
+	objects, err = decodeTestPackage(t, gz.Bytes())
+	assert.NoError(t, err)
+	assert.Len(t, objects, 2)
+
+	objects, err = decodeTestPackage(t, []byte(testFeedLines))
+	assert.NoError(t, err)
+	assert.Len(t, objects, 2)
+
+	objects, err = decodeTestPackage(t, nil)
+	assert.NoError(t, err)
+	assert.Len(t, objects, 0)
+
+	// zstd is not supported out of the box.
+	_, err = decodeTestPackage(t, append(zstdMagic, 0, 0, 0, 0))
+	assert.True(t, errors.Is(err, ErrUnsupportedFeedCompression))
+	assert.Contains(t, err.Error(), "zstd")
+	_, err = decodeTestPackage(t, []byte("garbage"))
+	assert.True(t, errors.Is(err, ErrUnsupportedFeedCompression))
+}
+
+func TestRegisterFeedDecompressor(t *testing.T) {
+	magic := []byte("TEST")
+	RegisterFeedDecompressor(magic, func(r io.Reader) (io.Reader, error) {
+		if _, err := io.ReadFull(r, make([]byte, len(magic))); err != nil {
+			return nil, err
+		}
+		return r, nil
+	})
+	defer func() {
+		feedDecompressors.Lock()
+		feedDecompressors.list = feedDecompressors.list[:len(feedDecompressors.list)-1]
+		feedDecompressors.Unlock()
+	}()
+	objects, err := decodeTestPackage(t, append(magic, testFeedLines...))
+	assert.NoError(t, err)
+	assert.Len(t, objects, 2)
+}
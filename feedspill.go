@@ -0,0 +1,158 @@
+// Copyright © 2017 The vt-go authors. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vt
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+)
+
+// feedSpill is a queue of objects stored in a file, it's used by feeds with
+// the FeedOverflowSpill policy for holding the objects that don't fit in the
+// feed's channel. A goroutine moves the objects from the file to the channel
+// in the same order they were pushed.
+type feedSpill struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+	dir  string
+	c    chan<- *Object
+	// Objects are appended to w and read from r, both refer to the same file,
+	// which is created on the first push.
+	w *os.File
+	r *bufio.Reader
+	f *os.File
+	// Number of objects in the file not sent to the channel yet.
+	pending  int
+	finished bool
+	done     chan struct{}
+	onError  func(error)
+}
+
+func newFeedSpill(dir string, c chan<- *Object, onError func(error)) *feedSpill {
+	s := &feedSpill{dir: dir, c: c, done: make(chan struct{}), onError: onError}
+	s.cond = sync.NewCond(&s.mu)
+	go s.run()
+	return s
+}
+
+// empty returns true if there are no objects waiting in the file.
+func (s *feedSpill) empty() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pending == 0
+}
+
+// push appends an object to the queue.
+func (s *feedSpill) push(obj *Object) error {
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.w == nil {
+		if s.w, err = ioutil.TempFile(s.dir, "vt-feed-spill-"); err != nil {
+			return err
+		}
+		if s.f, err = os.Open(s.w.Name()); err != nil {
+			return err
+		}
+		s.r = bufio.NewReader(s.f)
+	}
+	if _, err := s.w.Write(append(b, '\n')); err != nil {
+		return err
+	}
+	s.pending++
+	s.cond.Signal()
+	return nil
+}
+
+// run sends the objects in the queue to the channel until finish is called
+// and the queue is empty.
+func (s *feedSpill) run() {
+	defer close(s.done)
+	for {
+		s.mu.Lock()
+		for s.pending == 0 && !s.finished {
+			s.cond.Wait()
+		}
+		if s.pending == 0 {
+			s.mu.Unlock()
+			s.remove()
+			return
+		}
+		s.mu.Unlock()
+		// Lines are read without holding the lock, as they are written
+		// completely before pending is incremented.
+		line, err := s.r.ReadBytes('\n')
+		if err == nil {
+			obj := &Object{}
+			if err = json.Unmarshal(line, obj); err == nil {
+				s.c <- obj
+			}
+		}
+		if err != nil {
+			s.onError(err)
+		}
+		s.mu.Lock()
+		s.pending--
+		// Once all the objects have been sent the file is emptied, so that it
+		// doesn't grow indefinitely.
+		if s.pending == 0 {
+			if err := s.reset(); err != nil {
+				s.onError(err)
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+// reset truncates the file, it must be called with the lock held.
+func (s *feedSpill) reset() error {
+	if err := s.w.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := s.w.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := s.f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	s.r.Reset(s.f)
+	return nil
+}
+
+// remove closes and deletes the file, if it was created.
+func (s *feedSpill) remove() {
+	if s.w == nil {
+		return
+	}
+	s.f.Close()
+	s.w.Close()
+	os.Remove(s.w.Name())
+}
+
+// finish waits until all the objects in the queue have been sent to the
+// channel, and stops the goroutine.
+func (s *feedSpill) finish() {
+	s.mu.Lock()
+	s.finished = true
+	s.cond.Signal()
+	s.mu.Unlock()
+	<-s.done
+}
@@ -15,12 +15,19 @@ package vt
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"mime/multipart"
 	"net/url"
 	"os"
+	"path/filepath"
+	"time"
 )
 
 type progressReader struct {
@@ -28,40 +35,195 @@ type progressReader struct {
 	total      int64
 	read       int64
 	progressCh chan<- float32
+	onProgress func(pct float32)
 }
 
 func (pr *progressReader) Read(p []byte) (int, error) {
 	n, err := pr.reader.Read(p)
+	if n == 0 {
+		return n, err
+	}
 	pr.read += int64(n)
+	pct := float32(100)
+	if pr.total > 0 {
+		pct = float32(pr.read) / float32(pr.total) * 100
+	}
+	if pr.onProgress != nil {
+		pr.onProgress(pct)
+	}
 	if pr.progressCh != nil {
-		pr.progressCh <- float32(pr.read) / float32(pr.total) * 100
+		// Sending doesn't block, so that a consumer that is not reading from
+		// the channel doesn't stall the upload. Updates that can't be sent
+		// are skipped, as the next one supersedes them.
+		select {
+		case pr.progressCh <- pct:
+		default:
+		}
 	}
 	return n, err
 }
 
+// ErrFileTooLarge is returned when trying to upload a file larger than the
+// maximum file size, see WithMaxFileSize. The error returned by FileScanner
+// wraps ErrFileTooLarge, use errors.Is for checking it.
+var ErrFileTooLarge = errors.New("file too large")
+
+func fileTooLarge(limit int64) error {
+	return fmt.Errorf("%w, the maximum size is %d bytes", ErrFileTooLarge, limit)
+}
+
 // FileScanner represents a file scanner.
 type FileScanner struct {
 	cli *Client
+	// Function that returns the name sent to VirusTotal for files scanned
+	// with ScanFile and ScanFileWithParameters.
+	submissionName func(path string) string
+	// Function called with the percentage of the file uploaded so far.
+	onProgress func(pct float32)
+	// Known files analysed before this time ago are uploaded again by
+	// ScanOrLookup, see FileScannerMaxAge.
+	maxAge time.Duration
+	// Files larger than this are written to a temporary file in spillDir
+	// while uploaded, instead of kept in memory, see FileScannerSpillThreshold.
+	spillThreshold int64
+	spillDir       string
+}
+
+// FileScannerOption represents an option passed to NewFileScannerWithOptions.
+type FileScannerOption func(*FileScanner)
+
+// FileScannerSubmissionName specifies a function that receives the local path
+// of files scanned with ScanFile and ScanFileWithParameters, and returns the
+// file name sent to VirusTotal. By default only the file's base name is sent,
+// so that the local directory structure is not disclosed.
+func FileScannerSubmissionName(f func(path string) string) FileScannerOption {
+	return func(s *FileScanner) {
+		s.submissionName = f
+	}
+}
+
+// FileScannerOnProgress specifies a function that is called with the
+// percentage of the file that has been uploaded so far, as an alternative to
+// the progress channel accepted by the scanning methods. The function is
+// called from the goroutine that uploads the file, so it must return quickly.
+func FileScannerOnProgress(f func(pct float32)) FileScannerOption {
+	return func(s *FileScanner) {
+		s.onProgress = f
+	}
+}
+
+// FileScannerMaxAge specifies the maximum age of the last analysis of a file
+// already known by VirusTotal for ScanOrLookup and ScanFileOrLookup to return
+// it instead of uploading the file again. By default known files are never
+// uploaded again.
+func FileScannerMaxAge(d time.Duration) FileScannerOption {
+	return func(s *FileScanner) {
+		s.maxAge = d
+	}
+}
+
+// FileScannerSpillThreshold specifies a size above which the content being
+// uploaded is written to a temporary file instead of being kept in memory.
+// The content must be kept until the upload finishes, as it's needed for
+// computing its size before sending it, which for large files or many
+// parallel uploads can use a lot of memory. The temporary file is
+// removed after the upload. By default everything is kept in memory. This
+// doesn't apply to ScanOrLookup, which needs the content in memory.
+func FileScannerSpillThreshold(n int64) FileScannerOption {
+	return func(s *FileScanner) {
+		s.spillThreshold = n
+	}
+}
+
+// FileScannerSpillDir specifies the directory where temporary files are
+// created when the content being uploaded exceeds the threshold specified with
+// FileScannerSpillThreshold. By default the system's temporary directory is
+// used.
+func FileScannerSpillDir(dir string) FileScannerOption {
+	return func(s *FileScanner) {
+		s.spillDir = dir
+	}
+}
+
+// uploadBuffer keeps the body of an upload, in memory until its size
+// exceeds the threshold, and in a temporary file afterwards.
+type uploadBuffer struct {
+	threshold int64
+	dir       string
+	mem       bytes.Buffer
+	file      *os.File
+	size      int64
+}
+
+func (b *uploadBuffer) Write(p []byte) (int, error) {
+	if b.file == nil && b.threshold > 0 && b.size+int64(len(p)) > b.threshold {
+		f, err := ioutil.TempFile(b.dir, "vt-upload-")
+		if err != nil {
+			return 0, err
+		}
+		b.file = f
+		if _, err := b.file.Write(b.mem.Bytes()); err != nil {
+			return 0, err
+		}
+		b.mem = bytes.Buffer{}
+	}
+	var n int
+	var err error
+	if b.file != nil {
+		n, err = b.file.Write(p)
+	} else {
+		n, err = b.mem.Write(p)
+	}
+	b.size += int64(n)
+	return n, err
+}
+
+// body returns the content written to the buffer.
+func (b *uploadBuffer) body() io.ReaderAt {
+	if b.file != nil {
+		return b.file
+	}
+	return bytes.NewReader(b.mem.Bytes())
+}
+
+// close removes the temporary file, if any.
+func (b *uploadBuffer) close() {
+	if b.file != nil {
+		b.file.Close()
+		os.Remove(b.file.Name())
+	}
+}
+
+// name returns the name sent to VirusTotal for a file with the given path.
+func (s *FileScanner) name(path string) string {
+	if s.submissionName != nil {
+		return s.submissionName(path)
+	}
+	return filepath.Base(path)
 }
 
 func (s *FileScanner) scanWithParameters(
 	r io.Reader, filename string, progress chan<- float32, parameters map[string]string) (*Object, error) {
-	var uploadURL *url.URL
 	var payloadSize int64
 
-	b := bytes.Buffer{}
+	b := &uploadBuffer{threshold: s.spillThreshold, dir: s.spillDir}
+	defer b.close()
 
 	// Create multipart writer for the file
-	w := multipart.NewWriter(&b)
+	w := multipart.NewWriter(b)
 	f, err := w.CreateFormFile("file", filename)
 	if err != nil {
 		return nil, err
 	}
 
-	// Copy data from input stream to the multiparted file
-	if payloadSize, err = io.Copy(f, r); err != nil {
+	// Copy data from input stream to the multiparted file, reading one byte
+	// more than the limit is enough for knowing if the file is too large.
+	if payloadSize, err = io.Copy(f, io.LimitReader(r, s.cli.maxFileSize+1)); err != nil {
 		return nil, err
 	}
+	if payloadSize > s.cli.maxFileSize {
+		return nil, fileTooLarge(s.cli.maxFileSize)
+	}
 
 	if parameters != nil {
 		for key, val := range parameters {
@@ -71,30 +233,60 @@ func (s *FileScanner) scanWithParameters(
 		}
 	}
 
-	w.Close()
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	headers := map[string]string{"Content-Type": w.FormDataContentType()}
 
-	if payloadSize > maxFileSize {
-		return nil, fmt.Errorf("file size can't be larger than %d bytes", maxFileSize)
-	} else if payloadSize > maxPayloadSize {
-		// Payload is bigger than supported by AppEngine in a POST request,
-		// let's ask for an upload URL.
-		var u string
-		if _, err := s.cli.GetData(URL("files/upload_url"), &u); err != nil {
-			return nil, err
-		}
-		if uploadURL, err = url.Parse(u); err != nil {
-			return nil, err
-		}
-	} else {
-		uploadURL = URL("files")
+	analysis, err := s.upload(b.body(), b.size, payloadSize, headers, progress)
+	if err != nil {
+		return nil, err
 	}
 
-	pr := &progressReader{
-		reader:     &b,
-		total:      int64(b.Len()),
-		progressCh: progress}
+	if analysis.data.ContextAttributes == nil {
+		analysis.data.ContextAttributes = make(map[string]interface{})
+	}
+	analysis.data.ContextAttributes["filename"] = filename
 
-	headers := map[string]string{"Content-Type": w.FormDataContentType()}
+	return analysis, nil
+}
+
+// upload sends the multipart body of a file upload.
+func (s *FileScanner) upload(
+	body io.ReaderAt, size, payloadSize int64, headers map[string]string, progress chan<- float32) (*Object, error) {
+	if payloadSize <= s.cli.maxPayloadSize {
+		return s.send(URL("files"), body, size, headers, progress)
+	}
+	// Payload is bigger than supported by AppEngine in a POST request,
+	// let's ask for an upload URL, or reuse a previous one.
+	uploadURL, reused, err := s.cli.getUploadURL()
+	if err != nil {
+		return nil, err
+	}
+	analysis, err := s.send(uploadURL, body, size, headers, progress)
+	if err == nil {
+		return analysis, nil
+	}
+	s.cli.expireUploadURL(uploadURL)
+	if !reused {
+		return nil, err
+	}
+	// The reused URL may not be valid anymore, try again with a new one.
+	if uploadURL, _, err = s.cli.getUploadURL(); err != nil {
+		return nil, err
+	}
+	return s.send(uploadURL, body, size, headers, progress)
+}
+
+// send posts the multipart body of a file upload to the given URL.
+func (s *FileScanner) send(
+	uploadURL *url.URL, body io.ReaderAt, size int64, headers map[string]string, progress chan<- float32) (*Object, error) {
+	pr := &progressReader{
+		reader:     io.NewSectionReader(body, 0, size),
+		total:      size,
+		progressCh: progress,
+		onProgress: s.onProgress}
 
 	httpResp, err := s.cli.sendRequest("POST", uploadURL, pr, headers)
 	if err != nil {
@@ -115,14 +307,51 @@ func (s *FileScanner) scanWithParameters(
 	return analysis, nil
 }
 
+// getUploadURL returns a URL for uploading a large file, the URL obtained
+// from /files/upload_url is reused until it expires. The returned bool is
+// true if the URL was reused.
+func (cli *Client) getUploadURL() (*url.URL, bool, error) {
+	cli.uploadURLMu.Lock()
+	defer cli.uploadURLMu.Unlock()
+	if cli.uploadURL != nil && time.Now().Before(cli.uploadURLExpires) {
+		return cli.uploadURL, true, nil
+	}
+	var u string
+	if _, err := cli.GetData(URL("files/upload_url"), &u); err != nil {
+		return nil, false, err
+	}
+	uploadURL, err := url.Parse(u)
+	if err != nil {
+		return nil, false, err
+	}
+	if cli.uploadURLValidity > 0 {
+		cli.uploadURL = uploadURL
+		cli.uploadURLExpires = time.Now().Add(cli.uploadURLValidity)
+	}
+	return uploadURL, false, nil
+}
+
+// expireUploadURL stops reusing the given upload URL, if it's still the one
+// being reused.
+func (cli *Client) expireUploadURL(u *url.URL) {
+	cli.uploadURLMu.Lock()
+	if cli.uploadURL == u {
+		cli.uploadURL = nil
+	}
+	cli.uploadURLMu.Unlock()
+}
+
 // ScanParameters sends a file to VirusTotal for scanning. The file content is
 // read from the r io.Reader and sent to VirusTotal with the provided file name
 // which can be left blank. The function also sends a float32 through the
 // progress channel indicating the percentage of the file that has been already
-// uploaded. The progress channel can be nil if the caller is not interested in
+// uploaded, updates are skipped when the channel is not ready to receive them.
+// The progress channel can be nil if the caller is not interested in
 // receiving upload progress updates. An analysis object is returned as soon as
 // the file is uploaded. Additional parameters can be passed to the scan
-// by using the parameters map[string]string argument.
+// by using the parameters map[string]string argument. The file name actually
+// submitted is available in the "filename" context attribute of the returned
+// analysis.
 func (s *FileScanner) ScanParameters(
 	r io.Reader, filename string, progress chan<- float32, parameters map[string]string) (*Object, error) {
 	return s.scanWithParameters(r, filename, progress, parameters)
@@ -130,25 +359,108 @@ func (s *FileScanner) ScanParameters(
 
 // ScanFileWithParameters sends a file to VirusTotal for scanning. This function
 // is similar to ScanWithParameters but it receives an *os.File instead of a
-// io.Reader and a file name.
+// io.Reader and a file name. The file name sent to VirusTotal is the file's
+// base name, unless otherwise specified with FileScannerSubmissionName.
 func (s *FileScanner) ScanFileWithParameters(
 	f *os.File, progress chan<- float32, parameters map[string]string) (*Object, error) {
-	return s.scanWithParameters(f, f.Name(), progress, parameters)
+	return s.scanWithParameters(f, s.name(f.Name()), progress, parameters)
 }
 
 // Scan sends a file to VirusTotal for scanning. The file content is read from
 // the r io.Reader and sent to VirusTotal with the provided file name which can
 // be left blank. The function also sends a float32 through the progress channel
-// indicating the percentage of the file that has been already uploaded. The
-// progress channel can be nil if the caller is not interested in receiving
-// upload progress updates. An analysis object is returned as soon as the file
-// is uploaded.
+// indicating the percentage of the file that has been already uploaded,
+// updates are skipped when the channel is not ready to receive them, see also
+// FileScannerOnProgress. The progress channel can be nil if the caller is not
+// interested in receiving upload progress updates. An analysis object is
+// returned as soon as the file is uploaded. The file name actually submitted is
+// available in the "filename" context attribute of the returned analysis.
 func (s *FileScanner) Scan(r io.Reader, filename string, progress chan<- float32) (*Object, error) {
 	return s.scanWithParameters(r, filename, progress, nil)
 }
 
 // ScanFile sends a file to VirusTotal for scanning. This function is similar to
-// Scan but it receive an *os.File instead of a io.Reader and a file name.
+// Scan but it receive an *os.File instead of a io.Reader and a file name. The
+// file name sent to VirusTotal is the file's base name, unless otherwise
+// specified with FileScannerSubmissionName.
 func (s *FileScanner) ScanFile(f *os.File, progress chan<- float32) (*Object, error) {
-	return s.Scan(f, f.Name(), progress)
+	return s.Scan(f, s.name(f.Name()), progress)
+}
+
+// ScanOrLookup is like Scan, but the file is uploaded only if VirusTotal
+// doesn't know it already, which saves bandwidth and quota when submitting
+// files that are probably known. The SHA-256 of the file is computed locally
+// and the file's report is retrieved, if the file exists its object is
+// returned, with type "file", and the returned bool is false. Otherwise the
+// file is uploaded, the returned object is the analysis like in Scan, and the
+// returned bool is true. Known files whose last analysis is older than the
+// age specified with FileScannerMaxAge are uploaded too. The content of r is
+// kept in memory, as it's needed for both computing the hash and uploading.
+func (s *FileScanner) ScanOrLookup(r io.Reader, filename string, progress chan<- float32) (*Object, bool, error) {
+	content, err := ioutil.ReadAll(io.LimitReader(r, s.cli.maxFileSize+1))
+	if err != nil {
+		return nil, false, err
+	}
+	if int64(len(content)) > s.cli.maxFileSize {
+		return nil, false, fileTooLarge(s.cli.maxFileSize)
+	}
+	sum := sha256.Sum256(content)
+	file, err := s.cli.GetObject(URL("files/%s", hex.EncodeToString(sum[:])))
+	var apiErr Error
+	if err != nil && !(errors.As(err, &apiErr) && apiErr.Code == "NotFoundError") {
+		return nil, false, err
+	}
+	if err == nil && !s.outdated(file) {
+		return file, false, nil
+	}
+	analysis, err := s.scanWithParameters(bytes.NewReader(content), filename, progress, nil)
+	return analysis, err == nil, err
+}
+
+// ScanFileOrLookup is like ScanOrLookup, but it receives an *os.File instead
+// of a io.Reader and a file name, like ScanFile.
+func (s *FileScanner) ScanFileOrLookup(f *os.File, progress chan<- float32) (*Object, bool, error) {
+	return s.ScanOrLookup(f, s.name(f.Name()), progress)
+}
+
+// ScanAndWait uploads a file like Scan, waits until the analysis is completed
+// and returns the file object with the results. The file object is retrieved
+// by its SHA-256, which is computed while the file is uploaded. The analysis
+// is polled as described in Analysis.WaitForCompletion, the polling can be
+// tweaked with the given options, and it stops with an error when ctx is
+// cancelled. Example:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+//	defer cancel()
+//	file, err := scanner.ScanAndWait(ctx, r, "sample.exe")
+//	if err != nil {
+//		...handle error
+//	}
+//	stats, err := file.LastAnalysisStats()
+func (s *FileScanner) ScanAndWait(ctx context.Context, r io.Reader, filename string, options ...WaitOption) (*Object, error) {
+	h := sha256.New()
+	analysis, err := s.Scan(io.TeeReader(r, h), filename, nil)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := NewAnalysis(analysis).WaitForCompletion(ctx, s.cli, 5*time.Second, options...); err != nil {
+		return nil, err
+	}
+	return s.cli.GetObject(URL("files/%s", hex.EncodeToString(h.Sum(nil))))
+}
+
+// ScanFileAndWait is like ScanAndWait, but it receives an *os.File instead of
+// a io.Reader and a file name, like ScanFile.
+func (s *FileScanner) ScanFileAndWait(ctx context.Context, f *os.File, options ...WaitOption) (*Object, error) {
+	return s.ScanAndWait(ctx, f, s.name(f.Name()), options...)
+}
+
+// outdated returns true if the last analysis of a file is older than the
+// scanner's maximum age.
+func (s *FileScanner) outdated(file *Object) bool {
+	if s.maxAge <= 0 {
+		return false
+	}
+	date, err := file.GetTime("last_analysis_date")
+	return err != nil || time.Since(date) > s.maxAge
 }
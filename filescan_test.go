@@ -0,0 +1,269 @@
+package vt
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileScannerScanFile(t *testing.T) {
+	var received string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, header, err := r.FormFile("file")
+		assert.NoError(t, err)
+		received = header.Filename
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": {"type": "analysis", "id": "f-1234"}}`))
+	}))
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "vt")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "sample.exe")
+	assert.NoError(t, ioutil.WriteFile(path, []byte("foo"), 0644))
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+
+	f, err := os.Open(path)
+	assert.NoError(t, err)
+	defer f.Close()
+
+	a, err := c.NewFileScanner().ScanFile(f, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "sample.exe", received)
+	submitted, err := a.GetContextString("filename")
+	assert.NoError(t, err)
+	assert.Equal(t, "sample.exe", submitted)
+
+	_, err = f.Seek(0, 0)
+	assert.NoError(t, err)
+
+	s := c.NewFileScannerWithOptions(FileScannerSubmissionName(func(string) string { return "renamed.bin" }))
+	a, err = s.ScanFile(f, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "renamed.bin", received)
+	submitted, err = a.GetContextString("filename")
+	assert.NoError(t, err)
+	assert.Equal(t, "renamed.bin", submitted)
+}
+
+func TestFileScannerProgress(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _, err := r.FormFile("file")
+		assert.NoError(t, err)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": {"type": "analysis", "id": "f-1234"}}`))
+	}))
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+
+	var last float32
+	s := c.NewFileScannerWithOptions(FileScannerOnProgress(func(pct float32) { last = pct }))
+	// Nobody reads from the progress channel, but the upload doesn't block.
+	progress := make(chan float32)
+	_, err := s.Scan(strings.NewReader("foo"), "foo.txt", progress)
+	assert.NoError(t, err)
+	assert.Equal(t, float32(100), last)
+}
+
+func TestFileScannerScanOrLookup(t *testing.T) {
+	// SHA-256 of "known".
+	known := "7117fff2d0fd294462b3c802b7cb8753579f23f3946b99cf55f38e873f013f10"
+	var uploads int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "POST":
+			atomic.AddInt32(&uploads, 1)
+			w.Write([]byte(`{"data": {"type": "analysis", "id": "f-1234"}}`))
+		case r.URL.Path == "/api/v3/files/"+known:
+			w.Write([]byte(`{"data": {"type": "file", "id": "` + known + `",
+				"attributes": {"last_analysis_date": 1577880000}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": {"code": "NotFoundError"}}`))
+		}
+	}))
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+	s := c.NewFileScanner()
+
+	obj, uploaded, err := s.ScanOrLookup(strings.NewReader("known"), "known.txt", nil)
+	assert.NoError(t, err)
+	assert.False(t, uploaded)
+	assert.Equal(t, "file", obj.Type())
+
+	obj, uploaded, err = s.ScanOrLookup(strings.NewReader("unknown"), "unknown.txt", nil)
+	assert.NoError(t, err)
+	assert.True(t, uploaded)
+	assert.Equal(t, "analysis", obj.Type())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&uploads))
+
+	// The known file was analysed long ago.
+	s = c.NewFileScannerWithOptions(FileScannerMaxAge(24 * time.Hour))
+	_, uploaded, err = s.ScanOrLookup(strings.NewReader("known"), "known.txt", nil)
+	assert.NoError(t, err)
+	assert.True(t, uploaded)
+}
+
+func TestFileScannerScanAndWait(t *testing.T) {
+	// SHA-256 of "foo".
+	const hash = "2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"
+	var polls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v3/files":
+			w.Write([]byte(`{"data": {"type": "analysis", "id": "a-1234"}}`))
+		case "/api/v3/analyses/a-1234":
+			status := "queued"
+			if atomic.AddInt32(&polls, 1) >= 2 {
+				status = "completed"
+			}
+			w.Write([]byte(`{"data": {"type": "analysis", "id": "a-1234", "attributes": {"status": "` + status + `"}}}`))
+		case "/api/v3/files/" + hash:
+			w.Write([]byte(`{"data": {"type": "file", "id": "` + hash + `", "attributes": {
+				"last_analysis_stats": {"malicious": 3}}}}`))
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+
+	file, err := c.NewFileScanner().ScanAndWait(context.Background(),
+		strings.NewReader("foo"), "foo.txt", WaitInterval(time.Millisecond))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&polls))
+	assert.Equal(t, hash, file.ID())
+	stats, err := file.LastAnalysisStats()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), stats.Malicious)
+}
+
+func TestFileScannerUploadURL(t *testing.T) {
+	var urlRequests, expired int32
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/api/v3/files/upload_url" {
+			n := atomic.AddInt32(&urlRequests, 1)
+			fmt.Fprintf(w, `{"data": "%s/upload/%d"}`, ts.URL, n)
+			return
+		}
+		io.Copy(ioutil.Discard, r.Body)
+		if r.URL.Path == fmt.Sprintf("/upload/%d", atomic.LoadInt32(&expired)) {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"error": {"code": "ForbiddenError"}}`))
+			return
+		}
+		w.Write([]byte(`{"data": {"type": "analysis", "id": "f-1234"}}`))
+	}))
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	content := []byte("larger than the payload limit")
+
+	c := NewClient("api_key", WithMaxPayloadSize(10))
+	s := c.NewFileScanner()
+	for i := 0; i < 2; i++ {
+		_, err := s.Scan(bytes.NewReader(content), "big.bin", nil)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&urlRequests))
+
+	// The reused URL is rejected, and a new one is obtained.
+	atomic.StoreInt32(&expired, 1)
+	_, err := s.Scan(bytes.NewReader(content), "big.bin", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&urlRequests))
+
+	// URLs are not reused when the validity is 0.
+	atomic.StoreInt32(&expired, 0)
+	c = NewClient("api_key", WithMaxPayloadSize(10), WithUploadURLValidity(0))
+	s = c.NewFileScanner()
+	for i := 0; i < 2; i++ {
+		_, err := s.Scan(bytes.NewReader(content), "big.bin", nil)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int32(4), atomic.LoadInt32(&urlRequests))
+}
+
+func TestFileScannerMaxFileSize(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s", r.URL.Path)
+	}))
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key", WithMaxFileSize(2))
+
+	_, err := c.NewFileScanner().Scan(strings.NewReader("foo"), "foo.txt", nil)
+	assert.True(t, errors.Is(err, ErrFileTooLarge))
+	assert.EqualError(t, err, "file too large, the maximum size is 2 bytes")
+
+	_, _, err = c.NewFileScanner().ScanOrLookup(strings.NewReader("foo"), "foo.txt", nil)
+	assert.True(t, errors.Is(err, ErrFileTooLarge))
+}
+
+func TestFileScannerSpill(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vt")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	content := strings.Repeat("foo", 100)
+	var requests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The body being uploaded is in a temporary file.
+		files, err := ioutil.ReadDir(dir)
+		assert.NoError(t, err)
+		assert.Len(t, files, 1)
+		f, _, err := r.FormFile("file")
+		assert.NoError(t, err)
+		received, err := ioutil.ReadAll(f)
+		assert.NoError(t, err)
+		assert.Equal(t, content, string(received))
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": {"type": "analysis", "id": "f-1234"}}`))
+	}))
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+
+	s := c.NewFileScannerWithOptions(
+		FileScannerSpillThreshold(100),
+		FileScannerSpillDir(dir))
+	a, err := s.Scan(strings.NewReader(content), "foo.txt", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "f-1234", a.ID())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+
+	// The temporary file is removed after the upload.
+	files, err := ioutil.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Empty(t, files)
+}
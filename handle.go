@@ -0,0 +1,96 @@
+// Copyright © 2017 The vt-go authors. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vt
+
+import (
+	"net/url"
+)
+
+// Collections where objects of each type live, relative to the API root.
+// Types not listed here live in a collection named after the type in plural,
+// like "files" for "file".
+var objectCollections = map[string]string{
+	"analysis":                   "analyses",
+	"ip_address":                 "ip_addresses",
+	"hunting_ruleset":            "intelligence/hunting_rulesets",
+	"hunting_notification":       "intelligence/hunting_notifications",
+	"retrohunt_job":              "intelligence/retrohunt_jobs",
+	"zip_file":                   "intelligence/zip_files",
+	"intelligence_search_export": "intelligence/search_exports",
+	"monitor_item":               "monitor/items",
+}
+
+// ObjectURL returns the URL for the object with the given type and ID. For
+// example ObjectURL("file", hash) returns the same URL than
+// URL("files/%s", hash).
+func ObjectURL(objType, id string) *url.URL {
+	collection, ok := objectCollections[objType]
+	if !ok {
+		collection = objType + "s"
+	}
+	return URL("%s/%s", collection, url.PathEscape(id))
+}
+
+// ObjectHandle is a reference to an object in VirusTotal, bound to a client,
+// that allows retrieving, modifying and deleting the object without building
+// its URL. ObjectHandle embeds an Object, which holds the object's data as
+// retrieved by the last call to Refresh, and where attributes can be set
+// before calling Save.
+type ObjectHandle struct {
+	*Object
+	cli *Client
+}
+
+// Object returns a handle for the object with the given type and ID. The
+// object is not retrieved from VirusTotal until Refresh is called.
+func (cli *Client) Object(objType, id string) *ObjectHandle {
+	return &ObjectHandle{Object: NewObjectWithID(objType, id), cli: cli}
+}
+
+// URL returns the object's URL.
+func (h *ObjectHandle) URL() *url.URL {
+	return ObjectURL(h.Type(), h.ID())
+}
+
+// Refresh retrieves the object from VirusTotal, replacing the data in the
+// handle, including any modification not saved yet.
+func (h *ObjectHandle) Refresh(options ...RequestOption) error {
+	obj, err := h.cli.GetObject(h.URL(), options...)
+	if err != nil {
+		return err
+	}
+	h.Object = obj
+	return nil
+}
+
+// Save sends the attributes modified in the object to VirusTotal with a PATCH
+// request, and updates the object with the data returned by the server.
+func (h *ObjectHandle) Save(options ...RequestOption) error {
+	if err := h.cli.PatchObject(h.URL(), h.Object, options...); err != nil {
+		return err
+	}
+	h.lock()
+	h.modifiedAttributes = nil
+	h.modifiedContextAttributes = nil
+	h.modifiedData = nil
+	h.modifiedRelationships = nil
+	h.unlock()
+	return nil
+}
+
+// Delete deletes the object from VirusTotal.
+func (h *ObjectHandle) Delete(options ...RequestOption) error {
+	_, err := h.cli.Delete(h.URL(), options...)
+	return err
+}
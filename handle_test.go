@@ -0,0 +1,56 @@
+package vt
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObjectURL(t *testing.T) {
+	SetHost("https://www.virustotal.com")
+	assert.Equal(t, "https://www.virustotal.com/api/v3/files/1234", ObjectURL("file", "1234").String())
+	assert.Equal(t, "https://www.virustotal.com/api/v3/ip_addresses/8.8.8.8", ObjectURL("ip_address", "8.8.8.8").String())
+	assert.Equal(t, "https://www.virustotal.com/api/v3/intelligence/hunting_rulesets/1234", ObjectURL("hunting_ruleset", "1234").String())
+}
+
+func TestObjectHandle(t *testing.T) {
+	var methods, bodies []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v3/intelligence/hunting_rulesets/1234", r.URL.Path)
+		body, _ := ioutil.ReadAll(r.Body)
+		methods = append(methods, r.Method)
+		bodies = append(bodies, string(body))
+		if r.Method == "DELETE" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": {"type": "hunting_ruleset", "id": "1234",
+			"attributes": {"name": "foo", "enabled": true}}}`))
+	}))
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+
+	h := c.Object("hunting_ruleset", "1234")
+	assert.NoError(t, h.Refresh())
+	assert.Equal(t, "foo", h.MustGetString("name"))
+
+	assert.NoError(t, h.SetBool("enabled", false))
+	assert.NoError(t, h.Save())
+	assert.NoError(t, h.Save())
+	assert.NoError(t, h.Delete())
+
+	assert.Equal(t, []string{"GET", "PATCH", "PATCH", "DELETE"}, methods)
+	assert.JSONEq(t,
+		`{"data": {"type": "hunting_ruleset", "id": "1234", "attributes": {"enabled": false}}}`,
+		bodies[1])
+	// Modifications are sent only once.
+	assert.JSONEq(t,
+		`{"data": {"type": "hunting_ruleset", "id": "1234", "attributes": {}}}`,
+		bodies[2])
+}
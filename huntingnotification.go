@@ -0,0 +1,61 @@
+// Copyright © 2017 The vt-go authors. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vt
+
+import "time"
+
+// HuntingNotification is an Object of type "hunting_notification", which is
+// generated when a file matches a Livehunt rule. Notifications can be received
+// from the hunting notification feed, or retrieved from the
+// intelligence/hunting_notifications collection.
+type HuntingNotification struct {
+	*Object
+}
+
+// NewHuntingNotification returns a HuntingNotification from an Object of type
+// "hunting_notification".
+func NewHuntingNotification(obj *Object) *HuntingNotification {
+	return &HuntingNotification{Object: obj}
+}
+
+// RuleName returns the name of the rule that matched the file.
+func (n *HuntingNotification) RuleName() (string, error) {
+	return n.GetString("rule_name")
+}
+
+// RulesetName returns the name of the ruleset containing the rule.
+func (n *HuntingNotification) RulesetName() (string, error) {
+	return n.GetString("ruleset_name")
+}
+
+// RuleTags returns the tags of the rule that matched the file.
+func (n *HuntingNotification) RuleTags() ([]string, error) {
+	return n.GetStringSlice("rule_tags")
+}
+
+// Snippet returns an hexdump of the file's content around the matching
+// strings.
+func (n *HuntingNotification) Snippet() (string, error) {
+	return n.GetString("snippet")
+}
+
+// Date returns the date in which the notification was generated.
+func (n *HuntingNotification) Date() (time.Time, error) {
+	return n.GetTime("date")
+}
+
+// FileID returns the SHA-256 of the file that matched the rule.
+func (n *HuntingNotification) FileID() (string, error) {
+	return n.GetString("sha256")
+}
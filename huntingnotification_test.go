@@ -0,0 +1,42 @@
+package vt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHuntingNotification(t *testing.T) {
+	// Lines in the hunting notification feed may not include the type.
+	obj, err := parseFeedLine(HuntingNotificationFeed, []byte(`{
+		"id": "1234",
+		"rule_name": "evil",
+		"ruleset_name": "my rules",
+		"rule_tags": ["apt"],
+		"snippet": "4d 5a 90 00",
+		"date": 1577880000,
+		"sha256": "abcd"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "hunting_notification", obj.Type())
+
+	n := NewHuntingNotification(obj)
+	rule, err := n.RuleName()
+	assert.NoError(t, err)
+	assert.Equal(t, "evil", rule)
+	ruleset, err := n.RulesetName()
+	assert.NoError(t, err)
+	assert.Equal(t, "my rules", ruleset)
+	tags, err := n.RuleTags()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"apt"}, tags)
+	snippet, err := n.Snippet()
+	assert.NoError(t, err)
+	assert.Equal(t, "4d 5a 90 00", snippet)
+	date, err := n.Date()
+	assert.NoError(t, err)
+	assert.True(t, date.Equal(time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)))
+	sha256, err := n.FileID()
+	assert.NoError(t, err)
+	assert.Equal(t, "abcd", sha256)
+}
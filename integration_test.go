@@ -0,0 +1,133 @@
+//go:build integration
+// +build integration
+
+// Integration tests that exercise read-only endpoints of the VirusTotal API.
+// These tests are not run by default, they must be explicitly enabled with
+// the "integration" build tag and require an API key:
+//
+//	VT_API_KEY=<api key> go test -tags integration github.com/VirusTotal/vt-go
+//
+// Downstream projects can run the same command from their own module for
+// verifying the vt-go version they depend on against the live API. The tests
+// consume a small amount of quota, which is capped by VT_MAX_REQUESTS (20 by
+// default). The host can be changed with VT_HOST, for running the tests
+// against a mock server.
+
+package vt
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// SHA-256 of the EICAR test file, which is well known by VirusTotal.
+const eicarSHA256 = "275a021bbfb6489e54d471899f7db9d1663fc695ec2fe2a2c4538aabf651fd0f"
+
+var errBudgetExhausted = errors.New("integration tests request budget exhausted")
+
+// budgetTransport is a http.RoundTripper that fails once the number of
+// requests exceeds the budget, so that a misbehaving test can't consume more
+// quota than expected.
+type budgetTransport struct {
+	base      http.RoundTripper
+	remaining int32
+}
+
+func (t *budgetTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if atomic.AddInt32(&t.remaining, -1) < 0 {
+		return nil, errBudgetExhausted
+	}
+	return t.base.RoundTrip(req)
+}
+
+var integrationTransport = &budgetTransport{base: http.DefaultTransport}
+
+func init() {
+	integrationTransport.remaining = 20
+	if s := os.Getenv("VT_MAX_REQUESTS"); s != "" {
+		if n, err := strconv.Atoi(s); err == nil {
+			integrationTransport.remaining = int32(n)
+		}
+	}
+}
+
+func integrationClient(t *testing.T) *Client {
+	apiKey := os.Getenv("VT_API_KEY")
+	if apiKey == "" {
+		t.Skip("VT_API_KEY not set")
+	}
+	if host := os.Getenv("VT_HOST"); host != "" {
+		SetHost(host)
+	} else {
+		SetHost("https://www.virustotal.com")
+	}
+	c := NewClient(apiKey, WithHTTPClient(&http.Client{
+		Transport: integrationTransport,
+		Timeout:   30 * time.Second,
+	}))
+	c.Agent = "vt-go integration tests"
+	return c
+}
+
+func TestIntegrationMetadata(t *testing.T) {
+	c := integrationClient(t)
+	metadata, err := c.GetMetadata()
+	assert.NoError(t, err)
+	assert.NotEmpty(t, metadata.Engines)
+	assert.NotEmpty(t, metadata.Relationships["file"])
+}
+
+func TestIntegrationFile(t *testing.T) {
+	c := integrationClient(t)
+	f, err := c.GetObject(URL("files/%s", eicarSHA256))
+	assert.NoError(t, err)
+	assert.Equal(t, "file", f.Type())
+	assert.Equal(t, eicarSHA256, f.ID())
+	assert.Equal(t, eicarSHA256, f.MustGetString("sha256"))
+	_, err = f.GetTime("first_submission_date")
+	assert.NoError(t, err)
+	malicious, err := f.GetInt64("last_analysis_stats.malicious")
+	assert.NoError(t, err)
+	assert.True(t, malicious > 0)
+}
+
+func TestIntegrationDomain(t *testing.T) {
+	c := integrationClient(t)
+	d, err := c.GetObject(URL("domains/virustotal.com"))
+	assert.NoError(t, err)
+	assert.Equal(t, "domain", d.Type())
+	_, err = d.GetCategories()
+	assert.NoError(t, err)
+}
+
+func TestIntegrationIterator(t *testing.T) {
+	c := integrationClient(t)
+	it, err := c.Iterator(URL("files/%s/comments", eicarSHA256),
+		IteratorBatchSize(2), IteratorLimit(2))
+	assert.NoError(t, err)
+	defer it.Close()
+	n := 0
+	for it.Next() {
+		assert.Equal(t, "comment", it.Get().Type())
+		n++
+	}
+	assert.NoError(t, it.Error())
+	assert.Equal(t, 2, n)
+}
+
+func TestIntegrationNotFound(t *testing.T) {
+	c := integrationClient(t)
+	_, err := c.GetObject(URL("files/%s", "0000000000000000000000000000000000000000000000000000000000000000"))
+	assert.Error(t, err)
+	var apiErr Error
+	if assert.True(t, errors.As(err, &apiErr)) {
+		assert.Equal(t, "NotFoundError", apiErr.Code)
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright © 2019 The vt-go authors. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vt
+
+const (
+	// Only strings shorter than this are interned. Values worth interning,
+	// like engine names, tags or categories, are short, while longer strings
+	// like hashes are usually unique and would only bloat the table.
+	maxInternedStringLen = 32
+	// Maximum number of distinct strings kept by a stringInterner. Once the
+	// limit is reached already known strings are still interned, but new ones
+	// are not added to the table.
+	maxInternedStrings = 1 << 16
+)
+
+// stringInterner deduplicates strings so that equal strings share the same
+// underlying memory. It's not safe for concurrent use.
+type stringInterner struct {
+	strings map[string]string
+}
+
+func newStringInterner() *stringInterner {
+	return &stringInterner{strings: make(map[string]string)}
+}
+
+func (si *stringInterner) intern(s string) string {
+	if len(s) >= maxInternedStringLen {
+		return s
+	}
+	if interned, ok := si.strings[s]; ok {
+		return interned
+	}
+	if len(si.strings) < maxInternedStrings {
+		si.strings[s] = s
+	}
+	return s
+}
+
+// internValue interns all the strings found in v, which is a value produced
+// by the JSON decoder, and returns the resulting value. Maps and slices are
+// modified in place, map keys are interned too.
+func (si *stringInterner) internValue(v interface{}) interface{} {
+	switch value := v.(type) {
+	case string:
+		return si.intern(value)
+	case []interface{}:
+		for i, item := range value {
+			value[i] = si.internValue(item)
+		}
+	case map[string]interface{}:
+		si.internMap(value)
+	}
+	return v
+}
+
+func (si *stringInterner) internMap(m map[string]interface{}) {
+	for key, item := range m {
+		interned := si.intern(key)
+		// Replacing the key with an equal one leaves the map's length unchanged,
+		// so it's safe to do it while iterating.
+		m[interned] = si.internValue(item)
+	}
+}
+
+// internObject interns the strings in the object's attributes and context
+// attributes.
+func (si *stringInterner) internObject(obj *Object) {
+	obj.data.Type = si.intern(obj.data.Type)
+	si.internMap(obj.data.Attributes)
+	si.internMap(obj.data.ContextAttributes)
+}
@@ -0,0 +1,82 @@
+// Copyright © 2017 The vt-go authors. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vt
+
+import "net"
+
+// IPAddress is an Object of type "ip_address", it provides typed accessors for
+// the most relevant attributes of an IP address, while the generic methods
+// from Object are still available.
+type IPAddress struct {
+	*Object
+}
+
+// NewIPAddress returns an IPAddress from an Object of type "ip_address".
+func NewIPAddress(obj *Object) *IPAddress {
+	return &IPAddress{Object: obj}
+}
+
+// ASN returns the number of the autonomous system the IP address belongs to.
+func (ip *IPAddress) ASN() (int64, error) {
+	return ip.GetInt64("asn")
+}
+
+// ASOwner returns the owner of the autonomous system the IP address belongs
+// to.
+func (ip *IPAddress) ASOwner() (string, error) {
+	return ip.GetString("as_owner")
+}
+
+// Country returns the ISO 3166 code of the country where the IP address is
+// located.
+func (ip *IPAddress) Country() (string, error) {
+	return ip.GetString("country")
+}
+
+// Network returns the IP network the address belongs to.
+func (ip *IPAddress) Network() (*net.IPNet, error) {
+	cidr, err := ip.GetString("network")
+	if err != nil {
+		return nil, err
+	}
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, err
+	}
+	return network, nil
+}
+
+// RIR returns the regional internet registry that assigned the IP address,
+// like "ARIN" or "RIPE NCC".
+func (ip *IPAddress) RIR() (string, error) {
+	return ip.GetString("regional_internet_registry")
+}
+
+// Reputation returns the IP address' reputation score, as computed from the
+// votes of the VirusTotal community.
+func (ip *IPAddress) Reputation() (int64, error) {
+	return ip.GetInt64("reputation")
+}
+
+// Resolutions returns an iterator over the past resolutions of domains to
+// this IP address.
+func (ip *IPAddress) Resolutions(cli *Client, options ...IteratorOption) (*Iterator, error) {
+	return cli.Iterator(URL("ip_addresses/%s/resolutions", ip.ID()), options...)
+}
+
+// CommunicatingFiles returns an iterator over the files that communicate with
+// this IP address.
+func (ip *IPAddress) CommunicatingFiles(cli *Client, options ...IteratorOption) (*Iterator, error) {
+	return cli.Iterator(URL("ip_addresses/%s/communicating_files", ip.ID()), options...)
+}
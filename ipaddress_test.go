@@ -0,0 +1,73 @@
+package vt
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIPAddress(t *testing.T) {
+	obj := &Object{}
+	err := json.Unmarshal([]byte(`{
+		"type": "ip_address",
+		"id": "8.8.8.8",
+		"attributes": {
+			"asn": 15169,
+			"as_owner": "GOOGLE",
+			"country": "US",
+			"network": "8.8.8.0/24",
+			"regional_internet_registry": "ARIN",
+			"reputation": 12
+		}}`), obj)
+	assert.NoError(t, err)
+
+	ip := NewIPAddress(obj)
+
+	asn, err := ip.ASN()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(15169), asn)
+
+	owner, err := ip.ASOwner()
+	assert.NoError(t, err)
+	assert.Equal(t, "GOOGLE", owner)
+
+	country, err := ip.Country()
+	assert.NoError(t, err)
+	assert.Equal(t, "US", country)
+
+	network, err := ip.Network()
+	assert.NoError(t, err)
+	assert.Equal(t, "8.8.8.0/24", network.String())
+
+	rir, err := ip.RIR()
+	assert.NoError(t, err)
+	assert.Equal(t, "ARIN", rir)
+
+	reputation, err := ip.Reputation()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12), reputation)
+}
+
+func TestIPAddressResolutions(t *testing.T) {
+	ts := NewTestServer(t).
+		SetExpectedMethod("GET").
+		SetResponse(map[string]interface{}{
+			"data": []map[string]interface{}{
+				{"type": "resolution", "id": "8.8.8.8dns.google"},
+			}})
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+
+	ip := NewIPAddress(NewObjectWithID("ip_address", "8.8.8.8"))
+	it, err := ip.Resolutions(c)
+	assert.NoError(t, err)
+	defer it.Close()
+
+	assert.True(t, it.Next())
+	assert.Equal(t, "8.8.8.8dns.google", it.Get().ID())
+	assert.False(t, it.Next())
+	assert.NoError(t, it.Error())
+}
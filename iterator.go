@@ -16,10 +16,15 @@ package vt
 import (
 	"bytes"
 	"compress/flate"
+	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/url"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -58,19 +63,32 @@ func (c *cursor) decode(s string) error {
 	if err != nil {
 		return err
 	}
+	if u, err := url.Parse(c.Link); err != nil || !u.IsAbs() {
+		return fmt.Errorf("invalid cursor link \"%s\"", c.Link)
+	}
 	return nil
 }
 
 type collectionObject struct {
 	object *Object
 	cursor cursor
+	// Cursor provided by the server, used when the iterator uses native
+	// cursors.
+	nativeCursor string
 }
 
+// pageEnd is sent through the iterator's channel after the objects in each
+// page when the number of prefetched pages is limited.
+type pageEnd struct{}
+
 // IteratorOption represents an option passed to an iterator.
 type IteratorOption func(*Iterator) error
 
 // IteratorCursor specifies a cursor for the iterator. The iterator will start
-// at the point indicated by the cursor.
+// at the point indicated by the cursor. Both cursors returned by Cursor and
+// cursors provided by the server, like those used by vt-cli and other
+// VirusTotal tools, are accepted. In the latter case the iterator behaves as
+// if IteratorNativeCursor was used, so Cursor returns server cursors too.
 func IteratorCursor(cursor string) IteratorOption {
 	return func(it *Iterator) error {
 		it.cursor = cursor
@@ -78,6 +96,24 @@ func IteratorCursor(cursor string) IteratorOption {
 	}
 }
 
+// IteratorNativeCursor receives a boolean that indicates whether the iterator
+// must use the cursors provided by the server in the "cursor" field of the
+// collection's metadata, instead of its own cursors. Native cursors don't
+// expire like the links embedded in the iterator's own cursors, and are
+// compatible with cursors produced by other VirusTotal tools, but they can
+// only point to the start of a page. In this mode Cursor returns a cursor
+// pointing to the start of the page containing the current object, except
+// for the last object in a page, for which it returns the cursor of the next
+// page. This means that resuming from a cursor can return again some objects
+// from the current page. IteratorCursor and Reset accept both kinds of
+// cursors in any case.
+func IteratorNativeCursor(b bool) IteratorOption {
+	return func(it *Iterator) error {
+		it.nativeCursor = b
+		return nil
+	}
+}
+
 // IteratorFilter specifies a filtering query that is sent to the backend. The
 // backend will return items that comply with the condition imposed by the
 // filter. The filter syntax varies depending on the collection being iterated.
@@ -88,6 +124,111 @@ func IteratorFilter(filter string) IteratorOption {
 	}
 }
 
+// IteratorAttributes specifies the attributes that the backend must return for
+// each object, other attributes are omitted. This reduces the bandwidth and
+// latency while iterating large collections, like search results, when only
+// a few attributes are needed. By default all the attributes are returned.
+func IteratorAttributes(attrs ...string) IteratorOption {
+	return func(it *Iterator) error {
+		it.attributes = attrs
+		return nil
+	}
+}
+
+// IteratorOrder specifies the order in which the objects are returned, which
+// is the name of an attribute followed by "+" for ascending order or "-" for
+// descending order, like in "first_submission_date-". If the order doesn't
+// include the suffix the order is ascending. Not all collections support all
+// orders, with WithStrictValidation the attribute is checked against the
+// attributes of the objects in the collection before sending the request.
+func IteratorOrder(order string) IteratorOption {
+	return func(it *Iterator) error {
+		attr := strings.TrimRight(order, "+-")
+		if attr == "" || len(order)-len(attr) > 1 || strings.ContainsAny(attr, " ,") {
+			return fmt.Errorf("invalid order \"%s\"", order)
+		}
+		it.order = order
+		return nil
+	}
+}
+
+// IteratorBufferSize specifies the maximum number of objects that the iterator
+// retrieves in background before they are requested with Next. The default
+// is 50 objects.
+func IteratorBufferSize(n int) IteratorOption {
+	return func(it *Iterator) error {
+		if n < 1 {
+			return fmt.Errorf("invalid buffer size %d", n)
+		}
+		it.bufferSize = n
+		return nil
+	}
+}
+
+// IteratorPrefetch specifies the maximum number of pages that the iterator
+// retrieves in background before the objects in them are requested with Next,
+// including the page being consumed. By default the number of pages is not
+// limited, but the iterator never holds more objects than the buffer size
+// specified with IteratorBufferSize. Memory-constrained consumers of
+// collections with large objects can use 1 for retrieving a page only after
+// the previous one was fully consumed.
+func IteratorPrefetch(pages int) IteratorOption {
+	return func(it *Iterator) error {
+		if pages < 1 {
+			return fmt.Errorf("invalid number of pages %d", pages)
+		}
+		it.prefetch = pages
+		return nil
+	}
+}
+
+// IteratorRetry specifies the number of times that the iterator retries the
+// retrieval of a page after a transient error, like a network error or an API
+// error for which IsRetryableError returns true. The first retry occurs after
+// the given delay, which is doubled after each attempt. When retries succeed
+// the iteration continues from the same position without any error being
+// reported, otherwise the last error is returned by Error as usual. By
+// default failed pages are not retried.
+func IteratorRetry(retries int, delay time.Duration) IteratorOption {
+	return func(it *Iterator) error {
+		if retries < 0 {
+			return fmt.Errorf("invalid number of retries %d", retries)
+		}
+		if delay <= 0 {
+			return fmt.Errorf("invalid retry delay %s", delay)
+		}
+		it.retries = retries
+		it.retryDelay = delay
+		return nil
+	}
+}
+
+// CursorStore is the interface implemented by types that persist the cursor
+// of an iterator, see IteratorCursorStore. Load returns the last cursor saved,
+// or an empty string if no cursor has been saved yet.
+type CursorStore interface {
+	Load() (string, error)
+	Save(cursor string) error
+}
+
+// IteratorCursorStore specifies a CursorStore where the iterator saves its
+// cursor after every n objects returned by Next. When the iterator is created
+// without an explicit cursor it starts at the cursor loaded from the store, so
+// a long-running enumeration can be resumed after the process is restarted.
+// Objects returned after the last saved cursor are returned again after a
+// restart. If the cursor can't be saved the iteration stops, and the error is
+// returned by Error.
+func IteratorCursorStore(store CursorStore, n int) IteratorOption {
+	return func(it *Iterator) error {
+		if n < 1 {
+			return fmt.Errorf("invalid number of objects %d", n)
+		}
+		it.cursorStore = store
+		it.saveEvery = n
+		return nil
+	}
+}
+
 // IteratorBatchSize specifies the number of items that are retrieved in a
 // single call to the backend.
 func IteratorBatchSize(n int) IteratorOption {
@@ -115,14 +256,35 @@ func IteratorDescriptorsOnly(b bool) IteratorOption {
 	}
 }
 
+// IteratorRequiredAttributes specifies a list of attributes that objects must
+// have. Objects lacking any of these attributes are silently skipped by the
+// iterator, unless IteratorFailOnMissingAttributes is used. Attribute names
+// can include dots for referring to nested attributes, like in Object.Get.
+func IteratorRequiredAttributes(attrs ...string) IteratorOption {
+	return func(it *Iterator) error {
+		it.requiredAttributes = attrs
+		return nil
+	}
+}
+
+// IteratorFailOnMissingAttributes receives a boolean that indicates whether
+// the iterator must stop with an error when it finds an object that lacks
+// some of the attributes specified with IteratorRequiredAttributes, instead
+// of skipping the object.
+func IteratorFailOnMissingAttributes(b bool) IteratorOption {
+	return func(it *Iterator) error {
+		it.failOnMissingAttributes = b
+		return nil
+	}
+}
+
 // Iterator represents a iterator over a collection of VirusTotal objects.
 type Iterator struct {
 	client          *Client
 	ch              chan interface{}
-	done            chan bool
+	done            chan struct{}
 	next            *Object
 	err             error
-	closed          bool
 	limit           int
 	count           int
 	batchSize       int
@@ -131,15 +293,50 @@ type Iterator struct {
 	descriptorsOnly bool
 	links           Links
 	meta            map[string]interface{}
+	// URL for the first page of the collection.
+	firstURL string
+	// True if the current object is the last one in its page.
+	lastInPage bool
+	// Closed when the goroutine retrieving objects in background finishes.
+	finished chan struct{}
+	// Attributes that objects must have in order to be returned by the
+	// iterator.
+	requiredAttributes      []string
+	failOnMissingAttributes bool
+	// If true the iterator uses the cursors provided by the server.
+	nativeCursor bool
+	// Attributes returned by the backend, all of them if empty.
+	attributes []string
+	// Order in which objects are returned, like "first_submission_date-".
+	order string
+	// Closed by Close for stopping the goroutine retrieving objects.
+	closeOnce *sync.Once
+	// Closed when the iterator terminates, see Done.
+	terminated    chan struct{}
+	terminateOnce *sync.Once
+	// Protects meta, which is updated by the goroutine retrieving objects.
+	metaMu sync.Mutex
+	// Closed when the first page of the collection has been retrieved, or
+	// the attempt failed.
+	firstPage chan struct{}
+	// Size of the channel's buffer.
+	bufferSize int
+	// Maximum number of pages retrieved but not consumed yet, zero means no
+	// limit. When limited, pages has a token for each of those pages.
+	prefetch int
+	pages    chan struct{}
+	// Number of times a page is retried after a transient error, and the
+	// delay before the first retry.
+	retries    int
+	retryDelay time.Duration
+	// Store where the cursor is saved after every saveEvery objects.
+	cursorStore CursorStore
+	saveEvery   int
 }
 
 func newIterator(cli *Client, u *url.URL, options ...IteratorOption) (*Iterator, error) {
 
-	skip := 0
-	it := &Iterator{
-		client: cli,
-		ch:     make(chan interface{}, 50),
-		done:   make(chan bool)}
+	it := &Iterator{client: cli, bufferSize: 50}
 
 	for _, opt := range options {
 		if err := opt(it); err != nil {
@@ -147,47 +344,131 @@ func newIterator(cli *Client, u *url.URL, options ...IteratorOption) (*Iterator,
 		}
 	}
 
-	if it.cursor != "" {
-		c := cursor{}
-		err := c.decode(it.cursor)
+	first := *u
+	q := first.Query()
+	if it.batchSize > 0 {
+		q.Add("limit", strconv.Itoa(it.batchSize))
+	}
+	if it.filter != "" {
+		q.Add("filter", it.filter)
+	}
+	if it.descriptorsOnly {
+		q.Add("descriptors_only", "true")
+	}
+	if it.order != "" {
+		q.Add("order", it.order)
+	}
+	if len(it.attributes) > 0 {
+		q.Add("attributes", strings.Join(it.attributes, ","))
+	}
+	first.RawQuery = q.Encode()
+	it.firstURL = first.String()
+
+	if it.cursorStore != nil && it.cursor == "" {
+		cursor, err := it.cursorStore.Load()
 		if err != nil {
 			return nil, err
 		}
+		it.cursor = cursor
+	}
+
+	if err := it.start(it.cursor); err != nil {
+		return nil, err
+	}
+
+	return it, nil
+}
+
+// start starts retrieving objects in background from the position indicated
+// by the cursor, or from the beginning of the collection if the cursor is
+// empty.
+func (it *Iterator) start(cursorStr string) error {
+	skip := 0
+	it.links = Links{}
+	c := cursor{}
+	if cursorStr == "" {
+		it.links.Next = it.firstURL
+	} else if err := c.decode(cursorStr); err == nil {
 		it.links.Next = c.Link
 		skip = c.Offset
 	} else {
-		q := u.Query()
-		if it.batchSize > 0 {
-			q.Add("limit", strconv.Itoa(it.batchSize))
-		}
-		if it.filter != "" {
-			q.Add("filter", it.filter)
-		}
-		if it.descriptorsOnly {
-			q.Add("descriptors_only", "true")
+		// Not a cursor produced by this iterator, it's assumed to be a cursor
+		// provided by the server, as used by other tools. From now on the
+		// iterator returns server cursors too.
+		u, err := url.Parse(it.firstURL)
+		if err != nil {
+			return err
 		}
+		q := u.Query()
+		q.Set("cursor", cursorStr)
 		u.RawQuery = q.Encode()
 		it.links.Next = u.String()
+		it.nativeCursor = true
 	}
 
+	it.cursor = cursorStr
+	it.next = nil
+	it.lastInPage = false
+	it.err = nil
+	it.count = 0
+	it.ch = make(chan interface{}, it.bufferSize)
+	if it.prefetch > 0 {
+		it.pages = make(chan struct{}, it.prefetch)
+	}
+	it.done = make(chan struct{})
+	it.closeOnce = &sync.Once{}
+	it.terminated = make(chan struct{})
+	it.terminateOnce = &sync.Once{}
+	it.finished = make(chan struct{})
+	it.firstPage = make(chan struct{})
+
 	go it.iterate(skip)
 
-	return it, nil
+	return nil
 }
 
 // Next advances the iterator to the next object and returns true if there are
 // more objects or false if the end of the collection has been reached.
 func (it *Iterator) Next() bool {
-	if it.limit > 0 && it.count == it.limit {
+	if !it.advance() {
+		it.terminate()
+		return false
+	}
+	return true
+}
+
+// advance implements Next, except for terminating the iterator.
+func (it *Iterator) advance() bool {
+	if it.err != nil || it.limit > 0 && it.count == it.limit {
 		return false
 	}
 	item, ok := <-it.ch
+	// Page boundaries allow the goroutine retrieving objects to fetch one
+	// more page.
+	for ; ok; item, ok = <-it.ch {
+		if _, isPageEnd := item.(pageEnd); !isPageEnd {
+			break
+		}
+		<-it.pages
+	}
 	if ok {
 		switch v := item.(type) {
 		case collectionObject:
 			it.next = v.object
-			it.cursor = v.cursor.encode()
+			it.lastInPage = v.cursor.Offset == 0
+			if it.nativeCursor {
+				it.cursor = v.nativeCursor
+			} else {
+				it.cursor = v.cursor.encode()
+			}
 			it.count++
+			if it.cursorStore != nil && it.count%it.saveEvery == 0 && it.cursor != "" {
+				if err := it.cursorStore.Save(it.cursor); err != nil {
+					it.next = nil
+					it.err = err
+					it.Close()
+				}
+			}
 		case error:
 			it.next = nil
 			it.err = v
@@ -201,25 +482,295 @@ func (it *Iterator) Get() *Object {
 	return it.next
 }
 
+// Descriptor returns the type and ID of the current object in the iterator.
+// This is specially useful with iterators that return only descriptors, like
+// those created with IteratorDescriptorsOnly or RelatedDescriptors.
+func (it *Iterator) Descriptor() Descriptor {
+	if it.next == nil {
+		return Descriptor{}
+	}
+	return Descriptor{Type: it.next.Type(), ID: it.next.ID()}
+}
+
 // Cursor returns a token indicating the current iterator's position.
 func (it *Iterator) Cursor() string {
 	return it.cursor
 }
 
-// Close closes a collection iterator.
+// Skip advances the iterator n objects, as if Next was called n times, and
+// returns the number of objects actually skipped, which can be lower than n if
+// the end of the collection is reached. Skipped objects count towards the
+// limit specified with IteratorLimit. Objects already retrieved in background
+// are skipped by advancing over them, the rest are skipped by moving the
+// iterator's position forward, requesting pages with only as many objects as
+// needed and without decoding them, except for the last skipped object, which
+// is returned by Get afterwards. Iterators using server cursors, required
+// attributes or a CursorStore skip objects one by one.
+func (it *Iterator) Skip(n int) int {
+	if it.limit > 0 && n > it.limit-it.count {
+		n = it.limit - it.count
+	}
+	skipped := 0
+	for skipped < n {
+		// Objects already retrieved are skipped by advancing over them, until
+		// reaching a page boundary.
+		if n-skipped > 1 && (len(it.ch) == 0 || it.lastInPage) && it.canSkipPages() {
+			skipped += it.skipPages(n - skipped - 1)
+			break
+		}
+		if !it.Next() {
+			return skipped
+		}
+		skipped++
+	}
+	for skipped < n && it.Next() {
+		skipped++
+	}
+	return skipped
+}
+
+// canSkipPages returns true if Skip can move the iterator's position without
+// retrieving the skipped objects.
+func (it *Iterator) canSkipPages() bool {
+	if it.err != nil || it.nativeCursor || it.cursorStore != nil || len(it.requiredAttributes) > 0 {
+		return false
+	}
+	// An empty cursor after some objects were returned means that the end of
+	// the collection was reached.
+	if it.cursor == "" && it.count > 0 {
+		return false
+	}
+	select {
+	case <-it.finished:
+		return false
+	default:
+		return true
+	}
+}
+
+// skipPages skips n objects by moving the position of the iterator, and
+// returns the number of objects skipped. The iteration is restarted at the
+// new position.
+func (it *Iterator) skipPages(n int) int {
+	pos := cursor{Link: it.firstURL}
+	if it.cursor != "" {
+		if err := pos.decode(it.cursor); err != nil {
+			return 0
+		}
+	}
+	count := it.count
+	it.stop()
+	skipped := 0
+	var err error
+	for skipped < n {
+		var got int
+		if pos, got, err = it.skipPage(pos, n-skipped); err != nil {
+			break
+		}
+		skipped += got
+		// The new position is within the page when the end of the collection
+		// was reached.
+		if got == 0 || pos.Offset > 0 {
+			break
+		}
+	}
+	if startErr := it.start(pos.encode()); startErr != nil && err == nil {
+		err = startErr
+	}
+	it.count = count + skipped
+	if err != nil {
+		it.err = err
+		it.Close()
+	}
+	return skipped
+}
+
+// Maximum number of objects requested in a single page by skipPage when the
+// iterator's pages don't have an explicit size.
+const maxSkipPageSize = 40
+
+// skipPage skips up to n objects starting at the given position, requesting a
+// page with no more objects than needed, and returns the position after the
+// skipped objects, and the number of objects actually skipped.
+func (it *Iterator) skipPage(pos cursor, n int) (cursor, int, error) {
+	u, err := url.Parse(pos.Link)
+	if err != nil {
+		return pos, 0, err
+	}
+	q := u.Query()
+	pageSize, _ := strconv.Atoi(q.Get("limit"))
+	maxSize := pageSize
+	if maxSize <= 0 {
+		maxSize = maxSkipPageSize
+	}
+	size := pos.Offset + n
+	if size > maxSize {
+		size = maxSize
+	}
+	q.Set("limit", strconv.Itoa(size))
+	u.RawQuery = q.Encode()
+	// Objects are not decoded, only counted.
+	var data []json.RawMessage
+	resp, err := it.client.GetData(u, &data)
+	if err != nil {
+		return pos, 0, err
+	}
+	available := len(data) - pos.Offset
+	if available <= 0 {
+		return pos, 0, nil
+	}
+	// If the page has more objects than needed, or it's the last one, the
+	// new position is within the page.
+	if available > n || resp.Links.Next == "" {
+		if available > n {
+			available = n
+		}
+		return cursor{Link: pos.Link, Offset: pos.Offset + available}, available, nil
+	}
+	// Otherwise the new position is at the start of the next page, which
+	// must have the iterator's page size.
+	next, err := url.Parse(resp.Links.Next)
+	if err != nil {
+		return pos, 0, err
+	}
+	q = next.Query()
+	if pageSize > 0 {
+		q.Set("limit", strconv.Itoa(pageSize))
+	} else {
+		q.Del("limit")
+	}
+	next.RawQuery = q.Encode()
+	return cursor{Link: next.String()}, available, nil
+}
+
+// Reset moves the iterator to the position indicated by a cursor previously
+// obtained with Cursor, or to the beginning of the collection if the cursor is
+// empty. Like in IteratorCursor, the cursor can be also a server cursor. Any
+// error occurred so far is cleared, and the count of objects returned by the
+// iterator, as used by IteratorLimit, starts over again.
+func (it *Iterator) Reset(cursor string) error {
+	it.stop()
+	return it.start(cursor)
+}
+
+// stop stops the goroutine retrieving objects in background and waits until
+// it finishes.
+func (it *Iterator) stop() {
+	it.Close()
+	// Drain the channel so that the background goroutine can finish.
+	for range it.ch {
+	}
+	<-it.finished
+}
+
+// Close closes a collection iterator. It's safe to call Close multiple times,
+// from multiple goroutines, and after the iterator reached the end of the
+// collection.
 func (it *Iterator) Close() {
-	if !it.closed {
-		it.closed = true
-		it.done <- true
+	it.closeOnce.Do(func() {
+		close(it.done)
+	})
+	it.terminate()
+}
+
+// Done returns a channel that is closed when the iterator terminates, either
+// because Next returned false or because Close was called. This allows
+// selecting on the iterator's termination alongside other events. After a call
+// to Reset, Done returns a new channel.
+func (it *Iterator) Done() <-chan struct{} {
+	return it.terminated
+}
+
+// terminate closes the channel returned by Done, it can be called multiple
+// times.
+func (it *Iterator) terminate() {
+	it.terminateOnce.Do(func() {
+		close(it.terminated)
+	})
+}
+
+// ForEach calls fn for every object returned by the iterator, using up to n
+// goroutines, so objects are processed in no particular order. Errors returned
+// by fn don't stop the iteration, ForEach returns a *MultiError with the error
+// for each object where fn failed, the index in the MultiError is the position
+// of the object in the iteration. If the context is cancelled no more objects
+// are retrieved, and ForEach returns the context's error after the calls to
+// fn in progress finish. An error occurred while retrieving the objects is
+// returned too. The iterator is not closed. Example:
+//
+//	err := it.ForEach(ctx, 8, func(obj *vt.Object) error {
+//		return process(obj)
+//	})
+func (it *Iterator) ForEach(ctx context.Context, n int, fn func(*Object) error) error {
+	return it.forEach(ctx, n, it.Next, fn)
+}
+
+// forEach implements ForEach, the iteration is advanced by calling next, which
+// allows iterators embedding Iterator to use their own Next.
+func (it *Iterator) forEach(ctx context.Context, n int, next func() bool, fn func(*Object) error) error {
+	if n < 1 {
+		n = 1
+	}
+	type indexedObject struct {
+		index int
+		obj   *Object
 	}
+	objs := make(chan indexedObject)
+	errs := &MultiError{}
+	var errsMu sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for o := range objs {
+				if err := fn(o.obj); err != nil {
+					errsMu.Lock()
+					errs.add(o.index, o.obj.ID(), err)
+					errsMu.Unlock()
+				}
+			}
+		}()
+	}
+	index := 0
+loop:
+	for ctx.Err() == nil && next() {
+		select {
+		case objs <- indexedObject{index, it.Get()}:
+			index++
+		case <-ctx.Done():
+			break loop
+		}
+	}
+	close(objs)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	return errs.errorOrNil()
 }
 
 // Meta returns the metadata returned by the server during the last call to
 // the collection's endpoint.
 func (it *Iterator) Meta() map[string]interface{} {
+	it.metaMu.Lock()
+	defer it.metaMu.Unlock()
 	return it.meta
 }
 
+// Count returns the total number of objects in the collection, as reported by
+// the server in the collection's metadata. This is useful for displaying
+// progress or pre-allocating storage. If the first page of the collection has
+// not been retrieved yet this function waits for it. Not all collections
+// report the number of objects, an error is returned in that case.
+func (it *Iterator) Count() (int64, error) {
+	<-it.firstPage
+	return Meta(it.Meta()).Count()
+}
+
 // Error returns any error occurred during the iteration of a collection.
 func (it *Iterator) Error() error {
 	return it.err
@@ -251,11 +802,21 @@ func (it *Iterator) sendToChannel(item interface{}) int {
 	return ok
 }
 
-func (it *Iterator) getMoreObjects() (objs []*Object, err error) {
+// getMoreObjects retrieves the next page of the collection. If maxObjects is
+// greater than zero, the page's size is reduced so that it contains at most
+// maxObjects objects.
+func (it *Iterator) getMoreObjects(maxObjects int) (objs []*Object, err error) {
 	nextURL, err := url.Parse(it.links.Next)
 	if err != nil {
 		return nil, err
 	}
+	if maxObjects > 0 {
+		q := nextURL.Query()
+		if limit, err := strconv.Atoi(q.Get("limit")); err == nil && limit > maxObjects {
+			q.Set("limit", strconv.Itoa(maxObjects))
+			nextURL.RawQuery = q.Encode()
+		}
+	}
 	var resp *Response
 	var data json.RawMessage
 	if resp, err = it.client.GetData(nextURL, &data); err != nil {
@@ -271,22 +832,93 @@ func (it *Iterator) getMoreObjects() (objs []*Object, err error) {
 		return nil, err
 	}
 	it.links = resp.Links
+	it.metaMu.Lock()
 	it.meta = resp.Meta
+	it.metaMu.Unlock()
 	return objs, nil
 }
 
+// isTransientError returns true if err is an error that may not occur again if
+// the request is sent again. Errors returned by the HTTP client are *url.Error,
+// but so are errors parsing URLs, which don't go away by retrying.
+func isTransientError(err error) bool {
+	var urlErr *url.Error
+	return IsRetryableError(err) || errors.As(err, &urlErr) && urlErr.Op != "parse"
+}
+
+// getMoreObjectsWithRetry is like getMoreObjects, but retries the request
+// after transient errors as specified with IteratorRetry. The returned bool is
+// false if the iterator was closed while waiting for retrying.
+func (it *Iterator) getMoreObjectsWithRetry(maxObjects int) ([]*Object, bool, error) {
+	delay := it.retryDelay
+	for attempt := 0; ; attempt++ {
+		objs, err := it.getMoreObjects(maxObjects)
+		if err == nil || attempt == it.retries || !isTransientError(err) {
+			return objs, true, err
+		}
+		select {
+		case <-it.done:
+			return nil, false, err
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// missingAttribute returns the first attribute in requiredAttributes that is
+// not present in the given object, or an empty string if the object has all
+// of them.
+func (it *Iterator) missingAttribute(obj *Object) string {
+	for _, attr := range it.requiredAttributes {
+		if !obj.hasAttribute(attr) {
+			return attr
+		}
+	}
+	return ""
+}
+
+// firstPageDone signals that the first page has been retrieved, it can be
+// called multiple times.
+func (it *Iterator) firstPageDone() {
+	select {
+	case <-it.firstPage:
+	default:
+		close(it.firstPage)
+	}
+}
+
 func (it *Iterator) iterate(skip int) {
 	sent := 0
 loop:
 	for it.limit == 0 || sent < it.limit {
-		// Send request to the API to get more objects.
-		objects, err := it.getMoreObjects()
-		if err != nil {
-			// If an error occurred send it through the channel
-			if it.sendToChannel(err) == stop {
+		// Send request to the API to get more objects. When the iterator has
+		// a limit there's no need to retrieve more objects than those required
+		// for reaching it, plus those that will be skipped.
+		maxObjects := 0
+		if it.limit > 0 {
+			maxObjects = it.limit - sent + skip
+		}
+		if it.pages != nil {
+			select {
+			case it.pages <- struct{}{}:
+			case <-it.done:
 				break loop
 			}
 		}
+		pageCursor := ""
+		if u, err := url.Parse(it.links.Next); err == nil {
+			pageCursor = u.Query().Get("cursor")
+		}
+		objects, running, err := it.getMoreObjectsWithRetry(maxObjects)
+		it.firstPageDone()
+		if !running {
+			break loop
+		}
+		if err != nil {
+			// If an error occurred send it through the channel
+			it.sendToChannel(err)
+			break loop
+		}
 
 		objects = objects[skip:]
 		for i, object := range objects {
@@ -294,9 +926,19 @@ loop:
 			if i == len(objects)-1 {
 				co.cursor.Link = it.links.Next
 				co.cursor.Offset = 0
+				co.nativeCursor = Meta(it.Meta()).Cursor()
 			} else {
 				co.cursor.Link = it.links.Self
 				co.cursor.Offset = skip + i + 1
+				co.nativeCursor = pageCursor
+			}
+			if attr := it.missingAttribute(object); attr != "" {
+				if !it.failOnMissingAttributes {
+					continue
+				}
+				it.sendToChannel(fmt.Errorf(
+					"object \"%s\" lacks required attribute \"%s\"", object.ID(), attr))
+				break loop
 			}
 			if it.sendToChannel(co) == stop {
 				break loop
@@ -304,13 +946,17 @@ loop:
 			sent++
 		}
 
+		if it.pages != nil && it.sendToChannel(pageEnd{}) == stop {
+			break loop
+		}
+
 		if len(objects) == 0 || it.links.Next == "" {
 			break loop
 		}
 
 		skip = 0
 	}
-	it.closed = true
+	it.firstPageDone()
 	close(it.ch)
-	close(it.done)
+	close(it.finished)
 }
@@ -0,0 +1,48 @@
+// Copyright © 2017 The vt-go authors. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.23
+
+package vt
+
+import "iter"
+
+// Seq returns an iterator over the objects in the collection that can be used
+// with range-over-func loops. Each iteration yields an object and a nil error,
+// except if an error occurs while retrieving the objects, in which case it
+// yields a nil object and the error, and the iteration ends. The Iterator is
+// closed when the loop finishes, even if it's exited early. Example:
+//
+//	it, err := client.Iterator(vt.URL("intelligence/hunting_notifications"))
+//	if err != nil {
+//		...handle error
+//	}
+//	for obj, err := range it.Seq() {
+//		if err != nil {
+//			...handle error
+//		}
+//		fmt.Println(obj.ID())
+//	}
+func (it *Iterator) Seq() iter.Seq2[*Object, error] {
+	return func(yield func(*Object, error) bool) {
+		defer it.Close()
+		for it.Next() {
+			if !yield(it.Get(), nil) {
+				return
+			}
+		}
+		if err := it.Error(); err != nil {
+			yield(nil, err)
+		}
+	}
+}
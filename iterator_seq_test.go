@@ -0,0 +1,37 @@
+//go:build go1.23
+
+package vt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIteratorSeq(t *testing.T) {
+	ts := newCollectionTestServer(t, 5, 2)
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+	it, err := c.Iterator(URL("collection"))
+	assert.NoError(t, err)
+
+	n := 0
+	for obj, err := range it.Seq() {
+		assert.NoError(t, err)
+		assert.Equal(t, int64(n), obj.MustGetInt64("index"))
+		n++
+	}
+	assert.Equal(t, 5, n)
+
+	// Exiting the loop early closes the iterator.
+	it, err = c.Iterator(URL("collection"))
+	assert.NoError(t, err)
+	for obj := range it.Seq() {
+		if obj.MustGetInt64("index") == 2 {
+			break
+		}
+	}
+	<-it.finished
+}
@@ -31,8 +31,9 @@ type MonitorUploader struct {
 // The function also sends a float32 through the progress channel indicating the
 // percentage of the file that has been already uploaded. The progress channel
 // can be nil if the caller is not interested in receiving upload progress
-// updates. The received object is returned as soon as the file is uploaded.
-func (s *MonitorUploader) upload(r io.Reader, params map[string]string, progress chan<- float32) (*Object, error) {
+// updates, onProgress is an alternative to the channel and can be nil too. The
+// received object is returned as soon as the file is uploaded.
+func (s *MonitorUploader) upload(r io.Reader, params map[string]string, progress chan<- float32, onProgress func(pct float32)) (*Object, error) {
 	var uploadURL *url.URL
 	var payloadSize int64
 
@@ -58,7 +59,7 @@ func (s *MonitorUploader) upload(r io.Reader, params map[string]string, progress
 
 	w.Close()
 
-	if payloadSize > maxPayloadSize {
+	if payloadSize > s.cli.maxPayloadSize {
 		// Payload is bigger than supported by AppEngine in a POST request,
 		// let's ask for an upload URL.
 		var u string
@@ -75,7 +76,8 @@ func (s *MonitorUploader) upload(r io.Reader, params map[string]string, progress
 	pr := &progressReader{
 		reader:     &b,
 		total:      int64(b.Len()),
-		progressCh: progress}
+		progressCh: progress,
+		onProgress: onProgress}
 
 	headers := map[string]string{"Content-Type": w.FormDataContentType()}
 
@@ -107,7 +109,7 @@ func (s *MonitorUploader) upload(r io.Reader, params map[string]string, progress
 // updates. The received object is returned as soon as the file is uploaded.
 func (s *MonitorUploader) Upload(r io.Reader, monitorPath string, progress chan<- float32) (*Object, error) {
 	params := map[string]string{"path": monitorPath}
-	return s.upload(r, params, progress)
+	return s.upload(r, params, progress, nil)
 }
 
 // Replace modifies the contents of Monitor file identified by its
@@ -120,5 +122,5 @@ func (s *MonitorUploader) Upload(r io.Reader, monitorPath string, progress chan<
 // The received object is returned as soon as the file is uploaded.
 func (s *MonitorUploader) Replace(r io.Reader, monitorItemID string, progress chan<- float32) (*Object, error) {
 	params := map[string]string{"item": monitorItemID}
-	return s.upload(r, params, progress)
+	return s.upload(r, params, progress, nil)
 }
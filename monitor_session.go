@@ -0,0 +1,213 @@
+// Copyright © 2019 The vt-go authors. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vt
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// MonitorUploadItem is a local file that must be uploaded to VT Monitor as
+// part of a MonitorUploadSession.
+type MonitorUploadItem struct {
+	// Path of the file in the local file system.
+	LocalPath string
+	// Destination path of the file in VT Monitor.
+	MonitorPath string
+}
+
+// MonitorUploadResult contains the result of uploading a MonitorUploadItem.
+type MonitorUploadResult struct {
+	Item MonitorUploadItem
+	// Object returned by VT Monitor for the uploaded file. It's nil if the
+	// upload failed or the file was skipped.
+	Object *Object
+	// Skipped is true if the file was not uploaded because the manifest
+	// indicates that it was uploaded by a previous session.
+	Skipped bool
+	// Number of attempts made for uploading the file.
+	Attempts int
+	// Err is the error occurred in the last attempt, if the upload failed.
+	Err error
+}
+
+// monitorManifest is the structure stored in the manifest file, it maps the
+// Monitor paths for the files already uploaded to the ID of the corresponding
+// Monitor items.
+type monitorManifest struct {
+	Uploaded map[string]string `json:"uploaded"`
+}
+
+// MonitorUploadSession uploads multiple files to VT Monitor, retrying the
+// failed uploads and, optionally, keeping track of the uploaded files in a
+// manifest file so that an interrupted session can be resumed later.
+type MonitorUploadSession struct {
+	uploader     *MonitorUploader
+	manifestPath string
+	manifest     monitorManifest
+	maxRetries   int
+	retryDelay   time.Duration
+	onProgress   func(item MonitorUploadItem, percent float32)
+}
+
+// MonitorUploadSessionOption represents an option passed to NewSession.
+type MonitorUploadSessionOption func(*MonitorUploadSession)
+
+// MonitorSessionManifest specifies the path of a manifest file where the
+// session records the files that has been uploaded successfully. If the file
+// already exists when the session is created, the files recorded in it are
+// not uploaded again, which allows resuming an interrupted session.
+func MonitorSessionManifest(path string) MonitorUploadSessionOption {
+	return func(s *MonitorUploadSession) {
+		s.manifestPath = path
+	}
+}
+
+// MonitorSessionRetries specifies the number of times that an upload failed
+// with a transient error is retried, and the delay between retries, see
+// BulkLookupRetries for how retries are spaced.
+func MonitorSessionRetries(n int, delay time.Duration) MonitorUploadSessionOption {
+	return func(s *MonitorUploadSession) {
+		s.maxRetries = n
+		s.retryDelay = delay
+	}
+}
+
+// MonitorSessionProgress specifies a function that is called with the
+// percentage of each file that has been already uploaded.
+func MonitorSessionProgress(f func(item MonitorUploadItem, percent float32)) MonitorUploadSessionOption {
+	return func(s *MonitorUploadSession) {
+		s.onProgress = f
+	}
+}
+
+// NewSession returns a new MonitorUploadSession. If a manifest is specified
+// with MonitorSessionManifest and the file exists, it's loaded for resuming a
+// previous session.
+func (s *MonitorUploader) NewSession(options ...MonitorUploadSessionOption) (*MonitorUploadSession, error) {
+	session := &MonitorUploadSession{
+		uploader:   s,
+		manifest:   monitorManifest{Uploaded: make(map[string]string)},
+		maxRetries: 3,
+		retryDelay: 5 * time.Second,
+	}
+	for _, opt := range options {
+		opt(session)
+	}
+	if session.manifestPath != "" {
+		b, err := ioutil.ReadFile(session.manifestPath)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		if err == nil {
+			if err := json.Unmarshal(b, &session.manifest); err != nil {
+				return nil, err
+			}
+			if session.manifest.Uploaded == nil {
+				session.manifest.Uploaded = make(map[string]string)
+			}
+		}
+	}
+	return session, nil
+}
+
+// Uploaded returns true if the file with the given Monitor path was already
+// uploaded by this session or by a previous one recorded in the manifest.
+func (s *MonitorUploadSession) Uploaded(monitorPath string) bool {
+	_, uploaded := s.manifest.Uploaded[monitorPath]
+	return uploaded
+}
+
+// saveManifest writes the manifest to a temporary file that is renamed
+// afterwards, so that the manifest is not corrupted if the program is
+// interrupted while writing it.
+func (s *MonitorUploadSession) saveManifest() error {
+	if s.manifestPath == "" {
+		return nil
+	}
+	b, err := json.Marshal(&s.manifest)
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(s.manifestPath), ".manifest")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.manifestPath)
+}
+
+func (s *MonitorUploadSession) uploadFile(item MonitorUploadItem) (*Object, error) {
+	f, err := os.Open(item.LocalPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var onProgress func(pct float32)
+	if s.onProgress != nil {
+		onProgress = func(pct float32) { s.onProgress(item, pct) }
+	}
+	params := map[string]string{"path": item.MonitorPath}
+	return s.uploader.upload(f, params, nil, onProgress)
+}
+
+// Upload uploads the given files to VT Monitor, one after the other. Uploads
+// that fail with transient errors, like network errors or retryable server
+// errors, are retried as specified by MonitorSessionRetries. Files that were
+// already uploaded according to the manifest are skipped. The result for each
+// file is returned in the same order than the items. The returned error is
+// non-nil only if the manifest couldn't be written, or if the context is done
+// before uploading all the files, in which case the remaining files are not
+// uploaded. Errors occurred while uploading individual files are reported in
+// the results.
+func (s *MonitorUploadSession) Upload(ctx context.Context, items []MonitorUploadItem) ([]MonitorUploadResult, error) {
+	results := make([]MonitorUploadResult, len(items))
+	for i, item := range items {
+		results[i].Item = item
+		if s.Uploaded(item.MonitorPath) {
+			results[i].Skipped = true
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		results[i].Attempts, results[i].Err = retryTransient(ctx, nil, s.maxRetries, s.retryDelay, func() (err error) {
+			results[i].Object, err = s.uploadFile(item)
+			return err
+		})
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		if results[i].Err != nil {
+			continue
+		}
+		s.manifest.Uploaded[item.MonitorPath] = results[i].Object.ID()
+		if err := s.saveManifest(); err != nil {
+			return results, err
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,119 @@
+package vt
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMonitorUploadSession(t *testing.T) {
+	var requests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		// The first request fails, so that the upload is retried.
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"error": {"code": "TransientError", "message": "try again"}}`))
+			return
+		}
+		r.ParseMultipartForm(1024)
+		w.Write([]byte(`{"data": {"type": "monitor_item", "id": "` + r.FormValue("path") + `"}}`))
+	}))
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "monitor_session")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	items := []MonitorUploadItem{}
+	for _, name := range []string{"a.txt", "b.txt"} {
+		p := filepath.Join(dir, name)
+		assert.NoError(t, ioutil.WriteFile(p, []byte(name), 0644))
+		items = append(items, MonitorUploadItem{LocalPath: p, MonitorPath: "/" + name})
+	}
+	items = append(items, MonitorUploadItem{
+		LocalPath:   filepath.Join(dir, "missing.txt"),
+		MonitorPath: "/missing.txt"})
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+	manifest := filepath.Join(dir, "manifest.json")
+	var progressCalls int32
+
+	session, err := c.NewMonitorUploader().NewSession(
+		MonitorSessionManifest(manifest),
+		MonitorSessionRetries(1, time.Millisecond),
+		MonitorSessionProgress(func(item MonitorUploadItem, percent float32) {
+			atomic.AddInt32(&progressCalls, 1)
+		}))
+	assert.NoError(t, err)
+
+	results, err := session.Upload(context.Background(), items)
+	assert.NoError(t, err)
+	assert.Len(t, results, 3)
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, 2, results[0].Attempts)
+	assert.Equal(t, "/a.txt", results[0].Object.ID())
+	assert.NoError(t, results[1].Err)
+	assert.Equal(t, 1, results[1].Attempts)
+	// A missing file is not a transient error, it's not retried.
+	assert.Error(t, results[2].Err)
+	assert.Equal(t, 1, results[2].Attempts)
+	assert.True(t, atomic.LoadInt32(&progressCalls) > 0)
+
+	// A new session using the same manifest skips the files already uploaded.
+	session, err = c.NewMonitorUploader().NewSession(
+		MonitorSessionManifest(manifest),
+		MonitorSessionRetries(0, 0))
+	assert.NoError(t, err)
+	assert.True(t, session.Uploaded("/a.txt"))
+
+	results, err = session.Upload(context.Background(), items)
+	assert.NoError(t, err)
+	assert.True(t, results[0].Skipped)
+	assert.True(t, results[1].Skipped)
+	assert.False(t, results[2].Skipped)
+	assert.Error(t, results[2].Err)
+}
+
+func TestMonitorUploadSessionCancel(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"error": {"code": "TransientError", "message": "try again"}}`))
+	}))
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "monitor_session")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	items := []MonitorUploadItem{}
+	for _, name := range []string{"a.txt", "b.txt"} {
+		p := filepath.Join(dir, name)
+		assert.NoError(t, ioutil.WriteFile(p, []byte(name), 0644))
+		items = append(items, MonitorUploadItem{LocalPath: p, MonitorPath: "/" + name})
+	}
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+	session, err := c.NewMonitorUploader().NewSession(MonitorSessionRetries(3, time.Hour))
+	assert.NoError(t, err)
+
+	// The context is done while waiting for retrying the first file.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	results, err := session.Upload(ctx, items)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Equal(t, 1, results[0].Attempts)
+	assert.Equal(t, context.DeadlineExceeded, results[0].Err)
+	assert.Equal(t, 0, results[1].Attempts)
+}
@@ -0,0 +1,115 @@
+// Copyright © 2017 The vt-go authors. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vt
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ItemError is an error occurred while processing a single item in a batch
+// operation. It identifies the item by its index in the batch and its ID,
+// which can be a hash, a URL, a file path or whatever identifies the items
+// in the operation.
+type ItemError struct {
+	Index int
+	ID    string
+	Err   error
+}
+
+// Error implements the error interface.
+func (e *ItemError) Error() string {
+	return fmt.Sprintf("%s: %s", e.ID, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ItemError) Unwrap() error {
+	return e.Err
+}
+
+// MultiError is returned by batch operations when some of the items failed.
+// It contains an ItemError for each failed item, so that callers can retry
+// only the failed subset. MultiError implements Unwrap() []error, which
+// makes errors.Is and errors.As work with the underlying errors.
+type MultiError struct {
+	Errors []*ItemError
+}
+
+// Error implements the error interface.
+func (m *MultiError) Error() string {
+	if len(m.Errors) == 1 {
+		return m.Errors[0].Error()
+	}
+	msgs := make([]string, len(m.Errors))
+	for i, e := range m.Errors {
+		msgs[i] = e.Error()
+	}
+	return fmt.Sprintf("%d errors occurred: %s", len(m.Errors), strings.Join(msgs, "; "))
+}
+
+// Unwrap returns the errors for the individual items.
+func (m *MultiError) Unwrap() []error {
+	errs := make([]error, len(m.Errors))
+	for i, e := range m.Errors {
+		errs[i] = e
+	}
+	return errs
+}
+
+// Indexes returns the indexes of the failed items, in ascending order.
+func (m *MultiError) Indexes() []int {
+	indexes := make([]int, len(m.Errors))
+	for i, e := range m.Errors {
+		indexes[i] = e.Index
+	}
+	sort.Ints(indexes)
+	return indexes
+}
+
+// IDs returns the IDs of the failed items, in the same order than Indexes.
+func (m *MultiError) IDs() []string {
+	errs := append([]*ItemError(nil), m.Errors...)
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Index < errs[j].Index })
+	ids := make([]string, len(errs))
+	for i, e := range errs {
+		ids[i] = e.ID
+	}
+	return ids
+}
+
+// Get returns the error for the item with the given index, or nil if that
+// item didn't fail.
+func (m *MultiError) Get(index int) error {
+	for _, e := range m.Errors {
+		if e.Index == index {
+			return e.Err
+		}
+	}
+	return nil
+}
+
+// add records an error for an item.
+func (m *MultiError) add(index int, id string, err error) {
+	m.Errors = append(m.Errors, &ItemError{Index: index, ID: id, Err: err})
+}
+
+// errorOrNil returns the MultiError as an error if some item failed, or nil
+// if no item failed.
+func (m *MultiError) errorOrNil() error {
+	if len(m.Errors) == 0 {
+		return nil
+	}
+	return m
+}
@@ -0,0 +1,30 @@
+package vt
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMultiError(t *testing.T) {
+	m := &MultiError{}
+	assert.NoError(t, m.errorOrNil())
+
+	notFound := Error{Code: "NotFoundError", Message: "not found"}
+	m.add(3, "hash3", notFound)
+	m.add(1, "hash1", errors.New("timeout"))
+
+	err := m.errorOrNil()
+	assert.Error(t, err)
+	assert.Equal(t, []int{1, 3}, m.Indexes())
+	assert.Equal(t, []string{"hash1", "hash3"}, m.IDs())
+	assert.Equal(t, notFound, m.Get(3))
+	assert.Nil(t, m.Get(2))
+	assert.Equal(t, "2 errors occurred: hash3: not found; hash1: timeout", err.Error())
+
+	var apiErr Error
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, "NotFoundError", apiErr.Code)
+	assert.True(t, errors.Is(err, notFound))
+}
@@ -0,0 +1,128 @@
+// Copyright © 2017 The vt-go authors. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vt
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// MultiFeedItem is an object received by a MultiFeed, along with the type of
+// the feed it comes from.
+type MultiFeedItem struct {
+	FeedType FeedType
+	Object   *Object
+}
+
+// MultiFeed consumes several feeds concurrently and multiplexes their objects
+// into a single channel C. Objects from the same feed are received in order,
+// but objects from different feeds are interleaved as they arrive.
+type MultiFeed struct {
+	C     chan MultiFeedItem
+	types []FeedType
+	feeds map[FeedType]*Feed
+}
+
+// NewMultiFeed creates a MultiFeed that receives objects from feeds of the
+// given types, the options are applied to each of the feeds. Options that
+// refer to a single feed, like FeedPersistCursor, must not be used here, use
+// the feeds returned by MultiFeed.Feed instead. Example:
+//
+//	mf, err := client.NewMultiFeed([]vt.FeedType{vt.FileFeed, vt.URLFeed})
+//	if err != nil {
+//		...handle error
+//	}
+//	for item := range mf.C {
+//		...do something with item.Object according to item.FeedType
+//	}
+//	if err := mf.Error(); err != nil {
+//		...some feed has been stopped by an error
+//	}
+func (cli *Client) NewMultiFeed(types []FeedType, options ...FeedOption) (*MultiFeed, error) {
+	mf := &MultiFeed{
+		C:     make(chan MultiFeedItem, 1000),
+		types: types,
+		feeds: make(map[FeedType]*Feed, len(types)),
+	}
+	for _, t := range types {
+		if _, ok := mf.feeds[t]; ok {
+			mf.Stop()
+			return nil, fmt.Errorf("duplicate feed type \"%s\"", t)
+		}
+		f, err := cli.NewFeed(t, options...)
+		if err != nil {
+			mf.Stop()
+			return nil, err
+		}
+		if f.Packages != nil || f.Items != nil {
+			f.Stop()
+			mf.Stop()
+			return nil, errors.New("FeedPackages and FeedItems can't be used with MultiFeed")
+		}
+		mf.feeds[t] = f
+	}
+	var wg sync.WaitGroup
+	for t, f := range mf.feeds {
+		wg.Add(1)
+		go func(t FeedType, f *Feed) {
+			defer wg.Done()
+			for obj := range f.C {
+				mf.C <- MultiFeedItem{FeedType: t, Object: obj}
+			}
+		}(t, f)
+	}
+	go func() {
+		wg.Wait()
+		close(mf.C)
+	}()
+	return mf, nil
+}
+
+// Feed returns the underlying feed for the given type, or nil if the
+// MultiFeed doesn't include it. This is useful for obtaining the cursor and
+// stats of each feed.
+func (mf *MultiFeed) Feed(t FeedType) *Feed {
+	return mf.feeds[t]
+}
+
+// Cursors returns the cursor of each feed.
+func (mf *MultiFeed) Cursors() map[FeedType]string {
+	cursors := make(map[FeedType]string, len(mf.feeds))
+	for t, f := range mf.feeds {
+		cursors[t] = f.Cursor()
+	}
+	return cursors
+}
+
+// Stop stops all the feeds, channel C is closed once all the objects buffered
+// by them have been received.
+func (mf *MultiFeed) Stop() error {
+	for _, f := range mf.feeds {
+		f.Stop()
+	}
+	return nil
+}
+
+// Error returns a *MultiError with the errors that stopped any of the feeds,
+// where the ID of each item is the feed type, or nil if no feed failed.
+func (mf *MultiFeed) Error() error {
+	errs := &MultiError{}
+	for i, t := range mf.types {
+		if err := mf.feeds[t].Error(); err != nil {
+			errs.add(i, string(t), err)
+		}
+	}
+	return errs.errorOrNil()
+}
@@ -0,0 +1,42 @@
+package vt
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMultiFeed(t *testing.T) {
+	ts := newFeedTestServer(t, map[string]string{
+		"202001011200": "testdata/feed_package.bz2",
+	}, "")
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+	mf, err := c.NewMultiFeed([]FeedType{FileFeed, URLFeed}, FeedCursor("202001011200"))
+	assert.NoError(t, err)
+	counts := map[FeedType]int{}
+	for item := range mf.C {
+		counts[item.FeedType]++
+	}
+	assert.Equal(t, map[FeedType]int{FileFeed: 2, URLFeed: 2}, counts)
+	assert.Equal(t, map[FeedType]string{
+		FileFeed: "202001011202-0",
+		URLFeed:  "202001011202-0",
+	}, mf.Cursors())
+	assert.NotNil(t, mf.Feed(URLFeed))
+	assert.Nil(t, mf.Feed(DomainFeed))
+
+	// Both feeds stopped after two missing packages.
+	var multiErr *MultiError
+	assert.True(t, errors.As(mf.Error(), &multiErr))
+	assert.Len(t, multiErr.Errors, 2)
+	assert.Equal(t, "urls", multiErr.Errors[1].ID)
+
+	_, err = c.NewMultiFeed([]FeedType{FileFeed, FileFeed})
+	assert.Error(t, err)
+	_, err = c.NewMultiFeed([]FeedType{FileFeed}, FeedPackages(true))
+	assert.Error(t, err)
+}
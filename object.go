@@ -17,6 +17,9 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	gojsonq "github.com/thedevsaddam/gojsonq/v2"
@@ -45,14 +48,32 @@ type Object struct {
 	// any of the SetXX methods.
 	modifiedAttributes []string
 
+	// Contains a list of the context attributes that have been modified via
+	// a call to any of the SetContextXX methods.
+	modifiedContextAttributes []string
+
 	// Contains a map with additional data fields added to the object.
 	modifiedData map[string]interface{}
+
+	// Contains a list of the relationships that have been set via a call to
+	// SetRelationship.
+	modifiedRelationships []string
+
+	// Mutex used for making the object safe for concurrent use, it's nil
+	// unless MakeConcurrencySafe is called.
+	mu *sync.RWMutex
+
+	// Problems found while unmarshalling the object in UnmarshalWarn mode.
+	warnings []string
 }
 
 // Links contains links related to an API object.
 type Links struct {
 	Self string `json:"self,omitempty"`
 	Next string `json:"next,omitempty"`
+	// Related is included in relationships, and points to the collection
+	// with the full related objects.
+	Related string `json:"related,omitempty"`
 }
 
 // NewObject creates a new object.
@@ -70,6 +91,103 @@ func NewObjectWithID(objType, id string) *Object {
 		Attributes: make(map[string]interface{})}}
 }
 
+// MakeConcurrencySafe makes the object safe for concurrent use by multiple
+// goroutines. By default objects are not safe for concurrent use, as most
+// of the time they are accessed from a single goroutine and locking has a
+// cost. Objects shared by multiple goroutines must call this method before
+// sharing the object. Notice that values returned by methods like Query or
+// GetObjectSlice can reference the object's internal data, and accessing them
+// is not protected. For sharing an immutable snapshot of the object use Copy
+// instead.
+func (obj *Object) MakeConcurrencySafe() {
+	if obj.mu == nil {
+		obj.mu = &sync.RWMutex{}
+	}
+}
+
+func (obj *Object) lock() {
+	if obj.mu != nil {
+		obj.mu.Lock()
+	}
+}
+
+func (obj *Object) unlock() {
+	if obj.mu != nil {
+		obj.mu.Unlock()
+	}
+}
+
+func (obj *Object) rlock() {
+	if obj.mu != nil {
+		obj.mu.RLock()
+	}
+}
+
+func (obj *Object) runlock() {
+	if obj.mu != nil {
+		obj.mu.RUnlock()
+	}
+}
+
+// Copy returns a deep copy of the object. The copy doesn't share any data
+// with the original object, so it can be used as a snapshot that is not
+// affected by further modifications to the original object. The copy is not
+// safe for concurrent use unless MakeConcurrencySafe is called on it.
+func (obj *Object) Copy() *Object {
+	obj.rlock()
+	defer obj.runlock()
+	c := &Object{data: obj.data}
+	c.data.Attributes = deepCopy(obj.data.Attributes).(map[string]interface{})
+	c.data.ContextAttributes, _ = deepCopy(obj.data.ContextAttributes).(map[string]interface{})
+	if obj.data.Links != nil {
+		links := *obj.data.Links
+		c.data.Links = &links
+	}
+	if obj.data.Relationships != nil {
+		c.data.Relationships = make(map[string]*relationshipData, len(obj.data.Relationships))
+		for name, r := range obj.data.Relationships {
+			rc := *r
+			rc.Objects = make([]*Object, len(r.Objects))
+			for i, o := range r.Objects {
+				rc.Objects[i] = o.Copy()
+			}
+			c.data.Relationships[name] = &rc
+		}
+	}
+	c.modifiedAttributes = append([]string(nil), obj.modifiedAttributes...)
+	c.modifiedContextAttributes = append([]string(nil), obj.modifiedContextAttributes...)
+	c.modifiedData, _ = deepCopy(obj.modifiedData).(map[string]interface{})
+	c.modifiedRelationships = append([]string(nil), obj.modifiedRelationships...)
+	c.warnings = append([]string(nil), obj.warnings...)
+	return c
+}
+
+// deepCopy returns a copy of a value produced by the JSON decoder, maps and
+// slices are copied recursively.
+func deepCopy(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		if value == nil {
+			return value
+		}
+		m := make(map[string]interface{}, len(value))
+		for key, item := range value {
+			m[key] = deepCopy(item)
+		}
+		return m
+	case []interface{}:
+		if value == nil {
+			return value
+		}
+		s := make([]interface{}, len(value))
+		for i, item := range value {
+			s[i] = deepCopy(item)
+		}
+		return s
+	}
+	return v
+}
+
 // ID returns the object's identifier.
 func (obj *Object) ID() string {
 	return obj.data.ID
@@ -82,6 +200,8 @@ func (obj *Object) Type() string {
 
 // Attributes returns a list with the names of the object's attributes.
 func (obj *Object) Attributes() []string {
+	obj.rlock()
+	defer obj.runlock()
 	result := make([]string, len(obj.data.Attributes))
 	i := 0
 	for attr := range obj.data.Attributes {
@@ -97,6 +217,8 @@ func (obj *Object) Attributes() []string {
 // are part of a relationship, the objects may have attributes that only make
 // sense in the context of that relationship.
 func (obj *Object) ContextAttributes() []string {
+	obj.rlock()
+	defer obj.runlock()
 	result := make([]string, len(obj.data.ContextAttributes))
 	i := 0
 	for attr := range obj.data.ContextAttributes {
@@ -108,6 +230,8 @@ func (obj *Object) ContextAttributes() []string {
 
 // Relationships returns a list with the names of the object's relationships.
 func (obj *Object) Relationships() []string {
+	obj.rlock()
+	defer obj.runlock()
 	result := make([]string, len(obj.data.Relationships))
 	i := 0
 	for rel := range obj.data.Relationships {
@@ -124,12 +248,22 @@ func (obj *Object) Links() *Links {
 
 // MarshalJSON marshals a VirusTotal API object.
 func (obj *Object) MarshalJSON() ([]byte, error) {
+	obj.rlock()
+	defer obj.runlock()
 	return json.Marshal(obj.data)
 }
 
 // UnmarshalJSON unmarshals a VirusTotal API object from data.
 func (obj *Object) UnmarshalJSON(data []byte) error {
 
+	var problems []string
+	if mode := getUnmarshalMode(); mode != UnmarshalLenient {
+		problems = checkObjectPayload(data)
+		if mode == UnmarshalStrict && len(problems) > 0 {
+			return unmarshalError(problems)
+		}
+	}
+
 	decoder := json.NewDecoder(bytes.NewReader(data))
 	decoder.UseNumber()
 
@@ -139,8 +273,18 @@ func (obj *Object) UnmarshalJSON(data []byte) error {
 	}
 
 	obj.data = od
+	obj.warnings = problems
 
 	for _, v := range obj.data.Relationships {
+		// A null value corresponds to an empty one-to-one relationship.
+		if bytes.Equal(bytes.TrimSpace(v.Data), []byte("null")) {
+			v.IsOneToOne = true
+			continue
+		}
+		// Relationships can include only counters, without any data.
+		if len(v.Data) == 0 {
+			continue
+		}
 		var o Object
 		// Try unmarshalling as an Object first, if it fails this is a
 		// one-to-many relationship, so we try unmarshalling as an array.
@@ -163,6 +307,8 @@ func (obj *Object) UnmarshalJSON(data []byte) error {
 }
 
 func (obj *Object) getContextAttributeNumber(name string) (n json.Number, err error) {
+	obj.rlock()
+	defer obj.runlock()
 	if attrValue, attrExists := obj.data.ContextAttributes[name]; attrExists {
 		n, isNumber := attrValue.(json.Number)
 		if !isNumber {
@@ -211,6 +357,10 @@ func (obj *Object) getJsonQ() (*gojsonq.JSONQ, error) {
 // attributes will be of type json.Number, use GetInt64 or GetFloat64 if you
 // want one the result as an integer or float number.
 func (obj *Object) Get(attr string) (interface{}, error) {
+	// The JSONQ object is modified on every query, so this requires an
+	// exclusive lock.
+	obj.lock()
+	defer obj.unlock()
 	v, err := obj.getJsonQ()
 	if err != nil {
 		return nil, err
@@ -222,6 +372,56 @@ func (obj *Object) Get(attr string) (interface{}, error) {
 	return results, nil
 }
 
+// hasAttribute returns true if the object has the given attribute. Like in
+// Get, the attribute name can include dots for referring to nested attributes.
+func (obj *Object) hasAttribute(attr string) bool {
+	obj.rlock()
+	_, exists := obj.data.Attributes[attr]
+	obj.runlock()
+	if exists {
+		return true
+	}
+	if !strings.Contains(attr, ".") {
+		return false
+	}
+	_, err := obj.Get(attr)
+	return err == nil
+}
+
+// Query returns all the values that match a path that can contain wildcards.
+// A "*" in the path matches every value in a map or every item in a slice.
+// For example, "last_analysis_results.*.category" returns the category
+// returned by each engine, while "pe_info.sections.*.name" returns the names
+// of all the sections in a PE file. Values matched in maps are returned in
+// order of their keys. An empty result is returned if nothing matches.
+func (obj *Object) Query(path string) ([]interface{}, error) {
+	p, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	obj.rlock()
+	defer obj.runlock()
+	return queryPath(obj.data.Attributes, p), nil
+}
+
+// decodeAttribute decodes the value of an attribute into target, which must
+// be a pointer to some type where the attribute's value can be unmarshalled,
+// typically a struct with the appropriate JSON tags.
+func (obj *Object) decodeAttribute(attr string, target interface{}) error {
+	value, err := obj.Get(attr)
+	if err != nil {
+		return err
+	}
+	if value == nil {
+		return fmt.Errorf("attribute \"%s\" does not exists", attr)
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, target)
+}
+
 // GetInt64 returns an attribute as an int64. It returns the attribute's
 // value or an error if the attribute doesn't exist or is not a number.
 func (obj *Object) GetInt64(attr string) (int64, error) {
@@ -291,19 +491,72 @@ func (obj *Object) MustGetString(attr string) string {
 	return result
 }
 
+// Layouts accepted by GetTime for attributes containing dates as strings.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	time.RFC1123Z,
+	time.RFC1123,
+}
+
 // GetTime returns an attribute as a time. It returns the attribute's
-// value or an error if the attribute doesn't exist or is not a time.
+// value or an error if the attribute doesn't exist or is not a time. Most
+// dates are returned by the API as Unix timestamps, but some attributes
+// contain dates as strings, like RFC 3339 timestamps or "YYYY-MM-DD" dates,
+// those are parsed too. Dates without time zone are interpreted as UTC.
 func (obj *Object) GetTime(attr string) (t time.Time, err error) {
+	return obj.getTime(attr, nil)
+}
+
+// GetTimeInLocation is like GetTime, but dates without time zone are
+// interpreted in the given location, and the returned time is in that
+// location.
+func (obj *Object) GetTimeInLocation(attr string, loc *time.Location) (t time.Time, err error) {
+	return obj.getTime(attr, loc)
+}
+
+func (obj *Object) getTime(attr string, loc *time.Location) (t time.Time, err error) {
 	n, err := obj.Get(attr)
 	if err != nil {
 		return time.Unix(0, 0), err
 	}
-	value, ok := n.(json.Number)
-	if !ok {
-		return time.Unix(0, 0), fmt.Errorf("attr %v is not a number", attr)
+	var ts int64
+	switch value := n.(type) {
+	case json.Number:
+		if ts, err = value.Int64(); err != nil {
+			return time.Unix(0, 0), err
+		}
+	case string:
+		if ts, err = strconv.ParseInt(value, 10, 64); err != nil {
+			return parseTime(attr, value, loc)
+		}
+	default:
+		return time.Unix(0, 0), fmt.Errorf("attr %v is not a time", attr)
+	}
+	if loc != nil {
+		return time.Unix(ts, 0).In(loc), nil
+	}
+	return time.Unix(ts, 0), nil
+}
+
+// parseTime parses a string with any of the layouts in timeLayouts.
+func parseTime(attr, value string, loc *time.Location) (time.Time, error) {
+	parseLoc := loc
+	if parseLoc == nil {
+		parseLoc = time.UTC
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, value, parseLoc); err == nil {
+			if loc != nil {
+				t = t.In(loc)
+			}
+			return t, nil
+		}
 	}
-	ts, err := value.Int64()
-	return time.Unix(ts, 0), err
+	return time.Unix(0, 0), fmt.Errorf("attr %v is not a time", attr)
 }
 
 // MustGetTime is like GetTime, but it panic in case of error.
@@ -371,8 +624,119 @@ func (obj *Object) MustGetStringSlice(attr string) []string {
 	return result
 }
 
+// GetStringMap returns an attribute as a map of strings. It returns the
+// attribute's value or an error if the attribute doesn't exist or is not a
+// map where all values are strings.
+func (obj *Object) GetStringMap(attr string) (m map[string]string, err error) {
+	value, err := obj.Get(attr)
+	if err != nil {
+		return m, err
+	}
+
+	rawMap, isMap := value.(map[string]interface{})
+	if !isMap {
+		return m, fmt.Errorf("attribute %q is not a map", attr)
+	}
+
+	m = make(map[string]string, len(rawMap))
+	for key, rawValue := range rawMap {
+		strValue, isString := rawValue.(string)
+		if !isString {
+			return nil, fmt.Errorf("attribute %q is not a map of strings", attr)
+		}
+		m[key] = strValue
+	}
+
+	return m, nil
+}
+
+// MustGetStringMap is like GetStringMap, but it panic in case of error.
+func (obj *Object) MustGetStringMap(attr string) map[string]string {
+	result, err := obj.GetStringMap(attr)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// GetInt64Slice returns an attribute as an int64 slice. It returns the
+// attribute's value or an error if the attribute doesn't exist or is not a
+// slice of integer numbers.
+func (obj *Object) GetInt64Slice(attr string) (s []int64, err error) {
+	value, err := obj.Get(attr)
+	if err != nil {
+		return s, err
+	}
+
+	rawValues, isArrayInterface := value.([]interface{})
+	if !isArrayInterface {
+		return s, fmt.Errorf("attribute %q is not a slice", attr)
+	}
+
+	for _, rawValue := range rawValues {
+		n, isNumber := rawValue.(json.Number)
+		if !isNumber {
+			return nil, fmt.Errorf("attribute %q is not a slice of numbers", attr)
+		}
+		i, err := n.Int64()
+		if err != nil {
+			return nil, err
+		}
+		s = append(s, i)
+	}
+
+	return s, nil
+}
+
+// MustGetInt64Slice is like GetInt64Slice, but it panic in case of error.
+func (obj *Object) MustGetInt64Slice(attr string) []int64 {
+	result, err := obj.GetInt64Slice(attr)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// GetObjectSlice returns an attribute that is a slice of nested structures,
+// like "pe_info.sections", as a slice of objects. The attributes of each
+// object are the fields of the corresponding structure, and they can be
+// accessed with the usual getters. The returned objects don't have type nor
+// ID, and they share their attributes with the parent object.
+func (obj *Object) GetObjectSlice(attr string) (s []*Object, err error) {
+	value, err := obj.Get(attr)
+	if err != nil {
+		return s, err
+	}
+
+	rawValues, isArrayInterface := value.([]interface{})
+	if !isArrayInterface {
+		return s, fmt.Errorf("attribute %q is not a slice", attr)
+	}
+
+	for _, rawValue := range rawValues {
+		m, isMap := rawValue.(map[string]interface{})
+		if !isMap {
+			return nil, fmt.Errorf("attribute %q is not a slice of objects", attr)
+		}
+		s = append(s, &Object{data: objectData{Attributes: m}})
+	}
+
+	return s, nil
+}
+
+// MustGetObjectSlice is like GetObjectSlice, but it panic in case of error.
+func (obj *Object) MustGetObjectSlice(attr string) []*Object {
+	result, err := obj.GetObjectSlice(attr)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
 // GetContext gets a context attribute by name.
 func (obj *Object) GetContext(attr string) (interface{}, error) {
+	obj.rlock()
+	defer obj.runlock()
 	if value, exists := obj.data.ContextAttributes[attr]; exists {
 		return value, nil
 	}
@@ -405,6 +769,8 @@ func (obj *Object) GetContextFloat64(attr string) (float64, error) {
 // attribute's value or an error if the attribute doesn't exist or is not a
 // string.
 func (obj *Object) GetContextString(attr string) (s string, err error) {
+	obj.rlock()
+	defer obj.runlock()
 	if attrValue, attrExists := obj.data.ContextAttributes[attr]; attrExists {
 		s, isString := attrValue.(string)
 		if !isString {
@@ -419,6 +785,8 @@ func (obj *Object) GetContextString(attr string) (s string, err error) {
 // attribute's value or an error if the attribute doesn't exist or is not a
 // bool.
 func (obj *Object) GetContextBool(attr string) (b bool, err error) {
+	obj.rlock()
+	defer obj.runlock()
 	if attrValue, attrExists := obj.data.ContextAttributes[attr]; attrExists {
 		b, isBool := attrValue.(bool)
 		if !isBool {
@@ -429,13 +797,68 @@ func (obj *Object) GetContextBool(attr string) (b bool, err error) {
 	return false, fmt.Errorf("context attribute \"%s\" does not exists", attr)
 }
 
-// Set the value for an attribute.
+// Set the value for an attribute. Like in Get, the attribute name can include
+// dots for setting nested attributes and indexes for setting items in slices.
+// Example: 'pe_info.imphash'
+// Example for arrays: 'tags.[0]'
+// Intermediate maps are created if they don't exist, but slices must exist
+// and have enough items. When the object is sent to the server with
+// PatchObject only the nested attributes that were modified are included, not
+// the whole map that contains them.
 func (obj *Object) Set(attr string, value interface{}) error {
+	obj.lock()
+	defer obj.unlock()
+	if obj.data.Attributes == nil {
+		obj.data.Attributes = make(map[string]interface{})
+	}
+	if strings.Contains(attr, ".") {
+		path, err := parsePath(attr)
+		if err != nil {
+			return err
+		}
+		if hasWildcard(path) {
+			return fmt.Errorf("wildcards are not allowed in Set: \"%s\"", attr)
+		}
+		if err := setPath(obj.data.Attributes, path, value); err != nil {
+			return fmt.Errorf("error setting attribute \"%s\": %s", attr, err)
+		}
+	} else {
+		obj.data.Attributes[attr] = value
+	}
+	obj.modifiedAttributes = append(obj.modifiedAttributes, attr)
+	return nil
+}
+
+// Unset removes an attribute from the object. When the object is sent to the
+// server with PatchObject the attribute is included with a null value, which
+// instructs the server to clear it. Like in Set, the attribute name can
+// include dots for removing nested attributes, but it can't end with a slice
+// index.
+func (obj *Object) Unset(attr string) error {
+	obj.lock()
+	defer obj.unlock()
+	path, err := parsePath(attr)
+	if err != nil {
+		return err
+	}
+	if hasWildcard(path) {
+		return fmt.Errorf("wildcards are not allowed in Unset: \"%s\"", attr)
+	}
+	if obj.data.Attributes != nil {
+		if err := deletePath(obj.data.Attributes, path); err != nil {
+			return fmt.Errorf("error unsetting attribute \"%s\": %s", attr, err)
+		}
+	}
 	obj.modifiedAttributes = append(obj.modifiedAttributes, attr)
-	obj.data.Attributes[attr] = value
 	return nil
 }
 
+// SetNull sets an attribute to null. This is similar to Unset, but the
+// attribute is kept in the object with a nil value.
+func (obj *Object) SetNull(attr string) error {
+	return obj.Set(attr, nil)
+}
+
 // SetInt64 sets the value of an integer attribute.
 func (obj *Object) SetInt64(attr string, value int64) error {
 	return obj.Set(attr, value)
@@ -461,14 +884,83 @@ func (obj *Object) SetTime(attr string, value time.Time) error {
 	return obj.Set(attr, value.Unix())
 }
 
+// SetContext sets the value for a context attribute. Context attributes set
+// with this method are sent to the server along with the modified attributes,
+// which is useful when adding objects to a relationship that accepts context
+// attributes, like items in a graph or a collection.
+func (obj *Object) SetContext(attr string, value interface{}) error {
+	obj.lock()
+	defer obj.unlock()
+	if obj.data.ContextAttributes == nil {
+		obj.data.ContextAttributes = make(map[string]interface{})
+	}
+	obj.data.ContextAttributes[attr] = value
+	obj.modifiedContextAttributes = append(obj.modifiedContextAttributes, attr)
+	return nil
+}
+
+// SetContextInt64 sets the value of an integer context attribute.
+func (obj *Object) SetContextInt64(attr string, value int64) error {
+	return obj.SetContext(attr, json.Number(strconv.FormatInt(value, 10)))
+}
+
+// SetContextFloat64 sets the value of a float context attribute.
+func (obj *Object) SetContextFloat64(attr string, value float64) error {
+	return obj.SetContext(attr, json.Number(strconv.FormatFloat(value, 'g', -1, 64)))
+}
+
+// SetContextString sets the value of a string context attribute.
+func (obj *Object) SetContextString(attr, value string) error {
+	return obj.SetContext(attr, value)
+}
+
+// SetContextBool sets the value of a bool context attribute.
+func (obj *Object) SetContextBool(attr string, value bool) error {
+	return obj.SetContext(attr, value)
+}
+
 // SetData sets the value of a data field.
 func (obj *Object) SetData(key string, val interface{}) {
+	obj.lock()
+	defer obj.unlock()
 	if obj.modifiedData == nil {
 		obj.modifiedData = map[string]interface{}{}
 	}
 	obj.modifiedData[key] = val
 }
 
+// SetRelationship sets the objects in a one-to-many relationship. Relationships
+// set with this function are included when the object is sent to VirusTotal
+// with PostObject or PatchObject, which is required for creating some types of
+// objects, like collections or graphs. Example:
+//
+//	collection := vt.NewObject("collection")
+//	collection.SetString("name", "My collection")
+//	collection.SetRelationship("domains",
+//		vt.Descriptor{Type: "domain", ID: "example.com"})
+//	err := client.PostObject(vt.URL("collections"), collection)
+func (obj *Object) SetRelationship(name string, items ...Descriptor) error {
+	if items == nil {
+		items = []Descriptor{}
+	}
+	data, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+	objects := make([]*Object, len(items))
+	for i, item := range items {
+		objects[i] = NewObjectWithID(item.Type, item.ID)
+	}
+	obj.lock()
+	defer obj.unlock()
+	if obj.data.Relationships == nil {
+		obj.data.Relationships = make(map[string]*relationshipData)
+	}
+	obj.data.Relationships[name] = &relationshipData{Data: data, Objects: objects}
+	obj.modifiedRelationships = append(obj.modifiedRelationships, name)
+	return nil
+}
+
 // GetRelationship returns a relationship by name. Only those relationships
 // that you explicitly asked for in a call to GetObject can be obtained. You
 // can ask by a relationship by including the "relationships" parameter in the
@@ -482,26 +974,83 @@ func (obj *Object) SetData(key string, val interface{}) {
 //   r, _ := f.GetRelationship("contacted_urls")
 //
 func (obj *Object) GetRelationship(name string) (*Relationship, error) {
+	obj.rlock()
+	defer obj.runlock()
 	if r, exists := obj.data.Relationships[name]; exists {
 		return &Relationship{data: *r}, nil
 	}
 	return nil, fmt.Errorf("relationship \"%s\" doesn't exist", name)
 }
 
+// RelationshipCount returns the number of objects in a relationship. The
+// number is available only if the object was retrieved using the
+// WithRelationshipCounters option.
+func (obj *Object) RelationshipCount(name string) (int64, error) {
+	obj.rlock()
+	defer obj.runlock()
+	r, exists := obj.data.Relationships[name]
+	if !exists {
+		return 0, fmt.Errorf("relationship \"%s\" doesn't exist", name)
+	}
+	return r.Meta.Count()
+}
+
 // modifiedObject is a structure exactly like Object, but that implements the
 // MarshalJSON interface differently. When a modifiedObject is marshalled as
-// JSON only the attributes and data that have been modified are included.
-// Context attributes, relationships and links are not included either.
+// JSON only the attributes, context attributes, data and relationships that
+// have been modified are included. Links are not included either.
 type modifiedObject Object
 
 func (obj modifiedObject) MarshalJSON() ([]byte, error) {
+	if obj.mu != nil {
+		obj.mu.RLock()
+		defer obj.mu.RUnlock()
+	}
 	attributes := make(map[string]interface{})
 	for _, attr := range obj.modifiedAttributes {
-		attributes[attr] = obj.data.Attributes[attr]
+		if !strings.Contains(attr, ".") {
+			attributes[attr] = obj.data.Attributes[attr]
+			continue
+		}
+		// For nested attributes include only the modified value, building
+		// the maps that contain it. If the path goes through a slice the
+		// whole slice is included, as it can't be partially updated.
+		path, _ := parsePath(attr)
+		current := attributes
+		for i, elem := range path {
+			if i == len(path)-1 || path[i+1].isIndex {
+				current[elem.key], _ = getPath(obj.data.Attributes, path[:i+1])
+				break
+			}
+			next, isMap := current[elem.key].(map[string]interface{})
+			if !isMap {
+				next = make(map[string]interface{})
+				current[elem.key] = next
+			}
+			current = next
+		}
 	}
 	od := map[string]interface{}{
 		"attributes": attributes,
 	}
+	if len(obj.modifiedContextAttributes) > 0 {
+		contextAttributes := make(map[string]interface{})
+		for _, attr := range obj.modifiedContextAttributes {
+			contextAttributes[attr] = obj.data.ContextAttributes[attr]
+		}
+		od["context_attributes"] = contextAttributes
+	}
+	if len(obj.modifiedRelationships) > 0 {
+		relationships := make(map[string]interface{})
+		for _, name := range obj.modifiedRelationships {
+			// The relationship may have been replaced by data received
+			// from the server after it was set.
+			if r, exists := obj.data.Relationships[name]; exists {
+				relationships[name] = map[string]interface{}{"data": r.Data}
+			}
+		}
+		od["relationships"] = relationships
+	}
 	if obj.data.Type != "" {
 		od["type"] = obj.data.Type
 	}
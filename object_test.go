@@ -1,7 +1,11 @@
 package vt
 
 import (
+	"encoding/json"
+	"fmt"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -19,3 +23,293 @@ func TestModifiedObjectMarshallObject(t *testing.T) {
 		"{\"attributes\":{\"name\":\"collection name\"},\"data_field\":\"value\",\"type\":\"collection\"}",
 		string(marshalled))
 }
+
+func TestSetNestedAttributes(t *testing.T) {
+	obj := NewObject("file")
+	assert.NoError(t, obj.Set("pe_info.imphash", "abcd"))
+	assert.NoError(t, obj.Set("tags", []interface{}{"peexe", "upx"}))
+	assert.NoError(t, obj.Set("tags.[1]", "overlay"))
+
+	assert.Equal(t, "abcd", obj.MustGetString("pe_info.imphash"))
+	assert.Equal(t, []string{"peexe", "overlay"}, obj.MustGetStringSlice("tags"))
+
+	assert.Error(t, obj.Set("tags.[2]", "foo"))
+	assert.Error(t, obj.Set("pe_info.imphash.foo", "foo"))
+	assert.Error(t, obj.Set("pe_info..foo", "foo"))
+
+	marshalled, err := modifiedObject(*obj).MarshalJSON()
+	assert.NoError(t, err)
+	assert.JSONEq(t,
+		`{"type": "file", "attributes": {"pe_info": {"imphash": "abcd"}, "tags": ["peexe", "overlay"]}}`,
+		string(marshalled))
+}
+
+func TestSetNestedAttributesOnlySendsModified(t *testing.T) {
+	obj := &Object{}
+	err := obj.UnmarshalJSON([]byte(`{
+		"type": "file",
+		"id": "abcd",
+		"attributes": {
+			"pe_info": {"imphash": "1234", "entry_point": 4096},
+			"sections": [{"name": ".text"}, {"name": ".data"}]
+		}}`))
+	assert.NoError(t, err)
+
+	assert.NoError(t, obj.Set("pe_info.imphash", "5678"))
+	assert.NoError(t, obj.Set("sections.[1].name", ".rdata"))
+
+	marshalled, err := modifiedObject(*obj).MarshalJSON()
+	assert.NoError(t, err)
+	assert.JSONEq(t,
+		`{"type": "file", "id": "abcd", "attributes": {
+			"pe_info": {"imphash": "5678"},
+			"sections": [{"name": ".text"}, {"name": ".rdata"}]}}`,
+		string(marshalled))
+}
+
+func TestQuery(t *testing.T) {
+	obj := &Object{}
+	err := obj.UnmarshalJSON([]byte(`{
+		"type": "file",
+		"id": "abcd",
+		"attributes": {
+			"last_analysis_results": {
+				"EngineB": {"category": "malicious", "result": "Trojan"},
+				"EngineA": {"category": "undetected", "result": null},
+				"EngineC": {"category": "malicious", "result": "Backdoor"}
+			},
+			"pe_info": {"sections": [{"name": ".text", "size": 10}, {"name": ".data", "size": 20}]}
+		}}`))
+	assert.NoError(t, err)
+
+	values, err := obj.Query("last_analysis_results.*.category")
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"undetected", "malicious", "malicious"}, values)
+
+	values, err = obj.Query("pe_info.sections.*.size")
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{json.Number("10"), json.Number("20")}, values)
+
+	values, err = obj.Query("pe_info.sections.[1].name")
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{".data"}, values)
+
+	values, err = obj.Query("last_analysis_results.*.non_existing")
+	assert.NoError(t, err)
+	assert.Empty(t, values)
+
+	_, err = obj.Query("pe_info..name")
+	assert.Error(t, err)
+
+	assert.Error(t, obj.Set("pe_info.sections.*.name", "foo"))
+}
+
+func TestCollectionGetters(t *testing.T) {
+	obj := &Object{}
+	err := obj.UnmarshalJSON([]byte(`{
+		"type": "url",
+		"id": "abcd",
+		"attributes": {
+			"categories": {"VendorA": "phishing", "VendorB": "malware"},
+			"ports": [80, 443],
+			"sections": [{"name": ".text", "size": 10}, {"name": ".data", "size": 20}],
+			"mixed": [1, "two"]
+		}}`))
+	assert.NoError(t, err)
+
+	assert.Equal(t,
+		map[string]string{"VendorA": "phishing", "VendorB": "malware"},
+		obj.MustGetStringMap("categories"))
+	assert.Equal(t, []int64{80, 443}, obj.MustGetInt64Slice("ports"))
+
+	sections := obj.MustGetObjectSlice("sections")
+	assert.Len(t, sections, 2)
+	assert.Equal(t, ".data", sections[1].MustGetString("name"))
+	assert.Equal(t, int64(20), sections[1].MustGetInt64("size"))
+
+	_, err = obj.GetStringMap("ports")
+	assert.Error(t, err)
+	_, err = obj.GetInt64Slice("mixed")
+	assert.Error(t, err)
+	_, err = obj.GetObjectSlice("ports")
+	assert.Error(t, err)
+	assert.Panics(t, func() { obj.MustGetStringMap("non_existing") })
+}
+
+func TestConcurrencySafeObject(t *testing.T) {
+	obj := NewObject("file")
+	obj.MakeConcurrencySafe()
+	obj.SetInt64("counter", 0)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				obj.SetInt64(fmt.Sprintf("attr_%d", i), int64(j))
+				obj.GetInt64("counter")
+				obj.Attributes()
+			}
+		}(i)
+	}
+	wg.Wait()
+	assert.Len(t, obj.Attributes(), 11)
+}
+
+func TestObjectCopy(t *testing.T) {
+	obj := &Object{}
+	err := obj.UnmarshalJSON([]byte(`{
+		"type": "file",
+		"id": "abcd",
+		"attributes": {"tags": ["peexe"], "pe_info": {"imphash": "1234"}},
+		"context_attributes": {"some_string": "foo"}}`))
+	assert.NoError(t, err)
+
+	c := obj.Copy()
+	assert.NoError(t, obj.Set("tags.[0]", "pedll"))
+	assert.NoError(t, obj.Set("pe_info.imphash", "5678"))
+
+	assert.Equal(t, "abcd", c.ID())
+	assert.Equal(t, []string{"peexe"}, c.MustGetStringSlice("tags"))
+	assert.Equal(t, "1234", c.MustGetString("pe_info.imphash"))
+	s, err := c.GetContextString("some_string")
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", s)
+}
+
+func TestUnsetAttributes(t *testing.T) {
+	obj := &Object{}
+	err := obj.UnmarshalJSON([]byte(`{
+		"type": "graph",
+		"id": "abcd",
+		"attributes": {
+			"description": "foo",
+			"name": "bar",
+			"extra": {"comment": "baz", "tag": "qux"}
+		}}`))
+	assert.NoError(t, err)
+
+	assert.NoError(t, obj.Unset("description"))
+	assert.NoError(t, obj.Unset("extra.tag"))
+	assert.NoError(t, obj.SetNull("name"))
+	assert.Error(t, obj.Unset("extra.[0]"))
+
+	assert.ElementsMatch(t, []string{"name", "extra"}, obj.Attributes())
+	_, err = obj.Get("extra.tag")
+	assert.Error(t, err)
+
+	marshalled, err := modifiedObject(*obj).MarshalJSON()
+	assert.NoError(t, err)
+	assert.JSONEq(t,
+		`{"type": "graph", "id": "abcd", "attributes": {
+			"description": null, "name": null, "extra": {"tag": null}}}`,
+		string(marshalled))
+}
+
+func TestSetContextAttributes(t *testing.T) {
+	obj := NewObjectWithID("file", "abcd")
+	assert.NoError(t, obj.SetContextString("note", "first stage"))
+	assert.NoError(t, obj.SetContextInt64("position", 3))
+	assert.NoError(t, obj.SetContextFloat64("weight", 0.5))
+	assert.NoError(t, obj.SetContextBool("pinned", true))
+
+	s, err := obj.GetContextString("note")
+	assert.NoError(t, err)
+	assert.Equal(t, "first stage", s)
+	n, err := obj.GetContextInt64("position")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+	f, err := obj.GetContextFloat64("weight")
+	assert.NoError(t, err)
+	assert.Equal(t, 0.5, f)
+	b, err := obj.GetContextBool("pinned")
+	assert.NoError(t, err)
+	assert.True(t, b)
+
+	marshalled, err := modifiedObject(*obj).MarshalJSON()
+	assert.NoError(t, err)
+	assert.JSONEq(t,
+		`{"type": "file", "id": "abcd", "attributes": {}, "context_attributes": {
+			"note": "first stage", "position": 3, "weight": 0.5, "pinned": true}}`,
+		string(marshalled))
+}
+
+func TestGetTimeFromStrings(t *testing.T) {
+	obj := &Object{}
+	err := obj.UnmarshalJSON([]byte(`{
+		"type": "domain",
+		"id": "example.com",
+		"attributes": {
+			"unix": 1600000000,
+			"unix_string": "1600000000",
+			"rfc3339": "2020-09-13T12:26:40Z",
+			"rfc3339_offset": "2020-09-13T14:26:40+02:00",
+			"whois_date": "2020-09-13 12:26:40",
+			"date_only": "2020-09-13",
+			"garbage": "yesterday"
+		}}`))
+	assert.NoError(t, err)
+
+	expected := time.Unix(1600000000, 0)
+	for _, attr := range []string{"unix", "unix_string", "rfc3339", "rfc3339_offset", "whois_date"} {
+		tm, err := obj.GetTime(attr)
+		assert.NoError(t, err, attr)
+		assert.True(t, expected.Equal(tm), attr)
+	}
+
+	tm, err := obj.GetTime("date_only")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2020, 9, 13, 0, 0, 0, 0, time.UTC), tm)
+
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	tm, err = obj.GetTimeInLocation("whois_date", loc)
+	assert.NoError(t, err)
+	assert.True(t, expected.Add(-2*time.Hour).Equal(tm))
+	assert.Equal(t, loc, tm.Location())
+
+	tm, err = obj.GetTimeInLocation("unix", loc)
+	assert.NoError(t, err)
+	assert.True(t, expected.Equal(tm))
+	assert.Equal(t, loc, tm.Location())
+
+	_, err = obj.GetTime("garbage")
+	assert.Error(t, err)
+}
+
+func TestRelationshipCount(t *testing.T) {
+	ts := NewTestServer(t).
+		SetExpectedMethod("GET").
+		SetResponse(map[string]interface{}{
+			"data": map[string]interface{}{
+				"type": "file",
+				"id":   "1234",
+				"relationships": map[string]interface{}{
+					"contacted_ips": map[string]interface{}{
+						"meta": map[string]interface{}{"count": 1234},
+					},
+					"contacted_domains": map[string]interface{}{
+						"data": []interface{}{},
+						"meta": map[string]interface{}{"count": 0},
+					},
+				},
+			},
+		})
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+	obj, err := c.GetObject(URL("files/1234"), WithRelationshipCounters())
+	assert.NoError(t, err)
+
+	n, err := obj.RelationshipCount("contacted_ips")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1234), n)
+
+	n, err = obj.RelationshipCount("contacted_domains")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), n)
+
+	_, err = obj.RelationshipCount("contacted_urls")
+	assert.Error(t, err)
+}
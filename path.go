@@ -0,0 +1,212 @@
+// Copyright © 2017 The vt-go authors. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vt
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// pathElem is an element in an attribute path like "pe_info.sections.[0].name".
+// Each element is either a key in a map, or an index in a slice.
+type pathElem struct {
+	key        string
+	index      int
+	isIndex    bool
+	isWildcard bool
+}
+
+func (e pathElem) String() string {
+	if e.isWildcard {
+		return "*"
+	}
+	if e.isIndex {
+		return fmt.Sprintf("[%d]", e.index)
+	}
+	return e.key
+}
+
+// parsePath splits an attribute path into its elements. The path uses the
+// same syntax accepted by Get, where elements are separated by dots and
+// slice indexes are enclosed in brackets, like in "tags.[0]".
+func parsePath(path string) ([]pathElem, error) {
+	parts := strings.Split(path, ".")
+	elems := make([]pathElem, len(parts))
+	for i, part := range parts {
+		if part == "" {
+			return nil, fmt.Errorf("invalid attribute path \"%s\"", path)
+		}
+		if strings.HasPrefix(part, "[") && strings.HasSuffix(part, "]") {
+			n, err := strconv.Atoi(part[1 : len(part)-1])
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid index \"%s\" in attribute path \"%s\"", part, path)
+			}
+			elems[i] = pathElem{index: n, isIndex: true}
+		} else if part == "*" {
+			elems[i] = pathElem{isWildcard: true}
+		} else {
+			elems[i] = pathElem{key: part}
+		}
+	}
+	if elems[0].isIndex {
+		return nil, fmt.Errorf("attribute path \"%s\" can't start with an index", path)
+	}
+	return elems, nil
+}
+
+// hasWildcard returns true if some element in the path is a wildcard.
+func hasWildcard(path []pathElem) bool {
+	for _, elem := range path {
+		if elem.isWildcard {
+			return true
+		}
+	}
+	return false
+}
+
+// queryPath returns all the values within v that match the given path, which
+// can contain wildcards. A wildcard matches every value in a map, in order of
+// their keys, or every item in a slice.
+func queryPath(v interface{}, path []pathElem) []interface{} {
+	if len(path) == 0 {
+		return []interface{}{v}
+	}
+	elem, rest := path[0], path[1:]
+	var result []interface{}
+	switch c := v.(type) {
+	case map[string]interface{}:
+		if elem.isIndex {
+			return nil
+		}
+		if !elem.isWildcard {
+			if item, exists := c[elem.key]; exists {
+				return queryPath(item, rest)
+			}
+			return nil
+		}
+		keys := make([]string, 0, len(c))
+		for key := range c {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			result = append(result, queryPath(c[key], rest)...)
+		}
+	case []interface{}:
+		if elem.isIndex {
+			if elem.index < len(c) {
+				return queryPath(c[elem.index], rest)
+			}
+			return nil
+		}
+		if !elem.isWildcard {
+			return nil
+		}
+		for _, item := range c {
+			result = append(result, queryPath(item, rest)...)
+		}
+	}
+	return result
+}
+
+// setPath sets the value at the given path within m. Intermediate maps are
+// created as required, but slices must already exist and have enough items
+// for the specified indexes.
+func setPath(m map[string]interface{}, path []pathElem, value interface{}) error {
+	var current interface{} = m
+	for i, elem := range path {
+		last := i == len(path)-1
+		switch c := current.(type) {
+		case map[string]interface{}:
+			if elem.isIndex {
+				return fmt.Errorf("can't use index %s with a map", elem)
+			}
+			if last {
+				c[elem.key] = value
+				return nil
+			}
+			next, exists := c[elem.key]
+			if !exists || next == nil {
+				if path[i+1].isIndex {
+					return fmt.Errorf("\"%s\" is not a slice", elem)
+				}
+				next = make(map[string]interface{})
+				c[elem.key] = next
+			}
+			current = next
+		case []interface{}:
+			if !elem.isIndex {
+				return fmt.Errorf("can't use key \"%s\" with a slice", elem)
+			}
+			if elem.index >= len(c) {
+				return fmt.Errorf("index %s out of range", elem)
+			}
+			if last {
+				c[elem.index] = value
+				return nil
+			}
+			current = c[elem.index]
+		default:
+			return fmt.Errorf("\"%s\" is not a map or slice", path[i-1])
+		}
+	}
+	return nil
+}
+
+// deletePath removes the value at the given path within m. The last element
+// in the path must be a map key. Deleting a path that doesn't exist is not an
+// error.
+func deletePath(m map[string]interface{}, path []pathElem) error {
+	last := path[len(path)-1]
+	if last.isIndex || last.isWildcard {
+		return fmt.Errorf("can't delete %s, only map keys can be deleted", last)
+	}
+	parent, exists := getPath(m, path[:len(path)-1])
+	if !exists {
+		return nil
+	}
+	if pm, isMap := parent.(map[string]interface{}); isMap {
+		delete(pm, last.key)
+	}
+	return nil
+}
+
+// getPath returns the value at the given path within m, and a boolean that
+// indicates if the path exists.
+func getPath(m map[string]interface{}, path []pathElem) (interface{}, bool) {
+	var current interface{} = m
+	for _, elem := range path {
+		switch c := current.(type) {
+		case map[string]interface{}:
+			if elem.isIndex {
+				return nil, false
+			}
+			v, exists := c[elem.key]
+			if !exists {
+				return nil, false
+			}
+			current = v
+		case []interface{}:
+			if !elem.isIndex || elem.index >= len(c) {
+				return nil, false
+			}
+			current = c[elem.index]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
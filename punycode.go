@@ -0,0 +1,145 @@
+// Copyright © 2017 The vt-go authors. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vt
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+)
+
+// Parameters for the Punycode encoding as defined in RFC 3492.
+const (
+	punycodeBase        = 36
+	punycodeTMin        = 1
+	punycodeTMax        = 26
+	punycodeSkew        = 38
+	punycodeDamp        = 700
+	punycodeInitialBias = 72
+	punycodeInitialN    = 128
+)
+
+var errPunycodeOverflow = errors.New("punycode: overflow")
+
+func punycodeAdapt(delta, numPoints int, firstTime bool) int {
+	if firstTime {
+		delta /= punycodeDamp
+	} else {
+		delta /= 2
+	}
+	delta += delta / numPoints
+	k := 0
+	for delta > ((punycodeBase-punycodeTMin)*punycodeTMax)/2 {
+		delta /= punycodeBase - punycodeTMin
+		k += punycodeBase
+	}
+	return k + (punycodeBase-punycodeTMin+1)*delta/(delta+punycodeSkew)
+}
+
+func punycodeDigit(d int) byte {
+	if d < 26 {
+		return byte('a' + d)
+	}
+	return byte('0' + d - 26)
+}
+
+// punycodeEncode encodes a Unicode string using the Punycode algorithm
+// described in RFC 3492. The "xn--" prefix is not included in the result.
+func punycodeEncode(s string) (string, error) {
+	var out strings.Builder
+	runes := []rune(s)
+	for _, r := range runes {
+		if r < utf8.RuneSelf {
+			out.WriteByte(byte(r))
+		}
+	}
+	b := out.Len()
+	h := b
+	if b > 0 {
+		out.WriteByte('-')
+	}
+	n := punycodeInitialN
+	delta := 0
+	bias := punycodeInitialBias
+	for h < len(runes) {
+		m := int(utf8.MaxRune) + 1
+		for _, r := range runes {
+			if int(r) >= n && int(r) < m {
+				m = int(r)
+			}
+		}
+		if (m-n)*(h+1) < 0 {
+			return "", errPunycodeOverflow
+		}
+		delta += (m - n) * (h + 1)
+		n = m
+		for _, r := range runes {
+			if int(r) < n {
+				delta++
+				if delta < 0 {
+					return "", errPunycodeOverflow
+				}
+			}
+			if int(r) != n {
+				continue
+			}
+			q := delta
+			for k := punycodeBase; ; k += punycodeBase {
+				t := k - bias
+				if t < punycodeTMin {
+					t = punycodeTMin
+				} else if t > punycodeTMax {
+					t = punycodeTMax
+				}
+				if q < t {
+					break
+				}
+				out.WriteByte(punycodeDigit(t + (q-t)%(punycodeBase-t)))
+				q = (q - t) / (punycodeBase - t)
+			}
+			out.WriteByte(punycodeDigit(q))
+			bias = punycodeAdapt(delta, h+1, h == b)
+			delta = 0
+			h++
+		}
+		delta++
+		n++
+	}
+	return out.String(), nil
+}
+
+// toASCIIHost converts an internationalized host name into its ASCII form,
+// encoding each non-ASCII label with Punycode and adding the "xn--" prefix.
+// The host is lowercased. Labels that are already ASCII are left untouched.
+func toASCIIHost(host string) (string, error) {
+	labels := strings.Split(strings.ToLower(host), ".")
+	for i, label := range labels {
+		ascii := true
+		for j := 0; j < len(label); j++ {
+			if label[j] >= utf8.RuneSelf {
+				ascii = false
+				break
+			}
+		}
+		if ascii {
+			continue
+		}
+		encoded, err := punycodeEncode(label)
+		if err != nil {
+			return "", err
+		}
+		labels[i] = "xn--" + encoded
+	}
+	return strings.Join(labels, "."), nil
+}
@@ -0,0 +1,161 @@
+// Copyright © 2017 The vt-go authors. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vt
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// QueryBuilder builds VirusTotal Intelligence search queries, taking care of
+// quoting and escaping the values, so that values coming from untrusted
+// sources can't alter the query's meaning. Create it with Query and pass the
+// result of String to Search. Example:
+//
+//	query := vt.Query().
+//		Type("peexe").
+//		PositivesAtLeast(5).
+//		FirstSeenAfter(time.Now().Add(-24 * time.Hour)).
+//		Tag("upx")
+//	it, err := client.Search(query.String())
+type QueryBuilder struct {
+	terms []string
+}
+
+// Query returns a new QueryBuilder for an empty query.
+func Query() *QueryBuilder {
+	return &QueryBuilder{}
+}
+
+// queryTimeFormat is the format of dates in search queries.
+const queryTimeFormat = "2006-01-02T15:04:05"
+
+// quoteQueryString returns the value between double quotes, escaping any
+// double quote or backslash in it.
+func quoteQueryString(value string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	return `"` + r.Replace(value) + `"`
+}
+
+// quoteQueryValue returns the value quoted if it contains characters with a
+// special meaning in search queries.
+func quoteQueryValue(value string) string {
+	if value != "" && !strings.ContainsAny(value, " \t\n\r\"\\():") {
+		return value
+	}
+	return quoteQueryString(value)
+}
+
+// term adds a "name:value" term to the query without quoting the value.
+func (q *QueryBuilder) term(name, value string) *QueryBuilder {
+	q.terms = append(q.terms, name+":"+value)
+	return q
+}
+
+// Modifier adds a "name:value" term to the query, the value is quoted if
+// necessary. This can be used for modifiers that don't have a specific method.
+func (q *QueryBuilder) Modifier(name, value string) *QueryBuilder {
+	return q.term(name, quoteQueryValue(value))
+}
+
+// Not adds a negated "name:value" term to the query, which matches objects
+// that don't match the term.
+func (q *QueryBuilder) Not(name, value string) *QueryBuilder {
+	q.terms = append(q.terms, "NOT "+name+":"+quoteQueryValue(value))
+	return q
+}
+
+// Entity adds an "entity" modifier to the query, for searching something else
+// than files.
+func (q *QueryBuilder) Entity(e SearchEntity) *QueryBuilder {
+	return q.Modifier("entity", string(e))
+}
+
+// Type adds a "type" modifier to the query, like "peexe" or "pdf".
+func (q *QueryBuilder) Type(t string) *QueryBuilder {
+	return q.Modifier("type", t)
+}
+
+// Tag adds a "tag" modifier to the query.
+func (q *QueryBuilder) Tag(tag string) *QueryBuilder {
+	return q.Modifier("tag", tag)
+}
+
+// Name adds a "name" modifier to the query, which matches any of the names
+// with which the file was submitted.
+func (q *QueryBuilder) Name(name string) *QueryBuilder {
+	return q.Modifier("name", name)
+}
+
+// Engines adds an "engines" modifier to the query, which matches the
+// detection names produced by the antivirus engines.
+func (q *QueryBuilder) Engines(name string) *QueryBuilder {
+	return q.Modifier("engines", name)
+}
+
+// Content adds a "content" modifier to the query, which matches files
+// containing the given string.
+func (q *QueryBuilder) Content(s string) *QueryBuilder {
+	return q.term("content", quoteQueryString(s))
+}
+
+// PositivesAtLeast matches objects detected by at least n engines.
+func (q *QueryBuilder) PositivesAtLeast(n int) *QueryBuilder {
+	return q.term("p", strconv.Itoa(n)+"+")
+}
+
+// PositivesAtMost matches objects detected by at most n engines.
+func (q *QueryBuilder) PositivesAtMost(n int) *QueryBuilder {
+	return q.term("p", strconv.Itoa(n)+"-")
+}
+
+// SizeAtLeast matches files with a size of at least n bytes.
+func (q *QueryBuilder) SizeAtLeast(n int64) *QueryBuilder {
+	return q.term("size", strconv.FormatInt(n, 10)+"+")
+}
+
+// SizeAtMost matches files with a size of at most n bytes.
+func (q *QueryBuilder) SizeAtMost(n int64) *QueryBuilder {
+	return q.term("size", strconv.FormatInt(n, 10)+"-")
+}
+
+// FirstSeenAfter matches objects submitted to VirusTotal for the first time
+// after the given time.
+func (q *QueryBuilder) FirstSeenAfter(t time.Time) *QueryBuilder {
+	return q.term("fs", t.UTC().Format(queryTimeFormat)+"+")
+}
+
+// FirstSeenBefore matches objects submitted to VirusTotal for the first time
+// before the given time.
+func (q *QueryBuilder) FirstSeenBefore(t time.Time) *QueryBuilder {
+	return q.term("fs", t.UTC().Format(queryTimeFormat)+"-")
+}
+
+// LastSeenAfter matches objects submitted to VirusTotal for the last time
+// after the given time.
+func (q *QueryBuilder) LastSeenAfter(t time.Time) *QueryBuilder {
+	return q.term("ls", t.UTC().Format(queryTimeFormat)+"+")
+}
+
+// LastSeenBefore matches objects submitted to VirusTotal for the last time
+// before the given time.
+func (q *QueryBuilder) LastSeenBefore(t time.Time) *QueryBuilder {
+	return q.term("ls", t.UTC().Format(queryTimeFormat)+"-")
+}
+
+// String returns the query.
+func (q *QueryBuilder) String() string {
+	return strings.Join(q.terms, " ")
+}
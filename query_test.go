@@ -0,0 +1,31 @@
+package vt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilder(t *testing.T) {
+	fs := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	q := Query().
+		Type("peexe").
+		PositivesAtLeast(5).
+		FirstSeenAfter(fs).
+		Tag("upx").
+		SizeAtMost(1024).
+		Name(`my "file".exe`).
+		Content(`MZ\x90`).
+		Not("tag", "signed")
+	assert.Equal(t,
+		`type:peexe p:5+ fs:2024-01-02T03:04:05+ tag:upx size:1024- `+
+			`name:"my \"file\".exe" content:"MZ\\x90" NOT tag:signed`,
+		q.String())
+
+	// Values that could alter the query are quoted.
+	assert.Equal(t, `tag:"upx OR p:0+"`, Query().Tag("upx OR p:0+").String())
+	assert.Equal(t, `tag:""`, Query().Tag("").String())
+	assert.Equal(t, "entity:domain tld:xyz",
+		Query().Entity(EntityDomain).Modifier("tld", "xyz").String())
+}
@@ -0,0 +1,90 @@
+// Copyright © 2017 The vt-go authors. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vt
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// objectTypes maps VirusTotal object types to the Go types registered for
+// them with RegisterObjectType.
+var objectTypes = struct {
+	sync.RWMutex
+	m map[string]reflect.Type
+}{m: make(map[string]reflect.Type)}
+
+// RegisterObjectType registers a Go struct type as the target for decoding
+// the attributes of objects of the given type. This allows decoding objects
+// into typed values with Object.Decode, even for object types not known by
+// this package. The target must be a struct or a pointer to a struct, its
+// fields are filled from the object's attributes following the same rules
+// as json.Unmarshal. Example:
+//
+//	type Collection struct {
+//		Name       string `json:"name"`
+//		FilesCount int64  `json:"files_count"`
+//	}
+//
+//	vt.RegisterObjectType("collection", Collection{})
+//
+// Registering the same object type again replaces the previous target. This
+// function panics if target is not a struct or a pointer to a struct.
+func RegisterObjectType(objType string, target interface{}) {
+	t := reflect.TypeOf(target)
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("target for object type \"%s\" is not a struct", objType))
+	}
+	objectTypes.Lock()
+	objectTypes.m[objType] = t
+	objectTypes.Unlock()
+}
+
+// IsObjectTypeRegistered returns true if some Go type was registered for the
+// given object type with RegisterObjectType.
+func IsObjectTypeRegistered(objType string) bool {
+	objectTypes.RLock()
+	defer objectTypes.RUnlock()
+	_, ok := objectTypes.m[objType]
+	return ok
+}
+
+// Decode decodes the object's attributes into a new value of the Go type
+// registered for the object's type with RegisterObjectType, and returns a
+// pointer to that value. An error is returned if no Go type has been
+// registered for the object's type.
+func (obj *Object) Decode() (interface{}, error) {
+	objectTypes.RLock()
+	t, ok := objectTypes.m[obj.Type()]
+	objectTypes.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("object type \"%s\" is not registered", obj.Type())
+	}
+	obj.rlock()
+	b, err := json.Marshal(obj.data.Attributes)
+	obj.runlock()
+	if err != nil {
+		return nil, err
+	}
+	v := reflect.New(t).Interface()
+	if err := json.Unmarshal(b, v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
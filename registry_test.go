@@ -0,0 +1,37 @@
+package vt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testCollection struct {
+	Name       string   `json:"name"`
+	FilesCount int64    `json:"files_count"`
+	Tags       []string `json:"tags"`
+}
+
+func TestRegisterObjectType(t *testing.T) {
+	RegisterObjectType("test_collection", testCollection{})
+	assert.True(t, IsObjectTypeRegistered("test_collection"))
+	assert.False(t, IsObjectTypeRegistered("test_unknown"))
+
+	obj := NewObject("test_collection")
+	obj.SetString("name", "foo")
+	obj.SetInt64("files_count", 3)
+	obj.Set("tags", []string{"bar", "baz"})
+
+	v, err := obj.Decode()
+	assert.NoError(t, err)
+	assert.Equal(t, &testCollection{
+		Name:       "foo",
+		FilesCount: 3,
+		Tags:       []string{"bar", "baz"},
+	}, v)
+
+	_, err = NewObject("test_unknown").Decode()
+	assert.Error(t, err)
+
+	assert.Panics(t, func() { RegisterObjectType("test_invalid", "foo") })
+}
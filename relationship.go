@@ -13,11 +13,18 @@
 
 package vt
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+)
 
 type relationshipData struct {
 	Data  json.RawMessage `json:"data,omitempty"`
 	Links Links           `json:"links,omitempty"`
+	// Meta includes the number of objects in the relationship when the
+	// object is requested with WithRelationshipCounters.
+	Meta Meta `json:"meta,omitempty"`
 	// IsOneToOne is true if this is a one-to-one relationship and False if
 	// otherwise. If true Objects contains one object at most.
 	IsOneToOne bool
@@ -38,3 +45,159 @@ func (r *Relationship) IsOneToOne() bool {
 func (r *Relationship) Objects() []*Object {
 	return r.data.Objects
 }
+
+// Links returns the links of this relationship. Self points to the relationship
+// itself, Related to the collection with the full related objects, and Next
+// to the next page of related objects, if the relationship has more objects
+// than those included inline.
+func (r *Relationship) Links() Links {
+	return r.data.Links
+}
+
+// HasNext returns true if the relationship has more objects than those
+// included inline, which can be retrieved with Next.
+func (r *Relationship) HasNext() bool {
+	return r.data.Links.Next != ""
+}
+
+// Next retrieves the next page of objects in the relationship by following its
+// Next link, and returns it as another Relationship whose Objects are the ones
+// in that page, and whose Next method retrieves the following page. An error
+// is returned if there are no more pages, see HasNext. Example:
+//
+//	r, _ := obj.GetRelationship("contacted_ips")
+//	for {
+//		for _, ip := range r.Objects() {
+//			...
+//		}
+//		if !r.HasNext() {
+//			break
+//		}
+//		if r, err = r.Next(client); err != nil {
+//			...handle error
+//		}
+//	}
+func (r *Relationship) Next(cli *Client) (*Relationship, error) {
+	if !r.HasNext() {
+		return nil, errors.New("relationship doesn't have more pages")
+	}
+	u, err := url.Parse(r.data.Links.Next)
+	if err != nil {
+		return nil, err
+	}
+	next := &Relationship{}
+	resp, err := cli.GetData(u, &next.data.Objects)
+	if err != nil {
+		return nil, err
+	}
+	next.data.Data = resp.Data
+	next.data.Links = resp.Links
+	// The Related link doesn't change from one page to another.
+	next.data.Links.Related = r.data.Links.Related
+	return next, nil
+}
+
+// AllObjects returns the objects included inline in the relationship, followed
+// by the objects in the subsequent pages, which are retrieved with Next.
+func (r *Relationship) AllObjects(cli *Client) ([]*Object, error) {
+	objs := append([]*Object(nil), r.Objects()...)
+	for page := r; page.HasNext(); {
+		var err error
+		if page, err = page.Next(cli); err != nil {
+			return nil, err
+		}
+		objs = append(objs, page.Objects()...)
+	}
+	return objs, nil
+}
+
+// Descriptor identifies an object by its type and ID. Descriptors are used for
+// adding objects to relationships and removing them.
+type Descriptor struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+// Maximum number of descriptors sent in a single request by
+// AddRelationshipItems and RemoveRelationshipItems.
+const relationshipBatchSize = 100
+
+// modifyRelationship sends the descriptors to the relationship endpoint in
+// batches, using the given function for sending each batch.
+func modifyRelationship(items []Descriptor, send func(batch []Descriptor) error) error {
+	errs := &MultiError{}
+	for start := 0; start < len(items); start += relationshipBatchSize {
+		end := start + relationshipBatchSize
+		if end > len(items) {
+			end = len(items)
+		}
+		if err := send(items[start:end]); err != nil {
+			for i := start; i < end; i++ {
+				errs.add(i, items[i].ID, err)
+			}
+		}
+	}
+	return errs.errorOrNil()
+}
+
+// relationshipURL returns the URL for a relationship of the object with the
+// given URL.
+func relationshipURL(objectURL *url.URL, relationship string) *url.URL {
+	u := *objectURL
+	u.Path = u.Path + "/relationships/" + relationship
+	return &u
+}
+
+// AddRelationshipItems adds objects to a relationship of the object with the
+// given URL, like adding files or domains to a collection, or items to a
+// graph. Large lists of items are sent in multiple requests, if some of them
+// fail the returned error is a *MultiError that indicates which items failed.
+// Example:
+//
+//	err := client.AddRelationshipItems(vt.URL("collections/%s", id), "domains",
+//		[]vt.Descriptor{{Type: "domain", ID: "example.com"}})
+func (cli *Client) AddRelationshipItems(objectURL *url.URL, relationship string, items []Descriptor, options ...RequestOption) error {
+	u := relationshipURL(objectURL, relationship)
+	return modifyRelationship(items, func(batch []Descriptor) error {
+		_, err := cli.PostData(u, batch, options...)
+		return err
+	})
+}
+
+// RemoveRelationshipItems removes objects from a relationship of the object
+// with the given URL. Like in AddRelationshipItems, large lists of items are
+// sent in multiple requests, and the returned error is a *MultiError if some
+// of them fail.
+func (cli *Client) RemoveRelationshipItems(objectURL *url.URL, relationship string, items []Descriptor, options ...RequestOption) error {
+	u := relationshipURL(objectURL, relationship)
+	return modifyRelationship(items, func(batch []Descriptor) error {
+		_, err := cli.DeleteData(u, batch, options...)
+		return err
+	})
+}
+
+// RelatedDescriptors returns an iterator over the descriptors of the objects
+// related to the object with the given URL, the descriptors contain only the
+// type and ID of the objects, and can be obtained with Iterator.Descriptor.
+// This uses the relationships endpoint (i.e: /files/{id}/relationships/{name}),
+// which is much cheaper than retrieving the full related objects when only
+// their IDs are needed.
+func (cli *Client) RelatedDescriptors(objectURL *url.URL, relationship string, options ...IteratorOption) (*Iterator, error) {
+	return cli.Iterator(relationshipURL(objectURL, relationship), options...)
+}
+
+// Iterator returns an iterator over the full related objects, as opposed to
+// Objects, which returns only the objects included inline in the parent
+// object, which are usually descriptors containing only the type and ID, and
+// possibly not all of them. Objects are retrieved on demand by following the
+// relationship's Related link.
+func (r *Relationship) Iterator(cli *Client, options ...IteratorOption) (*Iterator, error) {
+	if r.data.Links.Related == "" {
+		return nil, errors.New("relationship doesn't have a related link")
+	}
+	u, err := url.Parse(r.data.Links.Related)
+	if err != nil {
+		return nil, err
+	}
+	return cli.Iterator(u, options...)
+}
@@ -0,0 +1,247 @@
+package vt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRelationshipLinks(t *testing.T) {
+	obj := &Object{}
+	err := json.Unmarshal([]byte(`{
+		"type": "file",
+		"id": "1234",
+		"relationships": {
+			"contacted_domains": {
+				"data": [{"type": "domain", "id": "example.com"}],
+				"links": {
+					"self": "https://www.virustotal.com/api/v3/files/1234/relationships/contacted_domains?limit=1",
+					"related": "https://www.virustotal.com/api/v3/files/1234/contacted_domains",
+					"next": "https://www.virustotal.com/api/v3/files/1234/relationships/contacted_domains?cursor=abc&limit=1"
+				}
+			}
+		}}`), obj)
+	assert.NoError(t, err)
+
+	r, err := obj.GetRelationship("contacted_domains")
+	assert.NoError(t, err)
+	assert.False(t, r.IsOneToOne())
+	assert.Len(t, r.Objects(), 1)
+	assert.Equal(t,
+		"https://www.virustotal.com/api/v3/files/1234/contacted_domains",
+		r.Links().Related)
+	assert.Equal(t,
+		"https://www.virustotal.com/api/v3/files/1234/relationships/contacted_domains?cursor=abc&limit=1",
+		r.Links().Next)
+}
+
+func TestRelationshipIterator(t *testing.T) {
+	ts := newCollectionTestServer(t, 5, 2)
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+
+	obj := &Object{}
+	err := json.Unmarshal([]byte(`{
+		"type": "file",
+		"id": "1234",
+		"relationships": {
+			"contacted_domains": {
+				"data": [{"type": "object_type", "id": "object_id_0"}],
+				"links": {"related": "`+ts.URL+`/api/v3/files/1234/contacted_domains"}
+			},
+			"parent": {"data": null}
+		}}`), obj)
+	assert.NoError(t, err)
+
+	r, err := obj.GetRelationship("contacted_domains")
+	assert.NoError(t, err)
+	it, err := r.Iterator(c)
+	assert.NoError(t, err)
+	defer it.Close()
+
+	n := 0
+	for it.Next() {
+		assert.Equal(t, int64(n), it.Get().MustGetInt64("index"))
+		n++
+	}
+	assert.NoError(t, it.Error())
+	assert.Equal(t, 5, n)
+
+	r, err = obj.GetRelationship("parent")
+	assert.NoError(t, err)
+	_, err = r.Iterator(c)
+	assert.Error(t, err)
+}
+
+func TestModifyRelationship(t *testing.T) {
+	var requests []string
+	var batches []int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v3/collections/1234/relationships/domains", r.URL.Path)
+		req := struct{ Data []Descriptor }{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		requests = append(requests, r.Method)
+		batches = append(batches, len(req.Data))
+		w.Header().Set("Content-Type", "application/json")
+		// Fail the second batch of DELETE requests.
+		if r.Method == "DELETE" && len(batches) == 5 {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error": {"code": "BadRequestError", "message": "bad"}}`))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+
+	items := make([]Descriptor, 250)
+	for i := range items {
+		items[i] = Descriptor{Type: "domain", ID: fmt.Sprintf("%d.example.com", i)}
+	}
+
+	assert.NoError(t, c.AddRelationshipItems(URL("collections/1234"), "domains", items))
+
+	err := c.RemoveRelationshipItems(URL("collections/1234"), "domains", items)
+	assert.Error(t, err)
+	var multi *MultiError
+	assert.True(t, errors.As(err, &multi))
+	assert.Len(t, multi.Errors, 100)
+	assert.Equal(t, 100, multi.Indexes()[0])
+	assert.Equal(t, "100.example.com", multi.IDs()[0])
+
+	assert.Equal(t, []string{"POST", "POST", "POST", "DELETE", "DELETE", "DELETE"}, requests)
+	assert.Equal(t, []int{100, 100, 50, 100, 100, 50}, batches)
+}
+
+func TestSetRelationship(t *testing.T) {
+	ts := NewTestServer(t).
+		SetExpectedMethod("POST").
+		SetExpectedBody(`{"data":{"attributes":{"name":"foo"},"relationships":{"domains":{"data":[{"type":"domain","id":"example.com"}]}},"type":"collection"}}`).
+		SetResponse(map[string]interface{}{
+			"data": map[string]interface{}{
+				"type": "collection",
+				"id":   "1234",
+			},
+		})
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+
+	obj := NewObject("collection")
+	obj.SetString("name", "foo")
+	assert.NoError(t, obj.SetRelationship("domains", Descriptor{Type: "domain", ID: "example.com"}))
+
+	r, err := obj.GetRelationship("domains")
+	assert.NoError(t, err)
+	assert.Equal(t, "example.com", r.Objects()[0].ID())
+
+	assert.NoError(t, c.PostObject(URL("collections"), obj))
+	assert.Equal(t, "1234", obj.ID())
+}
+
+func TestSetRelationshipAfterUnmarshal(t *testing.T) {
+	obj := NewObject("collection")
+	assert.NoError(t, obj.SetRelationship("domains"))
+	assert.NoError(t, json.Unmarshal([]byte(`{"type": "collection", "id": "1234"}`), obj))
+	_, err := json.Marshal(modifiedObject(*obj))
+	assert.NoError(t, err)
+}
+
+func TestRelatedDescriptors(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v3/files/1234/relationships/contacted_domains", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": [
+			{"type": "domain", "id": "example.com"},
+			{"type": "domain", "id": "example.org"}]}`))
+	}))
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+
+	it, err := c.RelatedDescriptors(URL("files/1234"), "contacted_domains")
+	assert.NoError(t, err)
+	defer it.Close()
+
+	assert.Equal(t, Descriptor{}, it.Descriptor())
+	descriptors := []Descriptor{}
+	for it.Next() {
+		descriptors = append(descriptors, it.Descriptor())
+	}
+	assert.NoError(t, it.Error())
+	assert.Equal(t, []Descriptor{
+		{Type: "domain", ID: "example.com"},
+		{Type: "domain", ID: "example.org"}}, descriptors)
+}
+
+func TestRelationshipPagination(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("cursor") {
+		case "2":
+			fmt.Fprintf(w, `{"data": [{"type": "domain", "id": "c.com"}, {"type": "domain", "id": "d.com"}],
+				"links": {"self": "%[1]s?cursor=2", "next": "%[1]s?cursor=4"}}`, "http://"+r.Host+r.URL.Path)
+		case "4":
+			fmt.Fprintf(w, `{"data": [{"type": "domain", "id": "e.com"}],
+				"links": {"self": "%s?cursor=4"}}`, "http://"+r.Host+r.URL.Path)
+		default:
+			t.Errorf("unexpected request %s", r.URL)
+		}
+	}))
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+
+	obj := &Object{}
+	err := json.Unmarshal([]byte(`{
+		"type": "file",
+		"id": "1234",
+		"relationships": {
+			"contacted_domains": {
+				"data": [{"type": "domain", "id": "a.com"}, {"type": "domain", "id": "b.com"}],
+				"links": {
+					"self": "`+ts.URL+`/api/v3/files/1234/relationships/contacted_domains",
+					"related": "`+ts.URL+`/api/v3/files/1234/contacted_domains",
+					"next": "`+ts.URL+`/api/v3/files/1234/relationships/contacted_domains?cursor=2"
+				}
+			}
+		}}`), obj)
+	assert.NoError(t, err)
+
+	r, err := obj.GetRelationship("contacted_domains")
+	assert.NoError(t, err)
+	assert.True(t, r.HasNext())
+
+	page, err := r.Next(c)
+	assert.NoError(t, err)
+	assert.Len(t, page.Objects(), 2)
+	assert.Equal(t, "c.com", page.Objects()[0].ID())
+	assert.Equal(t, r.Links().Related, page.Links().Related)
+	assert.True(t, page.HasNext())
+
+	objs, err := r.AllObjects(c)
+	assert.NoError(t, err)
+	ids := []string{}
+	for _, o := range objs {
+		ids = append(ids, o.ID())
+	}
+	assert.Equal(t, []string{"a.com", "b.com", "c.com", "d.com", "e.com"}, ids)
+
+	last, err := page.Next(c)
+	assert.NoError(t, err)
+	assert.False(t, last.HasNext())
+	_, err = last.Next(c)
+	assert.Error(t, err)
+}
@@ -0,0 +1,197 @@
+// Copyright © 2017 The vt-go authors. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vt
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// RenderFormat is the format of the human-readable representation of an
+// object produced by Render.
+type RenderFormat int
+
+const (
+	// RenderYAML renders the object as a YAML document, similar to the output
+	// of vt-cli.
+	RenderYAML RenderFormat = iota
+	// RenderText renders the object as plain text, with one "path: value" line
+	// per attribute. Paths use the syntax accepted by Get.
+	RenderText
+	// RenderMarkdown renders the object as a Markdown table with one row per
+	// attribute.
+	RenderMarkdown
+)
+
+// Render returns a human-readable representation of the object's ID, type and
+// attributes in the given format, which is useful for command-line tools and
+// debug dumps. Attributes are sorted by name.
+func (obj *Object) Render(format RenderFormat) (string, error) {
+	obj.rlock()
+	defer obj.runlock()
+	var b strings.Builder
+	switch format {
+	case RenderYAML:
+		b.WriteString("_id: " + renderYAMLScalar(obj.data.ID) + "\n")
+		b.WriteString("_type: " + renderYAMLScalar(obj.data.Type) + "\n")
+		renderYAML(&b, obj.data.Attributes, 0)
+	case RenderText:
+		b.WriteString("_id: " + obj.data.ID + "\n")
+		b.WriteString("_type: " + obj.data.Type + "\n")
+		renderFlat(obj.data.Attributes, "", func(path, value string) {
+			b.WriteString(path + ": " + value + "\n")
+		})
+	case RenderMarkdown:
+		b.WriteString(fmt.Sprintf("### %s %s\n\n", obj.data.Type, obj.data.ID))
+		b.WriteString("| Attribute | Value |\n")
+		b.WriteString("|-----------|-------|\n")
+		renderFlat(obj.data.Attributes, "", func(path, value string) {
+			b.WriteString("| " + escapeMarkdown(path) + " | " + escapeMarkdown(value) + " |\n")
+		})
+	default:
+		return "", fmt.Errorf("unknown render format %d", format)
+	}
+	return b.String(), nil
+}
+
+// sortedKeys returns the keys in a map sorted alphabetically.
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// renderScalar returns the textual representation of a value that is not a
+// map nor a slice. Empty maps and slices are rendered as "{}" and "[]".
+func renderScalar(v interface{}) string {
+	switch value := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return value
+	case json.Number:
+		return value.String()
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case map[string]interface{}:
+		return "{}"
+	case []interface{}:
+		return "[]"
+	}
+	return fmt.Sprint(v)
+}
+
+// isContainer returns true if v is a non-empty map or slice.
+func isContainer(v interface{}) bool {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		return len(value) > 0
+	case []interface{}:
+		return len(value) > 0
+	}
+	return false
+}
+
+// renderFlat calls emit for every scalar value within v, passing the value's
+// path and its textual representation.
+func renderFlat(v interface{}, path string, emit func(path, value string)) {
+	join := func(elem string) string {
+		if path == "" {
+			return elem
+		}
+		return path + "." + elem
+	}
+	switch value := v.(type) {
+	case map[string]interface{}:
+		if len(value) == 0 && path != "" {
+			emit(path, "{}")
+		}
+		for _, key := range sortedKeys(value) {
+			renderFlat(value[key], join(key), emit)
+		}
+	case []interface{}:
+		if len(value) == 0 {
+			emit(path, "[]")
+		}
+		for i, item := range value {
+			renderFlat(item, join(fmt.Sprintf("[%d]", i)), emit)
+		}
+	default:
+		emit(path, renderScalar(v))
+	}
+}
+
+// renderYAML writes v to b as YAML, indented with the given number of spaces.
+func renderYAML(b *strings.Builder, v interface{}, indent int) {
+	prefix := strings.Repeat(" ", indent)
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for _, key := range sortedKeys(value) {
+			b.WriteString(prefix + renderYAMLScalar(key) + ":")
+			if isContainer(value[key]) {
+				b.WriteString("\n")
+				renderYAML(b, value[key], indent+2)
+			} else {
+				b.WriteString(" " + renderYAMLScalar(value[key]) + "\n")
+			}
+		}
+	case []interface{}:
+		for _, item := range value {
+			if !isContainer(item) {
+				b.WriteString(prefix + "- " + renderYAMLScalar(item) + "\n")
+				continue
+			}
+			// Render the item with an additional indentation level and
+			// then replace the indentation in the first line with the dash.
+			var nested strings.Builder
+			renderYAML(&nested, item, indent+2)
+			b.WriteString(prefix + "- " + nested.String()[indent+2:])
+		}
+	}
+}
+
+// renderYAMLScalar returns the YAML representation of a value that is not a
+// non-empty map or slice. Strings are quoted when they could be parsed as
+// something else, or contain characters with a special meaning in YAML.
+func renderYAMLScalar(v interface{}) string {
+	s, ok := v.(string)
+	if !ok {
+		return renderScalar(v)
+	}
+	if s == "" || strings.TrimSpace(s) != s || strings.ContainsAny(s, "\n\r\t\"") ||
+		strings.Contains(s, ": ") || strings.Contains(s, " #") ||
+		strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'%@`") {
+		return strconv.Quote(s)
+	}
+	switch strings.ToLower(s) {
+	case "true", "false", "yes", "no", "on", "off", "null", "~":
+		return strconv.Quote(s)
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// escapeMarkdown escapes the characters that would break a Markdown table.
+func escapeMarkdown(s string) string {
+	s = strings.Replace(s, "|", "\\|", -1)
+	return strings.Replace(s, "\n", "<br>", -1)
+}
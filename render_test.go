@@ -0,0 +1,66 @@
+package vt
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	obj := &Object{}
+	err := json.Unmarshal([]byte(`{
+		"type": "file",
+		"id": "1234",
+		"attributes": {
+			"size": 1024,
+			"names": ["foo.exe", "true"],
+			"tags": [],
+			"last_analysis_stats": {"malicious": 3, "undetected": 60},
+			"sections": [{"name": ".text", "entropy": 6.5}],
+			"comment": "a | b"
+		}}`), obj)
+	assert.NoError(t, err)
+
+	yaml, err := obj.Render(RenderYAML)
+	assert.NoError(t, err)
+	assert.Equal(t, `_id: "1234"
+_type: file
+comment: a | b
+last_analysis_stats:
+  malicious: 3
+  undetected: 60
+names:
+  - foo.exe
+  - "true"
+sections:
+  - entropy: 6.5
+    name: .text
+size: 1024
+tags: []
+`, yaml)
+
+	text, err := obj.Render(RenderText)
+	assert.NoError(t, err)
+	assert.Equal(t, `_id: 1234
+_type: file
+comment: a | b
+last_analysis_stats.malicious: 3
+last_analysis_stats.undetected: 60
+names.[0]: foo.exe
+names.[1]: true
+sections.[0].entropy: 6.5
+sections.[0].name: .text
+size: 1024
+tags: []
+`, text)
+
+	md, err := obj.Render(RenderMarkdown)
+	assert.NoError(t, err)
+	assert.Contains(t, md, "### file 1234\n")
+	assert.Contains(t, md, "| comment | a \\| b |\n")
+	assert.Contains(t, md, "| sections.[0].name | .text |\n")
+
+	_, err = obj.Render(RenderFormat(100))
+	assert.Error(t, err)
+}
@@ -0,0 +1,155 @@
+// Copyright © 2017 The vt-go authors. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vt
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SearchEntity is the kind of objects returned by an intelligence search, as
+// specified with the "entity" modifier in the query.
+type SearchEntity string
+
+const (
+	// EntityFile is used by queries without an "entity" modifier.
+	EntityFile SearchEntity = "file"
+	// EntityDomain is used by queries with "entity:domain".
+	EntityDomain SearchEntity = "domain"
+	// EntityURL is used by queries with "entity:url".
+	EntityURL SearchEntity = "url"
+	// EntityIPAddress is used by queries with "entity:ip".
+	EntityIPAddress SearchEntity = "ip"
+)
+
+// Commonly used search modifiers for each entity. The list is not exhaustive,
+// the VirusTotal Intelligence documentation has the complete list.
+var searchModifiers = map[SearchEntity][]string{
+	EntityFile: {
+		"type", "size", "p", "positives", "tag", "name", "engines",
+		"fs", "ls", "submitter", "content", "signature"},
+	EntityDomain: {
+		"entity", "domain", "tld", "p", "tag", "registrar", "whois",
+		"creation_date", "last_update_date", "category"},
+	EntityURL: {
+		"entity", "url", "p", "tag", "title", "fs", "ls",
+		"response_code", "category"},
+	EntityIPAddress: {
+		"entity", "ip", "p", "tag", "asn", "country", "whois"},
+}
+
+// Modifiers returns the most commonly used search modifiers for the entity.
+// The list is not exhaustive, and queries are not restricted to these
+// modifiers.
+func (e SearchEntity) Modifiers() []string {
+	return append([]string(nil), searchModifiers[e]...)
+}
+
+// QueryEntity returns the entity specified with the "entity" modifier in an
+// intelligence search query, or EntityFile if the query doesn't have it.
+func QueryEntity(query string) SearchEntity {
+	for _, term := range strings.Fields(query) {
+		if strings.HasPrefix(term, "entity:") {
+			return SearchEntity(strings.TrimPrefix(term, "entity:"))
+		}
+	}
+	return EntityFile
+}
+
+// searchEntity returns an iterator over the results of a query for the given
+// entity, adding the "entity" modifier to the query if missing.
+func (cli *Client) searchEntity(entity SearchEntity, query string, options ...IteratorOption) (*Iterator, error) {
+	switch e := QueryEntity(query); {
+	case e == entity:
+	case e == EntityFile && !strings.Contains(query, "entity:file"):
+		query = fmt.Sprintf("entity:%s %s", entity, query)
+	default:
+		return nil, fmt.Errorf("query for entity \"%s\" used for searching %ss", e, entity)
+	}
+	return cli.Search(query, options...)
+}
+
+// SearchDomains returns an iterator over the domains that match an
+// intelligence search query. The query doesn't need to include
+// "entity:domain", it's added if missing. Example:
+//
+//	it, err := client.SearchDomains("registrar:namecheap creation_date:2024-01-01+")
+func (cli *Client) SearchDomains(query string, options ...IteratorOption) (*DomainIterator, error) {
+	it, err := cli.searchEntity(EntityDomain, query, options...)
+	if err != nil {
+		return nil, err
+	}
+	return &DomainIterator{Iterator: it}, nil
+}
+
+// SearchURLs returns an iterator over the URLs that match an intelligence
+// search query. The query doesn't need to include "entity:url", it's added if
+// missing.
+func (cli *Client) SearchURLs(query string, options ...IteratorOption) (*URLObjectIterator, error) {
+	it, err := cli.searchEntity(EntityURL, query, options...)
+	if err != nil {
+		return nil, err
+	}
+	return &URLObjectIterator{Iterator: it}, nil
+}
+
+// SearchIPAddresses returns an iterator over the IP addresses that match an
+// intelligence search query. The query doesn't need to include "entity:ip",
+// it's added if missing.
+func (cli *Client) SearchIPAddresses(query string, options ...IteratorOption) (*IPAddressIterator, error) {
+	it, err := cli.searchEntity(EntityIPAddress, query, options...)
+	if err != nil {
+		return nil, err
+	}
+	return &IPAddressIterator{Iterator: it}, nil
+}
+
+// DomainIterator is an iterator that returns Domain objects.
+type DomainIterator struct {
+	*Iterator
+}
+
+// Get returns the current domain in the iterator.
+func (it *DomainIterator) Get() *Domain {
+	if obj := it.Iterator.Get(); obj != nil {
+		return NewDomain(obj)
+	}
+	return nil
+}
+
+// URLObjectIterator is an iterator that returns URLObject objects.
+type URLObjectIterator struct {
+	*Iterator
+}
+
+// Get returns the current URL in the iterator.
+func (it *URLObjectIterator) Get() *URLObject {
+	if obj := it.Iterator.Get(); obj != nil {
+		return NewURLObject(obj)
+	}
+	return nil
+}
+
+// IPAddressIterator is an iterator that returns IPAddress objects.
+type IPAddressIterator struct {
+	*Iterator
+}
+
+// Get returns the current IP address in the iterator.
+func (it *IPAddressIterator) Get() *IPAddress {
+	if obj := it.Iterator.Get(); obj != nil {
+		return NewIPAddress(obj)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package vt
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryEntity(t *testing.T) {
+	assert.Equal(t, EntityFile, QueryEntity("p:10+ size:30MB+"))
+	assert.Equal(t, EntityDomain, QueryEntity("entity:domain tld:xyz"))
+	assert.Equal(t, EntityIPAddress, QueryEntity("country:ES entity:ip"))
+	assert.Contains(t, EntityURL.Modifiers(), "title")
+}
+
+func TestSearchDomains(t *testing.T) {
+	var query string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query().Get("query")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": [{"type": "domain", "id": "example.xyz",
+			"attributes": {"registrar": "Namecheap"}}]}`))
+	}))
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+
+	it, err := c.SearchDomains("tld:xyz")
+	assert.NoError(t, err)
+	defer it.Close()
+	assert.True(t, it.Next())
+	assert.Equal(t, "entity:domain tld:xyz", query)
+	registrar, err := it.Get().Registrar()
+	assert.NoError(t, err)
+	assert.Equal(t, "Namecheap", registrar)
+	assert.False(t, it.Next())
+	assert.NoError(t, it.Error())
+
+	it, err = c.SearchDomains("entity:domain tld:xyz")
+	assert.NoError(t, err)
+	defer it.Close()
+	assert.True(t, it.Next())
+	assert.Equal(t, "entity:domain tld:xyz", query)
+
+	_, err = c.SearchIPAddresses("entity:domain tld:xyz")
+	assert.Error(t, err)
+	_, err = c.SearchURLs("entity:file p:5+")
+	assert.Error(t, err)
+}
@@ -0,0 +1,114 @@
+// Copyright © 2017 The vt-go authors. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vt
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+// SearchExportFormat is the format of the file generated by a search export.
+type SearchExportFormat string
+
+const (
+	// SearchExportCSV generates a CSV file with a row per object.
+	SearchExportCSV SearchExportFormat = "csv"
+	// SearchExportJSON generates a file with a JSON object per line.
+	SearchExportJSON SearchExportFormat = "json"
+)
+
+// SearchExport is an Object of type "intelligence_search_export", which
+// represents a job that exports the results of an intelligence search to a
+// file that can be downloaded once the job is finished.
+type SearchExport struct {
+	*Object
+}
+
+// NewSearchExport returns a SearchExport from an Object of type
+// "intelligence_search_export".
+func NewSearchExport(obj *Object) *SearchExport {
+	return &SearchExport{Object: obj}
+}
+
+// ExportSearch requests an export of the results of an intelligence search
+// query in the given format. If attributes are specified, only those
+// attributes are exported for each object. The export is generated in
+// background, use WaitForCompletion for waiting until it's ready to be
+// downloaded. Example:
+//
+//	export, err := client.ExportSearch("p:10+ tag:upx", vt.SearchExportCSV, "sha256", "size")
+//	if err != nil {
+//		...handle error
+//	}
+//	export, err = export.WaitForCompletion(ctx, client, 30*time.Second)
+//	if err != nil {
+//		...handle error
+//	}
+//	_, err = export.Download(client, f)
+func (cli *Client) ExportSearch(query string, format SearchExportFormat, attributes ...string) (*SearchExport, error) {
+	obj := NewObject("intelligence_search_export")
+	obj.SetString("query", query)
+	obj.SetString("format", string(format))
+	if len(attributes) > 0 {
+		obj.Set("attributes", attributes)
+	}
+	if err := cli.PostObject(URL("intelligence/search_exports"), obj); err != nil {
+		return nil, err
+	}
+	return NewSearchExport(obj), nil
+}
+
+// Status returns the export's status, which can be "queued", "running",
+// "finished" or "failed".
+func (e *SearchExport) Status() (string, error) {
+	return e.GetString("status")
+}
+
+// finished returns true if the export's status is "finished" or "failed".
+func (e *SearchExport) finished() bool {
+	status, _ := e.Status()
+	return status == "finished" || status == "failed"
+}
+
+// WaitForCompletion polls the export until it's finished and returns the
+// finished export, the receiver is not modified. An error is returned if the
+// export failed. The first polls are separated by pollInterval, but the
+// interval grows on each poll as described in WaitFor, whose options can be
+// used for tweaking the polling.
+func (e *SearchExport) WaitForCompletion(ctx context.Context, cli *Client, pollInterval time.Duration, options ...WaitOption) (*SearchExport, error) {
+	finished := e
+	if !e.finished() {
+		options = append([]WaitOption{WaitInterval(pollInterval)}, options...)
+		obj, err := cli.WaitFor(ctx, URL("intelligence/search_exports/%s", e.ID()),
+			func(o *Object) bool {
+				return NewSearchExport(o).finished()
+			}, options...)
+		if err != nil {
+			return nil, err
+		}
+		finished = NewSearchExport(obj)
+	}
+	if status, _ := finished.Status(); status == "failed" {
+		return nil, fmt.Errorf("search export \"%s\" failed", e.ID())
+	}
+	return finished, nil
+}
+
+// Download writes the file generated by a finished export into the provided
+// io.Writer, returning the number of bytes written.
+func (e *SearchExport) Download(cli *Client, w io.Writer) (int64, error) {
+	return cli.GetRaw(URL("intelligence/search_exports/%s/download", e.ID()), w)
+}
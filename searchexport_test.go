@@ -0,0 +1,75 @@
+package vt
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchExport(t *testing.T) {
+	polls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/api/v3/intelligence/search_exports":
+			var req struct {
+				Data struct {
+					Attributes map[string]interface{} `json:"attributes"`
+				} `json:"data"`
+			}
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			assert.Equal(t, "p:10+", req.Data.Attributes["query"])
+			assert.Equal(t, "csv", req.Data.Attributes["format"])
+			assert.Equal(t, []interface{}{"sha256"}, req.Data.Attributes["attributes"])
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"data": {"type": "intelligence_search_export", "id": "e1",
+				"attributes": {"status": "queued"}}}`))
+		case r.URL.Path == "/api/v3/intelligence/search_exports/e1":
+			polls++
+			status := "running"
+			if polls > 1 {
+				status = "finished"
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"data": {"type": "intelligence_search_export", "id": "e1",
+				"attributes": {"status": "` + status + `"}}}`))
+		case r.URL.Path == "/api/v3/intelligence/search_exports/e1/download":
+			w.Write([]byte("sha256\nabcd\n"))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+	}))
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+
+	export, err := c.ExportSearch("p:10+", SearchExportCSV, "sha256")
+	assert.NoError(t, err)
+	status, _ := export.Status()
+	assert.Equal(t, "queued", status)
+
+	export, err = export.WaitForCompletion(context.Background(), c, time.Millisecond)
+	assert.NoError(t, err)
+	status, _ = export.Status()
+	assert.Equal(t, "finished", status)
+	assert.Equal(t, 2, polls)
+
+	var b bytes.Buffer
+	n, err := export.Download(c, &b)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12), n)
+	assert.Equal(t, "sha256\nabcd\n", b.String())
+}
+
+func TestSearchExportFailed(t *testing.T) {
+	obj := NewObjectWithID("intelligence_search_export", "e1")
+	obj.SetString("status", "failed")
+	_, err := NewSearchExport(obj).WaitForCompletion(context.Background(), nil, time.Millisecond)
+	assert.EqualError(t, err, "search export \"e1\" failed")
+}
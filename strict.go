@@ -0,0 +1,116 @@
+// Copyright © 2017 The vt-go authors. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vt
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// UnmarshalMode determines how objects received from the API are checked
+// while being unmarshalled.
+type UnmarshalMode int32
+
+const (
+	// UnmarshalLenient accepts any payload that can be decoded, payloads that
+	// don't have the expected structure can produce empty objects. This is the
+	// default mode.
+	UnmarshalLenient UnmarshalMode = iota
+	// UnmarshalWarn works like UnmarshalLenient, but the problems found in the
+	// payload are recorded in the object, and can be obtained with
+	// Object.UnmarshalWarnings.
+	UnmarshalWarn
+	// UnmarshalStrict makes unmarshalling fail if the payload doesn't have the
+	// structure expected for an object.
+	UnmarshalStrict
+)
+
+var unmarshalMode int32
+
+// SetUnmarshalMode sets the mode used for unmarshalling objects, which is
+// UnmarshalLenient by default. The strict modes are useful for catching
+// changes in the API early, as problems that would otherwise go unnoticed
+// are reported. The mode affects all the objects unmarshalled after the call.
+func SetUnmarshalMode(mode UnmarshalMode) {
+	atomic.StoreInt32(&unmarshalMode, int32(mode))
+}
+
+func getUnmarshalMode() UnmarshalMode {
+	return UnmarshalMode(atomic.LoadInt32(&unmarshalMode))
+}
+
+// Fields that can appear in the JSON representation of an object.
+var objectFields = map[string]bool{
+	"id":                 true,
+	"type":               true,
+	"attributes":         true,
+	"context_attributes": true,
+	"relationships":      true,
+	"links":              true,
+}
+
+// checkObjectPayload returns the problems found in the JSON representation
+// of an object, like not being a JSON object at all, lacking a type or having
+// unknown fields.
+func checkObjectPayload(data []byte) []string {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		payload := string(trimmed)
+		if len(payload) > 20 {
+			payload = payload[:20] + "..."
+		}
+		return []string{fmt.Sprintf("expecting a JSON object, got: %s", payload)}
+	}
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(trimmed, &fields); err != nil {
+		return []string{err.Error()}
+	}
+	var problems []string
+	var objType string
+	if raw, ok := fields["type"]; !ok {
+		problems = append(problems, "missing object type")
+	} else if json.Unmarshal(raw, &objType) != nil || objType == "" {
+		problems = append(problems, "invalid object type")
+	}
+	unknown := []string{}
+	for field := range fields {
+		if !objectFields[field] {
+			unknown = append(unknown, field)
+		}
+	}
+	sort.Strings(unknown)
+	for _, field := range unknown {
+		problems = append(problems, fmt.Sprintf("unknown field \"%s\"", field))
+	}
+	return problems
+}
+
+// UnmarshalWarnings returns the problems found while unmarshalling the object
+// when the unmarshalling mode is UnmarshalWarn. See SetUnmarshalMode.
+func (obj *Object) UnmarshalWarnings() []string {
+	obj.rlock()
+	defer obj.runlock()
+	return obj.warnings
+}
+
+// unmarshalError returns an error describing the problems found in the JSON
+// representation of an object.
+func unmarshalError(problems []string) error {
+	return errors.New("invalid object: " + strings.Join(problems, "; "))
+}
@@ -0,0 +1,50 @@
+package vt
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnmarshalMode(t *testing.T) {
+	defer SetUnmarshalMode(UnmarshalLenient)
+
+	valid := []byte(`{"type": "file", "id": "1234", "attributes": {"size": 1}}`)
+	unknown := []byte(`{"type": "file", "id": "1234", "foo": 1, "bar": 2}`)
+
+	obj := &Object{}
+	assert.NoError(t, json.Unmarshal([]byte(`null`), obj))
+	assert.NoError(t, json.Unmarshal(unknown, obj))
+	assert.Empty(t, obj.UnmarshalWarnings())
+
+	SetUnmarshalMode(UnmarshalWarn)
+	obj = &Object{}
+	assert.NoError(t, json.Unmarshal(unknown, obj))
+	assert.Equal(t, []string{
+		"unknown field \"bar\"",
+		"unknown field \"foo\""}, obj.UnmarshalWarnings())
+	assert.Equal(t, []string{
+		"unknown field \"bar\"",
+		"unknown field \"foo\""}, obj.Copy().UnmarshalWarnings())
+
+	SetUnmarshalMode(UnmarshalStrict)
+	obj = &Object{}
+	assert.NoError(t, json.Unmarshal(valid, obj))
+	assert.Empty(t, obj.UnmarshalWarnings())
+	assert.Equal(t, "1234", obj.ID())
+
+	assert.Error(t, json.Unmarshal(unknown, &Object{}))
+	assert.Error(t, json.Unmarshal([]byte(`null`), &Object{}))
+	assert.Error(t, json.Unmarshal([]byte(`"foo"`), &Object{}))
+	assert.Error(t, json.Unmarshal([]byte(`{"id": "1234"}`), &Object{}))
+
+	// Empty one-to-one relationships are accepted in strict mode.
+	obj = &Object{}
+	assert.NoError(t, json.Unmarshal([]byte(`{"type": "file", "id": "1234",
+		"relationships": {"parent": {"data": null}}}`), obj))
+	r, err := obj.GetRelationship("parent")
+	assert.NoError(t, err)
+	assert.True(t, r.IsOneToOne())
+	assert.Empty(t, r.Objects())
+}
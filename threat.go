@@ -0,0 +1,166 @@
+// Copyright © 2017 The vt-go authors. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vt
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Severity is the severity of a threat, as computed by VirusTotal for files,
+// URLs, domains and IP addresses.
+type Severity int
+
+// Possible values for Severity, sorted from lowest to highest severity.
+const (
+	SeverityUnknown Severity = iota
+	SeverityNone
+	SeverityLow
+	SeverityMedium
+	SeverityHigh
+)
+
+var severityNames = []string{"unknown", "none", "low", "medium", "high"}
+
+// String returns the severity's name in lowercase, like "high".
+func (s Severity) String() string {
+	if s >= 0 && int(s) < len(severityNames) {
+		return severityNames[s]
+	}
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+// ParseSeverity converts the different representations of a severity used by
+// the API, like "SEVERITY_HIGH", "high" or "High", into a Severity.
+func ParseSeverity(s string) (Severity, error) {
+	name := strings.TrimPrefix(strings.ToLower(strings.TrimSpace(s)), "severity_")
+	for i, n := range severityNames {
+		if name == n {
+			return Severity(i), nil
+		}
+	}
+	return SeverityUnknown, fmt.Errorf("unknown severity \"%s\"", s)
+}
+
+// Verdict is the verdict about an object being malicious or not, as computed
+// by VirusTotal.
+type Verdict int
+
+// Possible values for Verdict.
+const (
+	VerdictUnknown Verdict = iota
+	VerdictBenign
+	VerdictUndetected
+	VerdictSuspicious
+	VerdictMalicious
+)
+
+var verdictNames = []string{"unknown", "benign", "undetected", "suspicious", "malicious"}
+
+// String returns the verdict's name in lowercase, like "malicious".
+func (v Verdict) String() string {
+	if v >= 0 && int(v) < len(verdictNames) {
+		return verdictNames[v]
+	}
+	return fmt.Sprintf("Verdict(%d)", int(v))
+}
+
+// ParseVerdict converts the different representations of a verdict used by
+// the API, like "VERDICT_MALICIOUS" or "malicious", into a Verdict.
+func ParseVerdict(s string) (Verdict, error) {
+	name := strings.TrimPrefix(strings.ToLower(strings.TrimSpace(s)), "verdict_")
+	for i, n := range verdictNames {
+		if name == n {
+			return Verdict(i), nil
+		}
+	}
+	return VerdictUnknown, fmt.Errorf("unknown verdict \"%s\"", s)
+}
+
+// ThreatSeverity contains the "threat_severity" attribute of files, URLs,
+// domains and IP addresses.
+type ThreatSeverity struct {
+	// Level is the severity level as returned by the API, like
+	// "SEVERITY_HIGH". Use Severity for obtaining it as a Severity.
+	Level            string      `json:"threat_severity_level"`
+	LevelDescription string      `json:"level_description"`
+	Version          json.Number `json:"version"`
+	// Data contains the factors that contributed to the severity level.
+	Data map[string]interface{} `json:"threat_severity_data"`
+	// LastAnalysisDate is a UNIX timestamp, which can be sent by the API as a
+	// number or a string. Use LastUpdate for obtaining it as a time.
+	LastAnalysisDate interface{} `json:"last_analysis_date"`
+}
+
+// Severity returns the severity level.
+func (t *ThreatSeverity) Severity() (Severity, error) {
+	return ParseSeverity(t.Level)
+}
+
+// LastUpdate returns the date in which the severity was computed.
+func (t *ThreatSeverity) LastUpdate() (time.Time, error) {
+	var ts int64
+	var err error
+	switch v := t.LastAnalysisDate.(type) {
+	case float64:
+		ts = int64(v)
+	case string:
+		ts, err = strconv.ParseInt(v, 10, 64)
+	default:
+		err = fmt.Errorf("invalid last analysis date: %v", v)
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(ts, 0), nil
+}
+
+// ThreatSeverity returns the "threat_severity" attribute.
+func (obj *Object) ThreatSeverity() (*ThreatSeverity, error) {
+	ts := &ThreatSeverity{}
+	if err := obj.decodeAttribute("threat_severity", ts); err != nil {
+		return nil, err
+	}
+	return ts, nil
+}
+
+// ThreatVerdict returns the verdict about the object, taken from the
+// "threat_verdict" attribute or, if it doesn't exist, from the verdict in
+// the "gti_assessment" attribute.
+func (obj *Object) ThreatVerdict() (Verdict, error) {
+	for _, attr := range []string{"threat_verdict", "gti_assessment.verdict.value"} {
+		if s, err := obj.GetString(attr); err == nil {
+			return ParseVerdict(s)
+		}
+	}
+	return VerdictUnknown, fmt.Errorf("attribute \"threat_verdict\" does not exists")
+}
+
+// Severity returns the severity of the threat represented by the object,
+// normalized across the different attributes where it can appear. The
+// severity is taken from the "gti_assessment" attribute if present, or from
+// the "threat_severity" attribute otherwise.
+func (obj *Object) Severity() (Severity, error) {
+	for _, attr := range []string{
+		"gti_assessment.severity.value",
+		"threat_severity.threat_severity_level"} {
+		if s, err := obj.GetString(attr); err == nil {
+			return ParseSeverity(s)
+		}
+	}
+	return SeverityUnknown, fmt.Errorf("attribute \"threat_severity\" does not exists")
+}
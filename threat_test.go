@@ -0,0 +1,75 @@
+package vt
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSeverity(t *testing.T) {
+	for s, expected := range map[string]Severity{
+		"SEVERITY_HIGH":    SeverityHigh,
+		"medium":           SeverityMedium,
+		"Low":              SeverityLow,
+		"SEVERITY_NONE":    SeverityNone,
+		"SEVERITY_UNKNOWN": SeverityUnknown,
+	} {
+		severity, err := ParseSeverity(s)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, severity)
+	}
+	_, err := ParseSeverity("foo")
+	assert.Error(t, err)
+	assert.Equal(t, "high", SeverityHigh.String())
+}
+
+func TestThreatSeverity(t *testing.T) {
+	obj := &Object{}
+	err := json.Unmarshal([]byte(`{
+		"type": "file",
+		"id": "1234",
+		"attributes": {
+			"threat_verdict": "VERDICT_MALICIOUS",
+			"threat_severity": {
+				"version": 5,
+				"threat_severity_level": "SEVERITY_MEDIUM",
+				"level_description": "Some description",
+				"threat_severity_data": {"popular_threat_category": "trojan"},
+				"last_analysis_date": "1700000000"
+			}
+		}}`), obj)
+	assert.NoError(t, err)
+
+	ts, err := obj.ThreatSeverity()
+	assert.NoError(t, err)
+	assert.Equal(t, "trojan", ts.Data["popular_threat_category"])
+	severity, err := ts.Severity()
+	assert.NoError(t, err)
+	assert.Equal(t, SeverityMedium, severity)
+	date, err := ts.LastUpdate()
+	assert.NoError(t, err)
+	assert.Equal(t, time.Unix(1700000000, 0), date)
+
+	severity, err = obj.Severity()
+	assert.NoError(t, err)
+	assert.Equal(t, SeverityMedium, severity)
+
+	verdict, err := obj.ThreatVerdict()
+	assert.NoError(t, err)
+	assert.Equal(t, VerdictMalicious, verdict)
+
+	// The GTI assessment takes precedence over threat_severity.
+	obj.Set("gti_assessment", map[string]interface{}{
+		"severity": map[string]interface{}{"value": "SEVERITY_HIGH"},
+	})
+	severity, err = obj.Severity()
+	assert.NoError(t, err)
+	assert.Equal(t, SeverityHigh, severity)
+
+	_, err = NewObject("file").Severity()
+	assert.Error(t, err)
+	_, err = NewObject("file").ThreatVerdict()
+	assert.Error(t, err)
+}
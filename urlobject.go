@@ -0,0 +1,59 @@
+// Copyright © 2017 The vt-go authors. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vt
+
+// URLObject is an Object of type "url", it provides typed accessors for the
+// most relevant attributes of a URL, while the generic methods from Object
+// are still available.
+type URLObject struct {
+	*Object
+}
+
+// NewURLObject returns a URLObject from an Object of type "url".
+func NewURLObject(obj *Object) *URLObject {
+	return &URLObject{Object: obj}
+}
+
+// URL returns the URL as it was submitted to VirusTotal.
+func (u *URLObject) URL() (string, error) {
+	return u.GetString("url")
+}
+
+// LastFinalURL returns the URL reached after following redirections the last
+// time the URL was analysed.
+func (u *URLObject) LastFinalURL() (string, error) {
+	return u.GetString("last_final_url")
+}
+
+// Title returns the title of the web page the last time the URL was analysed.
+func (u *URLObject) Title() (string, error) {
+	return u.GetString("title")
+}
+
+// LastHTTPResponseCode returns the HTTP status code returned by the server the
+// last time the URL was analysed.
+func (u *URLObject) LastHTTPResponseCode() (int64, error) {
+	return u.GetInt64("last_http_response_code")
+}
+
+// Categories returns the categories assigned to the URL by each vendor.
+func (u *URLObject) Categories() (Categories, error) {
+	return u.GetCategories()
+}
+
+// Reputation returns the URL's reputation score, as computed from the votes
+// of the VirusTotal community.
+func (u *URLObject) Reputation() (int64, error) {
+	return u.GetInt64("reputation")
+}
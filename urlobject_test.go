@@ -0,0 +1,50 @@
+package vt
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestURLObject(t *testing.T) {
+	obj := &Object{}
+	err := json.Unmarshal([]byte(`{
+		"type": "url",
+		"id": "1234",
+		"attributes": {
+			"url": "http://example.com",
+			"last_final_url": "https://example.com/",
+			"title": "Example Domain",
+			"last_http_response_code": 200,
+			"categories": {"Forcepoint ThreatSeeker": "information technology"},
+			"reputation": -3
+		}}`), obj)
+	assert.NoError(t, err)
+
+	u := NewURLObject(obj)
+
+	s, err := u.URL()
+	assert.NoError(t, err)
+	assert.Equal(t, "http://example.com", s)
+
+	s, err = u.LastFinalURL()
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/", s)
+
+	s, err = u.Title()
+	assert.NoError(t, err)
+	assert.Equal(t, "Example Domain", s)
+
+	code, err := u.LastHTTPResponseCode()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(200), code)
+
+	categories, err := u.Categories()
+	assert.NoError(t, err)
+	assert.Len(t, categories, 1)
+
+	reputation, err := u.Reputation()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(-3), reputation)
+}
@@ -15,18 +15,121 @@ package vt
 
 import (
 	"bytes"
+	"context"
+	"encoding/base64"
 	"encoding/json"
 	"mime/multipart"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
 )
 
 // URLScanner represents a URL scanner.
 type URLScanner struct {
-	cli *Client
+	cli           *Client
+	stripFragment bool
+	// Options used by ScanAll, see URLScannerWorkers,
+	// URLScannerRequestsPerMinute and URLScannerRetries.
+	workers    int
+	interval   time.Duration
+	maxRetries int
+	retryDelay time.Duration
 }
 
-// Scan sends a URL to VirusTotal for scanning. An analysis object is returned
-// as soon as the URL is submitted.
-func (s *URLScanner) Scan(url string) (*Object, error) {
+// URLScannerOption represents an option passed to NewURLScannerWithOptions.
+type URLScannerOption func(*URLScanner)
+
+// URLScannerStripFragment specifies whether or not the fragment (the part
+// after "#") must be removed from URLs before submitting them to VirusTotal.
+func URLScannerStripFragment(b bool) URLScannerOption {
+	return func(s *URLScanner) {
+		s.stripFragment = b
+	}
+}
+
+// URLScannerWorkers specifies the maximum number of URLs that ScanAll submits
+// in parallel. The default is 4.
+func URLScannerWorkers(n int) URLScannerOption {
+	return func(s *URLScanner) {
+		s.workers = n
+	}
+}
+
+// URLScannerRequestsPerMinute limits the number of URLs submitted per minute
+// by ScanAll, including retries, which is useful for staying within the quota
+// of an API key. By default the number of requests is not limited.
+func URLScannerRequestsPerMinute(n int) URLScannerOption {
+	return func(s *URLScanner) {
+		if n > 0 {
+			s.interval = time.Minute / time.Duration(n)
+		}
+	}
+}
+
+// URLScannerRetries specifies the number of times that ScanAll retries a
+// submission failing with a transient error, and the delay between retries,
+// see BulkLookupRetries for how retries are spaced. The default is 3 retries
+// with a delay of 5 seconds.
+func URLScannerRetries(n int, delay time.Duration) URLScannerOption {
+	return func(s *URLScanner) {
+		s.maxRetries = n
+		s.retryDelay = delay
+	}
+}
+
+// NormalizeURL returns a normalized form of the given URL. The scheme and host
+// are lowercased, internationalized domain names are converted to their
+// Punycode representation, and the fragment is removed if stripFragment is
+// true. URLs without a scheme are assumed to be "http" URLs.
+func NormalizeURL(rawURL string, stripFragment bool) (string, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "http://" + rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	host, err := toASCIIHost(u.Hostname())
+	if err != nil {
+		return "", err
+	}
+	if port := u.Port(); port != "" {
+		u.Host = net.JoinHostPort(host, port)
+	} else if strings.Contains(host, ":") {
+		// IPv6 literals must be enclosed in brackets.
+		u.Host = "[" + host + "]"
+	} else {
+		u.Host = host
+	}
+	if stripFragment {
+		u.Fragment = ""
+		u.RawFragment = ""
+	}
+	return u.String(), nil
+}
+
+// Normalize returns the normalized form of a URL, exactly as it would be
+// submitted by Scan. See NormalizeURL for details.
+func (s *URLScanner) Normalize(url string) (string, error) {
+	return NormalizeURL(url, s.stripFragment)
+}
+
+// Scan sends a URL to VirusTotal for scanning. An analysis is returned as soon
+// as the URL is submitted, see Analysis.WaitForCompletion for waiting until
+// it's completed. The URL is normalized before being sent, so that trivial
+// variations of the same URL don't produce different URL objects in
+// VirusTotal. The URL actually submitted is available in the "url" context
+// attribute of the returned analysis.
+func (s *URLScanner) Scan(url string) (*Analysis, error) {
+
+	url, err := s.Normalize(url)
+	if err != nil {
+		return nil, err
+	}
 
 	b := bytes.Buffer{}
 	w := multipart.NewWriter(&b)
@@ -60,5 +163,122 @@ func (s *URLScanner) Scan(url string) (*Object, error) {
 		return nil, err
 	}
 
-	return analysis, nil
+	if analysis.data.ContextAttributes == nil {
+		analysis.data.ContextAttributes = make(map[string]interface{})
+	}
+	analysis.data.ContextAttributes["url"] = url
+
+	return NewAnalysis(analysis), nil
+}
+
+// ScanAndWait sends a URL to VirusTotal for scanning like Scan, waits until
+// the analysis is completed and returns the URL object with the results. The
+// analysis is polled as described in Analysis.WaitForCompletion, the polling
+// can be tweaked with the given options, and it stops with an error when ctx
+// is cancelled. Example:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+//	defer cancel()
+//	u, err := scanner.ScanAndWait(ctx, "http://www.example.com")
+//	if err != nil {
+//		...handle error
+//	}
+//	stats, err := u.LastAnalysisStats()
+func (s *URLScanner) ScanAndWait(ctx context.Context, url string, options ...WaitOption) (*Object, error) {
+	analysis, err := s.Scan(url)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := analysis.WaitForCompletion(ctx, s.cli, 5*time.Second, options...); err != nil {
+		return nil, err
+	}
+	// URL objects can be retrieved using the unpadded base64 of the URL as
+	// their ID.
+	submitted, _ := analysis.GetContextString("url")
+	return s.cli.GetObject(URL("urls/%s", base64.RawURLEncoding.EncodeToString([]byte(submitted))))
+}
+
+// URLScanResult contains the result of submitting a URL with ScanAll.
+type URLScanResult struct {
+	// URL is the URL as it was passed to ScanAll.
+	URL string
+	// Analysis is the analysis returned after submitting the URL, it's nil
+	// if the submission failed.
+	Analysis *Analysis
+	// Number of requests made for submitting the URL.
+	Attempts int
+	// Err is the error occurred in the last attempt, if the submission failed.
+	Err error
+}
+
+// ScanAll submits multiple URLs to VirusTotal for scanning like Scan, sending
+// multiple requests in parallel, and sends the results through the returned
+// channel as they are ready, which means that results are not necessarily in
+// the same order than URLs. The concurrency, rate and retries are controlled
+// with URLScannerWorkers, URLScannerRequestsPerMinute and URLScannerRetries.
+// The returned channel is closed after the results for all the URLs have been
+// sent, or when the context is cancelled.
+//
+// Example:
+//
+//	s := client.NewURLScannerWithOptions(vt.URLScannerRequestsPerMinute(60))
+//	for result := range s.ScanAll(ctx, urls) {
+//		if result.Err != nil {
+//			...
+//		}
+//	}
+func (s *URLScanner) ScanAll(ctx context.Context, urls []string) <-chan URLScanResult {
+	pending := make(chan string)
+	go func() {
+		defer close(pending)
+		for _, u := range urls {
+			select {
+			case pending <- u:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	results := make(chan URLScanResult)
+	var ticker *time.Ticker
+	if s.interval > 0 {
+		ticker = time.NewTicker(s.interval)
+	}
+	workers := s.workers
+	if workers < 1 {
+		workers = 1
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for u := range pending {
+				select {
+				case results <- s.scan(ctx, u, ticker):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		if ticker != nil {
+			ticker.Stop()
+		}
+		close(results)
+	}()
+	return results
+}
+
+// scan submits a single URL, retrying the request if needed. If ticker is not
+// nil, every request waits for a tick before being sent.
+func (s *URLScanner) scan(ctx context.Context, url string, ticker *time.Ticker) URLScanResult {
+	result := URLScanResult{URL: url}
+	result.Attempts, result.Err = retryTransient(ctx, ticker, s.maxRetries, s.retryDelay, func() (err error) {
+		result.Analysis, err = s.Scan(url)
+		return err
+	})
+	return result
 }
@@ -0,0 +1,144 @@
+package vt
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeURL(t *testing.T) {
+	tests := []struct {
+		url           string
+		stripFragment bool
+		expected      string
+	}{
+		{"HTTP://WWW.Example.COM/Path", false, "http://www.example.com/Path"},
+		{"  www.example.com/foo ", false, "http://www.example.com/foo"},
+		{"https://bücher.example/", false, "https://xn--bcher-kva.example/"},
+		{"https://München.de:8080/x", false, "https://xn--mnchen-3ya.de:8080/x"},
+		{"http://例え.テスト/", false, "http://xn--r8jz45g.xn--zckzah/"},
+		{"http://example.com/page#section", false, "http://example.com/page#section"},
+		{"http://example.com/page#section", true, "http://example.com/page"},
+		{"http://[::1]/path", false, "http://[::1]/path"},
+		{"HTTP://[FE80::1]:8080/path", false, "http://[fe80::1]:8080/path"},
+	}
+	for _, test := range tests {
+		u, err := NormalizeURL(test.url, test.stripFragment)
+		assert.NoError(t, err)
+		assert.Equal(t, test.expected, u)
+	}
+}
+
+func TestURLScannerScan(t *testing.T) {
+	ts := NewTestServer(t).
+		SetExpectedMethod("POST").
+		SetResponse(map[string]interface{}{
+			"data": map[string]interface{}{
+				"type": "analysis",
+				"id":   "u-1234",
+			},
+		})
+
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+	s := c.NewURLScannerWithOptions(URLScannerStripFragment(true))
+	a, err := s.Scan("HTTPS://bücher.example/#top")
+	assert.NoError(t, err)
+	assert.Equal(t, "u-1234", a.ID())
+	submitted, err := a.GetContextString("url")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://xn--bcher-kva.example/", submitted)
+}
+
+func TestURLScannerScanAndWait(t *testing.T) {
+	// Unpadded base64 of "http://www.example.com/".
+	const id = "aHR0cDovL3d3dy5leGFtcGxlLmNvbS8"
+	var polls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v3/urls":
+			assert.Equal(t, "http://www.example.com/", r.FormValue("url"))
+			w.Write([]byte(`{"data": {"type": "analysis", "id": "u-1234"}}`))
+		case "/api/v3/analyses/u-1234":
+			status := "queued"
+			if atomic.AddInt32(&polls, 1) >= 2 {
+				status = "completed"
+			}
+			w.Write([]byte(`{"data": {"type": "analysis", "id": "u-1234", "attributes": {"status": "` + status + `"}}}`))
+		case "/api/v3/urls/" + id:
+			w.Write([]byte(`{"data": {"type": "url", "id": "` + id + `", "attributes": {
+				"last_analysis_stats": {"malicious": 1}}}}`))
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+
+	u, err := c.NewURLScanner().ScanAndWait(context.Background(),
+		"WWW.EXAMPLE.COM/", WaitInterval(time.Millisecond))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&polls))
+	assert.Equal(t, id, u.ID())
+	stats, err := u.LastAnalysisStats()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), stats.Malicious)
+}
+
+func TestURLScannerScanAll(t *testing.T) {
+	var failed, broken int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		u := r.FormValue("url")
+		w.Header().Set("Content-Type", "application/json")
+		// The first submission of b.com fails with a transient error.
+		if u == "http://b.com" && atomic.CompareAndSwapInt32(&failed, 0, 1) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"error": {"code": "TransientError"}}`))
+			return
+		}
+		// The first submission of d.com fails with a network error.
+		if u == "http://d.com" && atomic.CompareAndSwapInt32(&broken, 0, 1) {
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Write([]byte("garbage\r\n\r\n"))
+			conn.Close()
+			return
+		}
+		w.Write([]byte(`{"data": {"type": "analysis", "id": "` + u + `"}}`))
+	}))
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+
+	s := c.NewURLScannerWithOptions(
+		URLScannerWorkers(2),
+		URLScannerRequestsPerMinute(60000),
+		URLScannerRetries(1, time.Millisecond))
+
+	var ids []string
+	attempts := make(map[string]int)
+	for result := range s.ScanAll(context.Background(), []string{"a.com", "b.com", "c.com", "d.com", "http://[::1"}) {
+		attempts[result.URL] = result.Attempts
+		if result.URL == "http://[::1" {
+			assert.Error(t, result.Err)
+			assert.Nil(t, result.Analysis)
+			continue
+		}
+		assert.NoError(t, result.Err)
+		ids = append(ids, result.Analysis.ID())
+	}
+	sort.Strings(ids)
+	assert.Equal(t, []string{"http://a.com", "http://b.com", "http://c.com", "http://d.com"}, ids)
+	assert.Equal(t, map[string]int{"a.com": 1, "b.com": 2, "c.com": 1, "d.com": 2, "http://[::1": 1}, attempts)
+}
@@ -0,0 +1,278 @@
+// Copyright © 2017 The vt-go authors. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vt
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+)
+
+// WithAttributeValidation enables the validation of object attributes before
+// sending objects to the server with PostObject or PatchObject. When enabled,
+// the attributes modified in the object are checked against the attributes
+// described by the metadata for the object's type, and the request is not sent
+// if some of them doesn't exist, which helps catching typos in attribute names.
+// The metadata is retrieved with GetMetadata the first time it's needed. Object
+// types not described in the metadata are not validated.
+func WithAttributeValidation(b bool) ClientOption {
+	return func(c *Client) {
+		c.validateAttributes = b
+	}
+}
+
+// ValidateAttributes checks that the attributes modified in the object exist
+// for the object's type according to the metadata, returning an error if
+// some of them doesn't exist. Only the first element of dotted paths is
+// checked. Objects with a type not described in the metadata are considered
+// valid.
+func (m *Metadata) ValidateAttributes(obj *Object) error {
+	attrs, ok := m.Attributes[obj.Type()]
+	if !ok {
+		return nil
+	}
+	known := make(map[string]bool, len(attrs))
+	for _, attr := range attrs {
+		known[attr.Name] = true
+	}
+	obj.rlock()
+	modified := append([]string(nil), obj.modifiedAttributes...)
+	obj.runlock()
+	sort.Strings(modified)
+	for _, attr := range modified {
+		name := attr
+		if path, err := parsePath(attr); err == nil {
+			name = path[0].key
+		}
+		if !known[name] {
+			return fmt.Errorf(
+				"attribute \"%s\" doesn't exist for object type \"%s\"", attr, obj.Type())
+		}
+	}
+	return nil
+}
+
+// validationMetadata returns the metadata used for validation, which is
+// retrieved the first time this function is called.
+func (cli *Client) validationMetadata() (*Metadata, error) {
+	cli.metadataMu.Lock()
+	defer cli.metadataMu.Unlock()
+	if cli.metadata == nil {
+		metadata, err := cli.GetMetadata()
+		if err != nil {
+			return nil, err
+		}
+		cli.metadata = metadata
+	}
+	return cli.metadata, nil
+}
+
+// validate validates the object's attributes if attribute validation or
+// strict validation are enabled in the client.
+func (cli *Client) validate(obj *Object) error {
+	if !cli.validateAttributes && !cli.strictValidation {
+		return nil
+	}
+	metadata, err := cli.validationMetadata()
+	if err != nil {
+		return err
+	}
+	return metadata.ValidateAttributes(obj)
+}
+
+// WithStrictValidation enables the validation of requests before sending them,
+// so that mistakes produce local errors instead of wasting a request that
+// would fail anyway. In strict mode the client rejects requests to objects,
+// collections, relationships and searches with query parameters that those
+// endpoints don't support, relationships in the "relationships" parameter
+// that don't exist for the object's type, and unknown attributes in the
+// "order" and "filter" parameters. Query parameters sent to other endpoints
+// are not validated. Strict validation also implies WithAttributeValidation.
+// The metadata is retrieved with GetMetadata the first time it's needed,
+// object types not described in the metadata are not validated.
+func WithStrictValidation(b bool) ClientOption {
+	return func(c *Client) {
+		c.strictValidation = b
+	}
+}
+
+// Query parameters accepted by the different kinds of API endpoints.
+var (
+	objectQueryParameters = map[string]bool{
+		"attributes":            true,
+		"relationship_counters": true,
+		"relationships":         true,
+	}
+	collectionQueryParameters = map[string]bool{
+		"attributes":            true,
+		"cursor":                true,
+		"filter":                true,
+		"ids":                   true,
+		"limit":                 true,
+		"order":                 true,
+		"relationship_counters": true,
+		"relationships":         true,
+	}
+	relationshipQueryParameters = map[string]bool{
+		"attributes":            true,
+		"cursor":                true,
+		"descriptors_only":      true,
+		"limit":                 true,
+		"relationship_counters": true,
+		"relationships":         true,
+	}
+	searchQueryParameters = map[string]bool{
+		"attributes":            true,
+		"cursor":                true,
+		"descriptors_only":      true,
+		"limit":                 true,
+		"order":                 true,
+		"query":                 true,
+		"relationship_counters": true,
+		"relationships":         true,
+	}
+)
+
+// endpointQueryParameters returns the query parameters accepted by the given
+// API endpoint, which is a path relative to the API root, or nil if they are
+// not known.
+func endpointQueryParameters(endpoint string) map[string]bool {
+	endpoint = strings.Trim(endpoint, "/")
+	if endpoint == "search" || endpoint == "intelligence/search" {
+		return searchQueryParameters
+	}
+	if objType, isObject := endpointObjectType(endpoint); objType != "" {
+		if isObject {
+			return objectQueryParameters
+		}
+		return collectionQueryParameters
+	}
+	for _, pattern := range []string{"*/*/*", "*/*/relationships/*"} {
+		if matched, _ := path.Match(pattern, endpoint); matched {
+			return relationshipQueryParameters
+		}
+	}
+	return nil
+}
+
+// filterAttributes returns the names of the attributes used in a filter, which
+// is a space-separated list of conditions like "size:1000" or "size>1000",
+// optionally negated with "-". Words that are not conditions are ignored. Only
+// the first element of dotted names is returned.
+func filterAttributes(filter string) []string {
+	var names []string
+	for _, word := range strings.Fields(filter) {
+		i := strings.IndexAny(word, ":<>=")
+		if i <= 0 {
+			continue
+		}
+		name := strings.TrimPrefix(word[:i], "-")
+		if j := strings.Index(name, "."); j >= 0 {
+			name = name[:j]
+		}
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// endpointObjectType returns the type of the objects involved in a request to
+// the given API endpoint, which is a path relative to the API root. For
+// endpoints like "files/{id}" it returns "file" and true, for collections like
+// "files" it returns "file" and false. If the type can't be determined the
+// returned type is empty.
+func endpointObjectType(endpoint string) (objType string, isObject bool) {
+	endpoint = strings.Trim(endpoint, "/")
+	for t, collection := range objectCollections {
+		if endpoint == collection {
+			return t, false
+		}
+		if strings.HasPrefix(endpoint, collection+"/") &&
+			!strings.Contains(endpoint[len(collection)+1:], "/") {
+			return t, true
+		}
+	}
+	parts := strings.Split(endpoint, "/")
+	if len(parts) > 2 || !strings.HasSuffix(parts[0], "s") {
+		return "", false
+	}
+	return strings.TrimSuffix(parts[0], "s"), len(parts) == 2
+}
+
+// validateURL validates the query parameters in a request to the API if
+// strict validation is enabled in the client.
+func (cli *Client) validateURL(u *url.URL) error {
+	if !cli.strictValidation || u.Host != baseURL.Host {
+		return nil
+	}
+	endpoint := strings.TrimPrefix(strings.TrimPrefix(u.Path, "/"), baseURL.Path)
+	q := u.Query()
+	if known := endpointQueryParameters(endpoint); known != nil {
+		params := make([]string, 0, len(q))
+		for param := range q {
+			params = append(params, param)
+		}
+		sort.Strings(params)
+		for _, param := range params {
+			if !known[param] {
+				return fmt.Errorf("unknown query parameter \"%s\"", param)
+			}
+		}
+	}
+	if q.Get("relationships") == "" && q.Get("order") == "" && q.Get("filter") == "" {
+		return nil
+	}
+	objType, isObject := endpointObjectType(endpoint)
+	if objType == "" {
+		return nil
+	}
+	metadata, err := cli.validationMetadata()
+	if err != nil {
+		return err
+	}
+	if relationships, ok := metadata.Relationships[objType]; ok && isObject {
+		known := make(map[string]bool, len(relationships))
+		for _, r := range relationships {
+			known[r.Name] = true
+		}
+		for _, name := range strings.Split(q.Get("relationships"), ",") {
+			if name = strings.TrimSpace(name); name != "" && !known[name] {
+				return fmt.Errorf(
+					"unknown relationship \"%s\" for object type \"%s\"", name, objType)
+			}
+		}
+	}
+	if attrs, ok := metadata.Attributes[objType]; ok && !isObject {
+		known := make(map[string]bool, len(attrs))
+		for _, attr := range attrs {
+			known[attr.Name] = true
+		}
+		if order := q.Get("order"); order != "" {
+			if name := strings.TrimRight(order, "+-"); !known[name] {
+				return fmt.Errorf(
+					"unknown attribute \"%s\" in order for object type \"%s\"", name, objType)
+			}
+		}
+		for _, name := range filterAttributes(q.Get("filter")) {
+			if !known[name] {
+				return fmt.Errorf(
+					"unknown attribute \"%s\" in filter for object type \"%s\"", name, objType)
+			}
+		}
+	}
+	return nil
+}
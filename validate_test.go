@@ -0,0 +1,103 @@
+package vt
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttributeValidation(t *testing.T) {
+	var metadataRequests, patchRequests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case "GET":
+			atomic.AddInt32(&metadataRequests, 1)
+			w.Write([]byte(`{"data": {"attributes": {"hunting_ruleset": [
+				{"name": "name", "description": "Ruleset name"},
+				{"name": "rules", "description": "YARA rules"}]}}}`))
+		case "PATCH":
+			atomic.AddInt32(&patchRequests, 1)
+			w.Write([]byte(`{"data": {"type": "hunting_ruleset", "id": "1234"}}`))
+		}
+	}))
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key", WithAttributeValidation(true))
+
+	obj := NewObjectWithID("hunting_ruleset", "1234")
+	obj.SetString("rules", "rule foo { condition: true }")
+	assert.NoError(t, c.PatchObject(URL("intelligence/hunting_rulesets/1234"), obj))
+
+	obj = NewObjectWithID("hunting_ruleset", "1234")
+	obj.SetString("rulez", "rule foo { condition: true }")
+	err := c.PatchObject(URL("intelligence/hunting_rulesets/1234"), obj)
+	assert.EqualError(t, err,
+		"attribute \"rulez\" doesn't exist for object type \"hunting_ruleset\"")
+
+	// Object types not described in the metadata are not validated.
+	obj = NewObjectWithID("comment", "1234")
+	obj.SetString("foo", "bar")
+	assert.NoError(t, c.PatchObject(URL("comments/1234"), obj))
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&metadataRequests))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&patchRequests))
+}
+
+func TestStrictValidation(t *testing.T) {
+	var requests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/api/v3/metadata" {
+			w.Write([]byte(`{"data": {
+				"relationships": {"file": [{"name": "contacted_domains"}, {"name": "bundled_files"}]},
+				"attributes": {"file": [{"name": "size"}, {"name": "first_submission_date"}]}}}`))
+			return
+		}
+		atomic.AddInt32(&requests, 1)
+		w.Write([]byte(`{"data": []}`))
+	}))
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key", WithStrictValidation(true))
+
+	_, err := c.Get(URL("files/1234?relationships=contacted_domains,bundled_files"))
+	assert.NoError(t, err)
+	_, err = c.Get(URL("files?order=first_submission_date-&limit=10"))
+	assert.NoError(t, err)
+	// Object types not described in the metadata are not validated.
+	_, err = c.Get(URL("urls/1234?relationships=foo"))
+	assert.NoError(t, err)
+
+	_, err = c.Get(URL("files/1234?relationships=contacted_domainz"))
+	assert.EqualError(t, err,
+		"unknown relationship \"contacted_domainz\" for object type \"file\"")
+	_, err = c.Get(URL("files?order=sise-"))
+	assert.EqualError(t, err,
+		"unknown attribute \"sise\" in order for object type \"file\"")
+	_, err = c.Get(URL("files?limt=10"))
+	assert.EqualError(t, err, "unknown query parameter \"limt\"")
+	_, err = c.Get(URL("files/1234?limit=10"))
+	assert.EqualError(t, err, "unknown query parameter \"limit\"")
+	_, err = c.Get(URL("files?filter=%s", url.QueryEscape("size>1000 -first_sumbission_date:2020-01-01")))
+	assert.EqualError(t, err,
+		"unknown attribute \"first_sumbission_date\" in filter for object type \"file\"")
+
+	_, err = c.Get(URL("files?filter=%s", url.QueryEscape("size>1000 -first_submission_date:2020-01-01")))
+	assert.NoError(t, err)
+	_, err = c.Get(URL("files/1234/contacted_domains?limit=10&descriptors_only=true"))
+	assert.NoError(t, err)
+	_, err = c.Get(URL("intelligence/search?query=foo&descriptors_only=true"))
+	assert.NoError(t, err)
+	// Query parameters sent to other endpoints are not validated.
+	_, err = c.Get(URL("intelligence/hunting_notification_files?foo=bar"))
+	assert.NoError(t, err)
+
+	assert.Equal(t, int32(7), atomic.LoadInt32(&requests))
+}
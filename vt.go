@@ -19,7 +19,9 @@ package vt
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
 	"net/url"
 	"strings"
 )
@@ -29,10 +31,12 @@ const (
 )
 
 const (
-	// Maximum size of payloads posted to VirusTotal's API endpoints.
-	maxPayloadSize = 30 * 1024 * 1024 // 30 MB
-	// Maximum file size that can scanned by VirusTotal.
-	maxFileSize = 650 * 1024 * 1024 // 650 MB
+	// Default maximum size of payloads posted to VirusTotal's API endpoints,
+	// see WithMaxPayloadSize.
+	defaultMaxPayloadSize = 30 * 1024 * 1024 // 30 MB
+	// Default maximum file size that can scanned by VirusTotal, see
+	// WithMaxFileSize.
+	defaultMaxFileSize = 650 * 1024 * 1024 // 650 MB
 )
 
 var baseURL = url.URL{
@@ -47,10 +51,78 @@ type Request struct {
 
 // Response is the top level structure of an API response.
 type Response struct {
-	Data  json.RawMessage        `json:"data"`
-	Meta  map[string]interface{} `json:"meta"`
-	Links Links                  `json:"links"`
-	Error Error                  `json:"error"`
+	Data  json.RawMessage `json:"data"`
+	Meta  Meta            `json:"meta"`
+	Links Links           `json:"links"`
+	Error Error           `json:"error"`
+	// NoContent is true when the server responded successfully but without
+	// any content in the response body, which is common for DELETE requests.
+	NoContent bool `json:"-"`
+}
+
+// Meta contains the "meta" member of an API response. Collections use it for
+// returning things like the cursor for the next page or the total number of
+// items, while analyses include information about the analysed file. Meta can
+// be used as a regular map for accessing the raw values, but it also provides
+// typed accessors for the most common keys.
+type Meta map[string]interface{}
+
+// FileInfo contains the information about the analysed file that is returned
+// in the "meta" member of /analyses/{id} responses.
+type FileInfo struct {
+	SHA256 string `json:"sha256"`
+	SHA1   string `json:"sha1"`
+	MD5    string `json:"md5"`
+	Size   int64  `json:"size"`
+}
+
+// Cursor returns the cursor for retrieving the next page of a collection, or
+// an empty string if the response doesn't include a cursor.
+func (m Meta) Cursor() string {
+	s, _ := m["cursor"].(string)
+	return s
+}
+
+func (m Meta) getInt64(key string) (int64, error) {
+	switch v := m[key].(type) {
+	case float64:
+		return int64(v), nil
+	case json.Number:
+		return v.Int64()
+	case nil:
+		return 0, fmt.Errorf("meta \"%s\" does not exists", key)
+	}
+	return 0, fmt.Errorf("meta \"%s\" is not a number", key)
+}
+
+// Count returns the total number of items in a collection. Not all collections
+// return this value, an error is returned when it's not present.
+func (m Meta) Count() (int64, error) {
+	return m.getInt64("count")
+}
+
+// DaysBack returns the number of days covered by the collection, as returned
+// by endpoints like /intelligence/hunting_notification_files.
+func (m Meta) DaysBack() (int64, error) {
+	return m.getInt64("days_back")
+}
+
+// FileInfo returns the information about the analysed file included in the
+// responses from /analyses/{id}.
+func (m Meta) FileInfo() (*FileInfo, error) {
+	v, exists := m["file_info"]
+	if !exists {
+		return nil, errors.New("meta \"file_info\" does not exists")
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	fi := &FileInfo{}
+	if err := json.Unmarshal(b, fi); err != nil {
+		return nil, err
+	}
+	return fi, nil
 }
 
 // Error contains information about an API error.
@@ -64,6 +136,31 @@ func (e Error) Error() string {
 	return e.Message
 }
 
+// Error codes returned by the API for errors that are transient, the same
+// request may succeed if retried later.
+var retryableErrorCodes = map[string]bool{
+	"QuotaExceededError":    true,
+	"TooManyRequestsError":  true,
+	"TransientError":        true,
+	"DeadlineExceededError": true,
+}
+
+// IsRetryableError returns true if err is a transient error, meaning that the
+// request that produced the error may succeed if retried later. API errors
+// like QuotaExceededError or TransientError are retryable, as well as network
+// timeouts, while errors like NotFoundError or WrongCredentialsError are not.
+func IsRetryableError(err error) bool {
+	var apiErr Error
+	if errors.As(err, &apiErr) {
+		return retryableErrorCodes[apiErr.Code]
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return netErr.Timeout()
+	}
+	return false
+}
+
 // URL returns a full VirusTotal API URL from a relative path (i.e: a path
 // without the domain name and the "/api/v3/" prefix). The path can contain
 // format 'verbs' as defined in the "fmt". This function is useful for creating
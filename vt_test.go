@@ -1,13 +1,20 @@
 package vt
 
 import (
+	"bytes"
 	"compress/gzip"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -468,3 +475,645 @@ func TestGetObjectOutOfQuota(t *testing.T) {
 		}
 	}
 }
+
+func TestResponseMeta(t *testing.T) {
+	ts := NewTestServer(t).
+		SetExpectedMethod("GET").
+		SetResponse(map[string]interface{}{
+			"data": map[string]interface{}{
+				"type": "analysis",
+				"id":   "analysis_id",
+			},
+			"meta": map[string]interface{}{
+				"cursor":    "abcd",
+				"count":     1234,
+				"days_back": 90,
+				"file_info": map[string]interface{}{
+					"sha256": "275a021bbfb6489e54d471899f7db9d1663fc695ec2fe2a2c4538aabf651fd0f",
+					"size":   68,
+				},
+			},
+		})
+
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+	resp, err := c.Get(URL("analyses/analysis_id"))
+	assert.NoError(t, err)
+
+	assert.Equal(t, "abcd", resp.Meta.Cursor())
+
+	count, err := resp.Meta.Count()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1234), count)
+
+	daysBack, err := resp.Meta.DaysBack()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(90), daysBack)
+
+	fi, err := resp.Meta.FileInfo()
+	assert.NoError(t, err)
+	assert.Equal(t, "275a021bbfb6489e54d471899f7db9d1663fc695ec2fe2a2c4538aabf651fd0f", fi.SHA256)
+	assert.Equal(t, int64(68), fi.Size)
+
+	resp.Meta["count"] = "foo"
+	_, err = resp.Meta.Count()
+	assert.Error(t, err)
+
+	delete(resp.Meta, "file_info")
+	_, err = resp.Meta.FileInfo()
+	assert.Error(t, err)
+}
+
+func TestGetRaw(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v3/files/abcd/download":
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Write([]byte("file content"))
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": {"code": "NotFoundError", "message": "not found"}}`))
+		}
+	}))
+
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+
+	var b bytes.Buffer
+	n, err := c.GetRaw(URL("files/abcd/download"), &b)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12), n)
+	assert.Equal(t, "file content", b.String())
+
+	b.Reset()
+	_, err = c.DownloadFile("efgh", &b)
+	assert.Error(t, err)
+	assert.Equal(t, "NotFoundError", err.(Error).Code)
+	assert.Equal(t, 0, b.Len())
+}
+
+func TestIteratorRequiredAttributes(t *testing.T) {
+
+	ts := NewTestServer(t).
+		SetExpectedMethod("GET").
+		SetResponse(map[string]interface{}{
+			"data": []map[string]interface{}{
+				{
+					"type": "object_type",
+					"id":   "object_id_1",
+					"attributes": map[string]interface{}{
+						"some_string": "hello",
+					},
+				},
+				{
+					"type": "object_type",
+					"id":   "object_id_2",
+					"attributes": map[string]interface{}{
+						"some_string": "world",
+						"super": map[string]interface{}{
+							"data": 1,
+						},
+					},
+				},
+			}})
+
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+	it, err := c.Iterator(URL("/collection"),
+		IteratorRequiredAttributes("some_string", "super.data"))
+
+	assert.NoError(t, err)
+	assert.True(t, it.Next())
+	assert.Equal(t, "object_id_2", it.Get().ID())
+	assert.False(t, it.Next())
+	assert.NoError(t, it.Error())
+
+	it, err = c.Iterator(URL("/collection"),
+		IteratorRequiredAttributes("some_string", "super.data"),
+		IteratorFailOnMissingAttributes(true))
+
+	assert.NoError(t, err)
+	assert.False(t, it.Next())
+	assert.Error(t, it.Error())
+}
+
+// newCollectionTestServer returns a test server for a collection with n
+// objects, returned in pages of pageSize objects. Each page includes a link to
+// the next one in "links.next", and the collection's cursor in "meta.cursor".
+// resetConnection resets the connection of the request being handled, the
+// server must have keep-alives disabled, otherwise the client retries the
+// request transparently.
+func resetConnection(t *testing.T, w http.ResponseWriter) {
+	conn, _, err := w.(http.Hijacker).Hijack()
+	assert.NoError(t, err)
+	conn.(*net.TCPConn).SetLinger(0)
+	conn.Close()
+}
+
+func newCollectionTestServer(t *testing.T, n, pageSize int) *httptest.Server {
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := 0
+		if c := r.URL.Query().Get("cursor"); c != "" {
+			fmt.Sscanf(c, "%d", &start)
+		}
+		objects := []map[string]interface{}{}
+		for i := start; i < start+pageSize && i < n; i++ {
+			objects = append(objects, map[string]interface{}{
+				"type": "object_type",
+				"id":   fmt.Sprintf("object_id_%d", i),
+				"attributes": map[string]interface{}{
+					"index": i,
+				},
+			})
+		}
+		resp := map[string]interface{}{
+			"data": objects,
+			"meta": map[string]interface{}{"count": n},
+			"links": map[string]interface{}{
+				"self": fmt.Sprintf("http://%s%s?cursor=%d", r.Host, r.URL.Path, start),
+			},
+		}
+		if start+pageSize < n {
+			resp["links"].(map[string]interface{})["next"] = fmt.Sprintf(
+				"http://%s%s?cursor=%d", r.Host, r.URL.Path, start+pageSize)
+			resp["meta"].(map[string]interface{})["cursor"] = fmt.Sprintf("%d", start+pageSize)
+		}
+		js, _ := json.Marshal(resp)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(js)
+	}))
+	return ts
+}
+
+func TestIteratorSkipAndReset(t *testing.T) {
+	ts := newCollectionTestServer(t, 10, 3)
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+	it, err := c.Iterator(URL("collection"))
+	assert.NoError(t, err)
+
+	assert.Equal(t, 4, it.Skip(4))
+	assert.Equal(t, "object_id_3", it.Get().ID())
+	cursor := it.Cursor()
+
+	assert.True(t, it.Next())
+	assert.Equal(t, "object_id_4", it.Get().ID())
+	assert.Equal(t, 5, it.Skip(10))
+	assert.False(t, it.Next())
+
+	assert.NoError(t, it.Reset(cursor))
+	assert.True(t, it.Next())
+	assert.Equal(t, "object_id_4", it.Get().ID())
+
+	assert.NoError(t, it.Reset(""))
+	assert.True(t, it.Next())
+	assert.Equal(t, "object_id_0", it.Get().ID())
+	it.Close()
+	assert.NoError(t, it.Error())
+}
+
+func TestIteratorSkipPages(t *testing.T) {
+	var mu sync.Mutex
+	var requests []string
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		mu.Lock()
+		requests = append(requests, r.URL.RawQuery)
+		mu.Unlock()
+		start, _ := strconv.Atoi(q.Get("cursor"))
+		limit, _ := strconv.Atoi(q.Get("limit"))
+		objects := []map[string]interface{}{}
+		for i := start; i < start+limit && i < 10; i++ {
+			objects = append(objects, map[string]interface{}{
+				"type": "object_type",
+				"id":   fmt.Sprintf("object_id_%d", i),
+			})
+		}
+		links := map[string]interface{}{
+			"self": fmt.Sprintf("%s%s?%s", ts.URL, r.URL.Path, r.URL.RawQuery),
+		}
+		if start+limit < 10 {
+			links["next"] = fmt.Sprintf("%s%s?cursor=%d&limit=%d", ts.URL, r.URL.Path, start+limit, limit)
+		}
+		js, _ := json.Marshal(map[string]interface{}{"data": objects, "links": links})
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(js)
+	}))
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+	it, err := c.Iterator(URL("collection"), IteratorBatchSize(3), IteratorPrefetch(1))
+	assert.NoError(t, err)
+	defer it.Close()
+
+	assert.True(t, it.Next())
+	assert.Equal(t, "object_id_0", it.Get().ID())
+
+	// Objects 1 and 2 are already retrieved, 3 and 4 are skipped by requesting
+	// a page with only two objects.
+	assert.Equal(t, 5, it.Skip(5))
+	assert.Equal(t, "object_id_5", it.Get().ID())
+	mu.Lock()
+	assert.Contains(t, requests, "cursor=3&limit=2")
+	assert.Contains(t, requests, "cursor=5&limit=3")
+	mu.Unlock()
+
+	cursor := it.Cursor()
+	assert.Equal(t, 4, it.Skip(10))
+	assert.False(t, it.Next())
+	assert.NoError(t, it.Error())
+
+	assert.NoError(t, it.Reset(cursor))
+	assert.True(t, it.Next())
+	assert.Equal(t, "object_id_6", it.Get().ID())
+
+	// Skipped objects count towards the limit.
+	it, err = c.Iterator(URL("collection"), IteratorBatchSize(3), IteratorLimit(6))
+	assert.NoError(t, err)
+	defer it.Close()
+	assert.Equal(t, 6, it.Skip(8))
+	assert.Equal(t, "object_id_5", it.Get().ID())
+	assert.False(t, it.Next())
+}
+
+func TestIteratorLimitShrinksLastPage(t *testing.T) {
+	var limits []string
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		limits = append(limits, q.Get("limit"))
+		start, _ := strconv.Atoi(q.Get("cursor"))
+		limit, _ := strconv.Atoi(q.Get("limit"))
+		objects := []map[string]interface{}{}
+		for i := start; i < start+limit; i++ {
+			objects = append(objects, map[string]interface{}{
+				"type": "object_type",
+				"id":   fmt.Sprintf("object_id_%d", i),
+			})
+		}
+		js, _ := json.Marshal(map[string]interface{}{
+			"data": objects,
+			"links": map[string]interface{}{
+				"self": fmt.Sprintf("%s%s?cursor=%d&limit=%d", ts.URL, r.URL.Path, start, limit),
+				"next": fmt.Sprintf("%s%s?cursor=%d&limit=%d", ts.URL, r.URL.Path, start+limit, limit),
+			},
+		})
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(js)
+	}))
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+	it, err := c.Iterator(URL("collection"), IteratorBatchSize(10), IteratorLimit(25))
+	assert.NoError(t, err)
+	defer it.Close()
+
+	n := 0
+	for it.Next() {
+		n++
+	}
+	assert.NoError(t, it.Error())
+	assert.Equal(t, 25, n)
+	assert.Equal(t, []string{"10", "10", "5"}, limits)
+}
+
+func TestIteratorNativeCursor(t *testing.T) {
+	ts := newCollectionTestServer(t, 10, 3)
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+	it, err := c.Iterator(URL("collection"), IteratorNativeCursor(true))
+	assert.NoError(t, err)
+
+	assert.Equal(t, 2, it.Skip(2))
+	assert.Equal(t, "", it.Cursor())
+	assert.Equal(t, 2, it.Skip(2))
+	assert.Equal(t, "3", it.Cursor())
+	assert.Equal(t, 2, it.Skip(2))
+	assert.Equal(t, "6", it.Cursor())
+	it.Close()
+
+	it, err = c.Iterator(URL("collection"),
+		IteratorNativeCursor(true), IteratorCursor("6"))
+	assert.NoError(t, err)
+	defer it.Close()
+	assert.True(t, it.Next())
+	assert.Equal(t, int64(6), it.Get().MustGetInt64("index"))
+
+	assert.NoError(t, it.Reset("3"))
+	assert.True(t, it.Next())
+	assert.Equal(t, int64(3), it.Get().MustGetInt64("index"))
+}
+
+func TestIteratorAttributes(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "size,type_tag", r.URL.Query().Get("attributes"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": [{"type": "file", "id": "1234", "attributes": {"size": 1}}]}`))
+	}))
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key", WithStrictValidation(true))
+	it, err := c.Iterator(URL("intelligence/search"), IteratorAttributes("size", "type_tag"))
+	assert.NoError(t, err)
+	defer it.Close()
+	assert.True(t, it.Next())
+	assert.Equal(t, int64(1), it.Get().MustGetInt64("size"))
+	assert.NoError(t, it.Error())
+}
+
+func TestIteratorOrder(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "first_submission_date-", r.URL.Query().Get("order"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": []}`))
+	}))
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+	it, err := c.Iterator(URL("files"), IteratorOrder("first_submission_date-"))
+	assert.NoError(t, err)
+	defer it.Close()
+	assert.False(t, it.Next())
+	assert.NoError(t, it.Error())
+
+	for _, order := range []string{"", "-", "size+-", "size,positives", "size -"} {
+		_, err = c.Iterator(URL("files"), IteratorOrder(order))
+		assert.Error(t, err, order)
+	}
+}
+
+func TestIteratorCount(t *testing.T) {
+	ts := newCollectionTestServer(t, 10, 3)
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+	it, err := c.Iterator(URL("collection"))
+	assert.NoError(t, err)
+	defer it.Close()
+
+	n, err := it.Count()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), n)
+
+	ts2 := NewTestServer(t).SetResponse(map[string]interface{}{"data": []interface{}{}})
+	defer ts2.Close()
+	SetHost(ts2.URL)
+	it, err = c.Iterator(URL("collection"))
+	assert.NoError(t, err)
+	defer it.Close()
+	_, err = it.Count()
+	assert.Error(t, err)
+}
+
+func TestIteratorPrefetch(t *testing.T) {
+	var requests int32
+	collection := newCollectionTestServer(t, 9, 3)
+	defer collection.Close()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		collection.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+	it, err := c.Iterator(URL("collection"), IteratorPrefetch(1), IteratorBufferSize(10))
+	assert.NoError(t, err)
+	defer it.Close()
+
+	for i := 0; i < 3; i++ {
+		assert.True(t, it.Next())
+	}
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+
+	assert.True(t, it.Next())
+	assert.Equal(t, int64(3), it.Get().MustGetInt64("index"))
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+	assert.Equal(t, 5, it.Skip(10))
+	assert.NoError(t, it.Error())
+
+	_, err = c.Iterator(URL("collection"), IteratorPrefetch(0))
+	assert.Error(t, err)
+	_, err = c.Iterator(URL("collection"), IteratorBufferSize(0))
+	assert.Error(t, err)
+}
+
+func TestIteratorRetry(t *testing.T) {
+	var requests int32
+	collection := newCollectionTestServer(t, 9, 3)
+	defer collection.Close()
+	// Every other request fails with a transient error.
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1)%2 == 1 {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"error": {"code": "TransientError", "message": "try again"}}`))
+			return
+		}
+		collection.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+	it, err := c.Iterator(URL("collection"), IteratorRetry(1, time.Millisecond))
+	assert.NoError(t, err)
+	defer it.Close()
+
+	var indexes []int64
+	for it.Next() {
+		indexes = append(indexes, it.Get().MustGetInt64("index"))
+	}
+	assert.NoError(t, it.Error())
+	assert.Equal(t, []int64{0, 1, 2, 3, 4, 5, 6, 7, 8}, indexes)
+	assert.Equal(t, int32(6), atomic.LoadInt32(&requests))
+
+	// Without retries the first error stops the iteration.
+	it, err = c.Iterator(URL("collection"))
+	assert.NoError(t, err)
+	defer it.Close()
+	assert.False(t, it.Next())
+	assert.True(t, IsRetryableError(it.Error()))
+
+	_, err = c.Iterator(URL("collection"), IteratorRetry(1, 0))
+	assert.Error(t, err)
+}
+
+type failingCursorStore struct{}
+
+func (failingCursorStore) Load() (string, error) { return "", nil }
+
+func (failingCursorStore) Save(cursor string) error { return errors.New("can't save") }
+
+func TestIteratorCursorStore(t *testing.T) {
+	ts := newCollectionTestServer(t, 10, 3)
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+	store := NewFileCursorStore(filepath.Join(t.TempDir(), "cursor"))
+
+	it, err := c.Iterator(URL("collection"), IteratorCursorStore(store, 2))
+	assert.NoError(t, err)
+	assert.Equal(t, 5, it.Skip(5))
+	it.Close()
+
+	// The cursor was saved after the fourth object.
+	it, err = c.Iterator(URL("collection"), IteratorCursorStore(store, 2))
+	assert.NoError(t, err)
+	defer it.Close()
+	assert.True(t, it.Next())
+	assert.Equal(t, int64(4), it.Get().MustGetInt64("index"))
+
+	it, err = c.Iterator(URL("collection"), IteratorCursorStore(failingCursorStore{}, 1))
+	assert.NoError(t, err)
+	defer it.Close()
+	assert.False(t, it.Next())
+	assert.EqualError(t, it.Error(), "can't save")
+	assert.False(t, it.Next())
+
+	_, err = c.Iterator(URL("collection"), IteratorCursorStore(store, 0))
+	assert.Error(t, err)
+}
+
+func TestIteratorForEach(t *testing.T) {
+	ts := newCollectionTestServer(t, 10, 3)
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+	it, err := c.Iterator(URL("collection"))
+	assert.NoError(t, err)
+	defer it.Close()
+
+	var sum, running, maxRunning int32
+	err = it.ForEach(context.Background(), 3, func(obj *Object) error {
+		if r := atomic.AddInt32(&running, 1); r > atomic.LoadInt32(&maxRunning) {
+			atomic.StoreInt32(&maxRunning, r)
+		}
+		defer atomic.AddInt32(&running, -1)
+		time.Sleep(time.Millisecond)
+		index := obj.MustGetInt64("index")
+		atomic.AddInt32(&sum, int32(index))
+		if index == 7 {
+			return errors.New("failed")
+		}
+		return nil
+	})
+	assert.Equal(t, int32(45), sum)
+	assert.LessOrEqual(t, atomic.LoadInt32(&maxRunning), int32(3))
+	var multiErr *MultiError
+	assert.True(t, errors.As(err, &multiErr))
+	assert.Equal(t, []int{7}, multiErr.Indexes())
+	assert.Equal(t, []string{"object_id_7"}, multiErr.IDs())
+
+	it, err = c.Iterator(URL("collection"))
+	assert.NoError(t, err)
+	defer it.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls int32
+	err = it.ForEach(ctx, 2, func(obj *Object) error {
+		if atomic.AddInt32(&calls, 1) == 2 {
+			cancel()
+		}
+		return nil
+	})
+	assert.Equal(t, context.Canceled, err)
+	assert.Less(t, atomic.LoadInt32(&calls), int32(10))
+}
+
+func TestIteratorCursorAutoDetection(t *testing.T) {
+	ts := newCollectionTestServer(t, 10, 3)
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+
+	// A server cursor switches the iterator to server cursors.
+	it, err := c.Iterator(URL("collection"), IteratorCursor("3"))
+	assert.NoError(t, err)
+	assert.True(t, it.Next())
+	assert.Equal(t, int64(3), it.Get().MustGetInt64("index"))
+	assert.Equal(t, 2, it.Skip(2))
+	assert.Equal(t, "6", it.Cursor())
+	it.Close()
+
+	// Iterator cursors are accepted by iterators using server cursors.
+	it, err = c.Iterator(URL("collection"))
+	assert.NoError(t, err)
+	assert.Equal(t, 5, it.Skip(5))
+	cursor := it.Cursor()
+	it.Close()
+
+	it, err = c.Iterator(URL("collection"),
+		IteratorNativeCursor(true), IteratorCursor(cursor))
+	assert.NoError(t, err)
+	defer it.Close()
+	assert.True(t, it.Next())
+	assert.Equal(t, int64(5), it.Get().MustGetInt64("index"))
+	assert.Equal(t, "6", it.Cursor())
+}
+
+func TestIteratorCloseAndDone(t *testing.T) {
+	ts := newCollectionTestServer(t, 10, 3)
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+
+	it, err := c.Iterator(URL("collection"))
+	assert.NoError(t, err)
+	for it.Next() {
+		select {
+		case <-it.Done():
+			t.Fatal("iterator terminated before the end")
+		default:
+		}
+	}
+	<-it.Done()
+	it.Close()
+	it.Close()
+
+	it, err = c.Iterator(URL("collection"))
+	assert.NoError(t, err)
+	assert.True(t, it.Next())
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			it.Close()
+		}()
+	}
+	wg.Wait()
+	<-it.Done()
+
+	assert.NoError(t, it.Reset(""))
+	select {
+	case <-it.Done():
+		t.Fatal("iterator terminated after Reset")
+	default:
+	}
+	assert.True(t, it.Next())
+	it.Close()
+}
@@ -0,0 +1,120 @@
+// Copyright © 2017 The vt-go authors. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vt
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+type waitOptions struct {
+	interval       time.Duration
+	maxInterval    time.Duration
+	backoff        float64
+	requestOptions []RequestOption
+}
+
+// WaitOption represents an option passed to WaitFor.
+type WaitOption func(*waitOptions)
+
+// WaitInterval specifies the time between the first polls. The default is 5
+// seconds.
+func WaitInterval(d time.Duration) WaitOption {
+	return func(o *waitOptions) {
+		o.interval = d
+	}
+}
+
+// WaitMaxInterval specifies the maximum time between polls, the interval
+// between polls grows until reaching this value. The default is 1 minute.
+func WaitMaxInterval(d time.Duration) WaitOption {
+	return func(o *waitOptions) {
+		o.maxInterval = d
+	}
+}
+
+// WaitBackoff specifies the factor by which the interval between polls is
+// multiplied after each poll. The default is 1.5, use 1 for polling at
+// regular intervals. Factors lower than 1 are not valid.
+func WaitBackoff(factor float64) WaitOption {
+	return func(o *waitOptions) {
+		o.backoff = factor
+	}
+}
+
+// WaitRequestOptions specifies options for the requests sent while polling.
+func WaitRequestOptions(options ...RequestOption) WaitOption {
+	return func(o *waitOptions) {
+		o.requestOptions = options
+	}
+}
+
+// WaitFor polls the object at the given URL until condition returns true for
+// it, and then returns the object. Polling continues until the context is
+// cancelled or its deadline expires, in which case the context's error is
+// returned. Transient errors, like network errors or errors for which
+// IsRetryableError returns true, like exceeding the quota, don't stop the
+// polling, other errors are returned immediately. Intervals must be greater than zero and the backoff factor
+// can't be lower than 1, otherwise an error is returned without polling. This
+// function unifies wait loops for analyses, retrohunt jobs, ZIP files and
+// similar objects. For example:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+//	defer cancel()
+//	analysis, err := client.WaitFor(ctx, vt.URL("analyses/%s", id),
+//		func(o *vt.Object) bool {
+//			return o.MustGetString("status") == "completed"
+//		})
+func (cli *Client) WaitFor(ctx context.Context, u *url.URL, condition func(*Object) bool, options ...WaitOption) (*Object, error) {
+	o := &waitOptions{
+		interval:    5 * time.Second,
+		maxInterval: time.Minute,
+		backoff:     1.5,
+	}
+	for _, opt := range options {
+		opt(o)
+	}
+	if o.interval <= 0 {
+		return nil, fmt.Errorf("invalid interval %s", o.interval)
+	}
+	if o.maxInterval <= 0 {
+		return nil, fmt.Errorf("invalid maximum interval %s", o.maxInterval)
+	}
+	// A factor lower than 1 would shrink the interval until polling without
+	// pause.
+	if o.backoff < 1 {
+		return nil, fmt.Errorf("invalid backoff factor %v", o.backoff)
+	}
+	interval := o.interval
+	for {
+		obj, err := cli.GetObject(u, o.requestOptions...)
+		if err == nil && condition(obj) {
+			return obj, nil
+		}
+		if err != nil && !isTransientError(err) {
+			return nil, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(interval):
+		}
+		interval = time.Duration(float64(interval) * o.backoff)
+		if interval > o.maxInterval {
+			interval = o.maxInterval
+		}
+	}
+}
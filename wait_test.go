@@ -0,0 +1,113 @@
+package vt
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWaitFor(t *testing.T) {
+	var polls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		n := atomic.AddInt32(&polls, 1)
+		if n == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"error": {"code": "QuotaExceededError", "message": "quota"}}`))
+			return
+		}
+		status := "queued"
+		if n >= 3 {
+			status = "completed"
+		}
+		fmt.Fprintf(w, `{"data": {"type": "analysis", "id": "1234", "attributes": {"status": "%s"}}}`, status)
+	}))
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+	completed := func(o *Object) bool {
+		return o.MustGetString("status") == "completed"
+	}
+
+	obj, err := c.WaitFor(context.Background(), URL("analyses/1234"), completed,
+		WaitInterval(time.Millisecond), WaitBackoff(2))
+	assert.NoError(t, err)
+	assert.Equal(t, "completed", obj.MustGetString("status"))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&polls))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = c.WaitFor(ctx, URL("analyses/1234"), func(o *Object) bool { return false },
+		WaitInterval(time.Millisecond))
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestWaitForConnectionReset(t *testing.T) {
+	var polls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&polls, 1) == 1 {
+			resetConnection(t, w)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": {"type": "analysis", "id": "1234", "attributes": {"status": "completed"}}}`))
+	}))
+	ts.Config.SetKeepAlivesEnabled(false)
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+	obj, err := c.WaitFor(context.Background(), URL("analyses/1234"),
+		func(o *Object) bool { return o.MustGetString("status") == "completed" },
+		WaitInterval(time.Millisecond))
+	assert.NoError(t, err)
+	assert.Equal(t, "completed", obj.MustGetString("status"))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&polls))
+}
+
+func TestWaitForFatalError(t *testing.T) {
+	ts := NewTestServer(t).
+		SetStatusCode(http.StatusNotFound).
+		SetResponse(map[string]interface{}{
+			"error": map[string]interface{}{
+				"code":    "NotFoundError",
+				"message": "not found",
+			},
+		})
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+	_, err := c.WaitFor(context.Background(), URL("analyses/1234"),
+		func(o *Object) bool { return true })
+	assert.Error(t, err)
+	assert.False(t, IsRetryableError(err))
+	assert.True(t, IsRetryableError(Error{Code: "TransientError"}))
+}
+
+func TestWaitForInvalidOptions(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s", r.URL.Path)
+	}))
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+	for _, option := range []WaitOption{
+		WaitInterval(0),
+		WaitMaxInterval(-time.Second),
+		WaitBackoff(0),
+		WaitBackoff(0.5),
+	} {
+		_, err := c.WaitFor(context.Background(), URL("analyses/1234"),
+			func(o *Object) bool { return true }, option)
+		assert.Error(t, err)
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright © 2017 The vt-go authors. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vt
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"reflect"
+	"time"
+)
+
+// ChangeEvent is sent by Watch when an attribute changes.
+type ChangeEvent struct {
+	// Path of the attribute that changed, as passed to Watch.
+	Path string
+	// Values of the attribute before and after the change. A nil value means
+	// that the attribute didn't exist. For paths with wildcards the values
+	// are of type []interface{}, as returned by Object.Query.
+	OldValue interface{}
+	NewValue interface{}
+	// Object is the object after the change.
+	Object *Object
+	// Time at which the change was detected.
+	Time time.Time
+	// Err is the error that stopped the watcher, if any. When Err is not
+	// nil the remaining fields are empty and no more events are sent.
+	Err error
+}
+
+// watchedValue returns the value of the attribute path in the given object,
+// or nil if the attribute doesn't exist.
+func watchedValue(obj *Object, path []pathElem) interface{} {
+	obj.rlock()
+	defer obj.runlock()
+	values := queryPath(obj.data.Attributes, path)
+	if hasWildcard(path) {
+		return values
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	return values[0]
+}
+
+// Watch polls the object at the given URL at regular intervals, and sends a
+// ChangeEvent through the returned channel every time the value of any of the
+// given attribute paths changes between two consecutive polls. Paths use the
+// syntax accepted by Object.Query, so they can contain wildcards. Example:
+//
+//	events, err := client.Watch(ctx, vt.URL("files/%s", hash), time.Hour,
+//		"last_analysis_stats.malicious")
+//	if err != nil {
+//		...handle error
+//	}
+//	for event := range events {
+//		if event.Err != nil {
+//			...handle error
+//		}
+//		fmt.Println(event.Path, event.OldValue, "->", event.NewValue)
+//	}
+//
+// The first poll is used as the baseline for detecting changes, so it doesn't
+// produce any event. Transient errors, like network errors or errors for which
+// IsRetryableError returns true, are ignored and the object is polled again
+// after the interval, while other errors are sent in a ChangeEvent and stop the
+// watcher. The channel is closed
+// when the watcher stops, either because of an error or because the context
+// is done. The interval must be greater than zero.
+func (cli *Client) Watch(ctx context.Context, u *url.URL, interval time.Duration, paths ...string) (<-chan ChangeEvent, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("invalid interval %s", interval)
+	}
+	parsed := make([][]pathElem, len(paths))
+	for i, path := range paths {
+		p, err := parsePath(path)
+		if err != nil {
+			return nil, err
+		}
+		parsed[i] = p
+	}
+	ch := make(chan ChangeEvent)
+	go func() {
+		defer close(ch)
+		var last []interface{}
+		for {
+			obj, err := cli.GetObject(u)
+			if err != nil && !isTransientError(err) {
+				select {
+				case ch <- ChangeEvent{Err: err}:
+				case <-ctx.Done():
+				}
+				return
+			}
+			if err == nil {
+				values := make([]interface{}, len(parsed))
+				for i, p := range parsed {
+					values[i] = watchedValue(obj, p)
+				}
+				for i := range values {
+					if last == nil || reflect.DeepEqual(last[i], values[i]) {
+						continue
+					}
+					event := ChangeEvent{
+						Path:     paths[i],
+						OldValue: last[i],
+						NewValue: values[i],
+						Object:   obj,
+						Time:     time.Now(),
+					}
+					select {
+					case ch <- event:
+					case <-ctx.Done():
+						return
+					}
+				}
+				last = values
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(interval):
+			}
+		}
+	}()
+	return ch, nil
+}
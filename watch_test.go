@@ -0,0 +1,66 @@
+package vt
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatch(t *testing.T) {
+	var polls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&polls, 1)
+		malicious := 1
+		if n >= 3 {
+			malicious = 5
+		}
+		if n == 2 {
+			resetConnection(t, w)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if n == 4 {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": {"code": "NotFoundError", "message": "not found"}}`))
+			return
+		}
+		fmt.Fprintf(w, `{"data": {"type": "file", "id": "1234", "attributes": {
+			"size": 10, "last_analysis_stats": {"malicious": %d}}}}`, malicious)
+	}))
+	ts.Config.SetKeepAlivesEnabled(false)
+	defer ts.Close()
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	_, err := c.Watch(ctx, URL("files/1234"), time.Millisecond, "foo..bar")
+	assert.Error(t, err)
+	_, err = c.Watch(ctx, URL("files/1234"), 0, "size")
+	assert.Error(t, err)
+
+	events, err := c.Watch(ctx, URL("files/1234"), time.Millisecond,
+		"size", "last_analysis_stats.malicious")
+	assert.NoError(t, err)
+
+	event := <-events
+	assert.NoError(t, event.Err)
+	assert.Equal(t, "last_analysis_stats.malicious", event.Path)
+	assert.Equal(t, "1", fmt.Sprint(event.OldValue))
+	assert.Equal(t, "5", fmt.Sprint(event.NewValue))
+	assert.Equal(t, "1234", event.Object.ID())
+
+	event = <-events
+	assert.Error(t, event.Err)
+
+	_, ok := <-events
+	assert.False(t, ok)
+}
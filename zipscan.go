@@ -0,0 +1,171 @@
+// Copyright © 2017 The vt-go authors. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vt
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"crypto/rand"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// ScanZipWithPassword sends a password-protected ZIP file to VirusTotal for
+// scanning, VirusTotal uses the password for extracting the files in the ZIP
+// and analyses them. This is the usual way of submitting malware samples
+// without exposing them to antivirus software along the way. The arguments
+// and returned object are like in Scan.
+func (s *FileScanner) ScanZipWithPassword(r io.Reader, filename, password string, progress chan<- float32) (*Object, error) {
+	return s.scanWithParameters(r, filename, progress, map[string]string{"password": password})
+}
+
+// ScanFilesWithPassword builds a ZIP file containing the files in paths,
+// encrypted with the given password, and sends it to VirusTotal for scanning
+// with ScanZipWithPassword. The ZIP file is built in memory and submitted
+// with the given file name, see ZipFilesWithPassword.
+func (s *FileScanner) ScanFilesWithPassword(filename, password string, paths []string, progress chan<- float32) (*Object, error) {
+	b := bytes.Buffer{}
+	if err := ZipFilesWithPassword(&b, password, paths...); err != nil {
+		return nil, err
+	}
+	return s.ScanZipWithPassword(&b, filename, password, progress)
+}
+
+// ZipFilesWithPassword writes to w a ZIP file containing the files in paths,
+// encrypted with the given password. Files are stored by their base name, and
+// encrypted with the traditional PKWARE encryption (a.k.a ZipCrypto), which is
+// the one supported by VirusTotal and by most ZIP tools. This encryption is
+// weak, it's meant for preventing samples from being detected and deleted
+// in transit, not for protecting them from being read.
+func ZipFilesWithPassword(w io.Writer, password string, paths ...string) error {
+	zw := zip.NewWriter(w)
+	// The header of the file being written, the compressor needs it for
+	// computing the encryption header.
+	var fh *zip.FileHeader
+	zw.RegisterCompressor(zip.Deflate, func(out io.Writer) (io.WriteCloser, error) {
+		return flate.NewWriter(newZipCryptoWriter(out, password, fh), flate.DefaultCompression)
+	})
+	for _, path := range paths {
+		if err := zipFile(zw, path, func(h *zip.FileHeader) { fh = h }); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// zipFile adds a file to a ZIP file, encrypted. The setHeader function is
+// called with the file's header before adding it.
+func zipFile(zw *zip.Writer, path string, setHeader func(*zip.FileHeader)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	h := &zip.FileHeader{
+		Name:     filepath.Base(path),
+		Method:   zip.Deflate,
+		Modified: info.ModTime(),
+		// Bit 0 indicates that the file is encrypted.
+		Flags: 0x1,
+	}
+	setHeader(h)
+	fw, err := zw.CreateHeader(h)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(fw, f)
+	return err
+}
+
+// zipCryptoWriter encrypts the data written to it with the traditional PKWARE
+// encryption, as described in section 6.1 of the ZIP file format
+// specification (APPNOTE.TXT).
+type zipCryptoWriter struct {
+	w    io.Writer
+	fh   *zip.FileHeader
+	keys [3]uint32
+	buf  []byte
+	// True after writing the encryption header.
+	started bool
+}
+
+// newZipCryptoWriter returns a zipCryptoWriter that writes to w the data
+// for the file with the given header.
+func newZipCryptoWriter(w io.Writer, password string, fh *zip.FileHeader) *zipCryptoWriter {
+	zw := &zipCryptoWriter{
+		w:    w,
+		fh:   fh,
+		keys: [3]uint32{0x12345678, 0x23456789, 0x34567890},
+	}
+	for i := 0; i < len(password); i++ {
+		zw.update(password[i])
+	}
+	return zw
+}
+
+// start writes the encryption header that precedes the file data. This is
+// done on the first write, as the compressor is created before the file's
+// local header is written.
+func (zw *zipCryptoWriter) start() error {
+	zw.started = true
+	header := make([]byte, 12)
+	if _, err := rand.Read(header[:11]); err != nil {
+		return err
+	}
+	// As the CRC is written in a data descriptor after the file data, the
+	// last byte of the header is checked against the high order byte of the
+	// modification time, instead of the CRC.
+	header[11] = byte(zw.fh.ModifiedTime >> 8)
+	_, err := zw.encrypt(header)
+	return err
+}
+
+func (zw *zipCryptoWriter) update(b byte) {
+	zw.keys[0] = crc32Update(zw.keys[0], b)
+	zw.keys[1] = (zw.keys[1]+zw.keys[0]&0xff)*134775813 + 1
+	zw.keys[2] = crc32Update(zw.keys[2], byte(zw.keys[1]>>24))
+}
+
+func (zw *zipCryptoWriter) Write(p []byte) (int, error) {
+	if !zw.started {
+		if err := zw.start(); err != nil {
+			return 0, err
+		}
+	}
+	return zw.encrypt(p)
+}
+
+func (zw *zipCryptoWriter) encrypt(p []byte) (int, error) {
+	if cap(zw.buf) < len(p) {
+		zw.buf = make([]byte, len(p))
+	}
+	buf := zw.buf[:len(p)]
+	for i, b := range p {
+		t := uint16(zw.keys[2] | 2)
+		buf[i] = b ^ byte((t*(t^1))>>8)
+		zw.update(b)
+	}
+	return zw.w.Write(buf)
+}
+
+func crc32Update(crc uint32, b byte) uint32 {
+	return crc32.IEEETable[byte(crc)^b] ^ crc>>8
+}
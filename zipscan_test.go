@@ -0,0 +1,94 @@
+package vt
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// zipCryptoReader decrypts data encrypted by zipCryptoWriter.
+type zipCryptoReader struct {
+	r  io.Reader
+	zw *zipCryptoWriter
+}
+
+func (zr *zipCryptoReader) Read(p []byte) (int, error) {
+	n, err := zr.r.Read(p)
+	for i := 0; i < n; i++ {
+		t := uint16(zr.zw.keys[2] | 2)
+		p[i] ^= byte((t * (t ^ 1)) >> 8)
+		zr.zw.update(p[i])
+	}
+	return n, err
+}
+
+// unzipWithPassword returns the content of the files in an encrypted ZIP.
+func unzipWithPassword(t *testing.T, data []byte, password string) map[string]string {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	assert.NoError(t, err)
+	var modTime uint16
+	zr.RegisterDecompressor(zip.Deflate, func(r io.Reader) io.ReadCloser {
+		cr := &zipCryptoReader{r: r, zw: newZipCryptoWriter(nil, password, nil)}
+		header := make([]byte, 12)
+		_, err := io.ReadFull(cr, header)
+		assert.NoError(t, err)
+		assert.Equal(t, byte(modTime>>8), header[11])
+		return flate.NewReader(cr)
+	})
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		assert.Equal(t, uint16(1), f.Flags&1)
+		modTime = f.ModifiedTime
+		rc, err := f.Open()
+		assert.NoError(t, err)
+		content, err := ioutil.ReadAll(rc)
+		assert.NoError(t, err)
+		rc.Close()
+		files[f.Name] = string(content)
+	}
+	return files
+}
+
+func TestFileScannerScanFilesWithPassword(t *testing.T) {
+	var files map[string]string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f, header, err := r.FormFile("file")
+		assert.NoError(t, err)
+		assert.Equal(t, "samples.zip", header.Filename)
+		assert.Equal(t, "infected", r.FormValue("password"))
+		data, err := ioutil.ReadAll(f)
+		assert.NoError(t, err)
+		files = unzipWithPassword(t, data, "infected")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": {"type": "analysis", "id": "f-1234"}}`))
+	}))
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "vt")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	paths := []string{filepath.Join(dir, "a.exe"), filepath.Join(dir, "b.dll")}
+	assert.NoError(t, ioutil.WriteFile(paths[0], []byte("foo"), 0644))
+	assert.NoError(t, ioutil.WriteFile(paths[1], bytes.Repeat([]byte("bar"), 1000), 0644))
+
+	SetHost(ts.URL)
+	c := NewClient("api_key")
+
+	a, err := c.NewFileScanner().ScanFilesWithPassword("samples.zip", "infected", paths, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "f-1234", a.ID())
+	assert.Equal(t, map[string]string{
+		"a.exe": "foo",
+		"b.dll": string(bytes.Repeat([]byte("bar"), 1000)),
+	}, files)
+}